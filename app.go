@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"sakpilot/internal/apigw"
 	"sakpilot/internal/apprun"
 	"sakpilot/internal/apprunshared"
 	"sakpilot/internal/cloudhsm"
 	"sakpilot/internal/eventbus"
+	"sakpilot/internal/ftps"
 	"sakpilot/internal/iam"
 	"sakpilot/internal/kms"
 	"sakpilot/internal/sakura"
@@ -17,6 +23,7 @@ import (
 	"sakpilot/internal/serviceendpointgateway"
 	"sakpilot/internal/simplemq"
 	"sakpilot/internal/simplenotification"
+	"sakpilot/internal/vncbridge"
 	"sakpilot/internal/workflows"
 
 	"github.com/sacloud/sacloud-sdk-go/api/iaas"
@@ -25,16 +32,42 @@ import (
 
 type App struct {
 	ctx context.Context
+	vnc *vncbridge.Bridge
+
+	waitsMu sync.Mutex
+	waits   map[string]context.CancelFunc // 実行中の状態待ち(waitID -> 中断関数)
 }
 
 func NewApp() *App {
-	return &App{}
+	return &App{vnc: vncbridge.New(vncSessionRecordPath())}
 }
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
+func (a *App) shutdown(ctx context.Context) {
+	_ = a.vnc.Close()
+}
+
+// vncSessionRecordPath はVNCコンソールの接続記録の保存先。設定ディレクトリが取得できない場合は記録しない。
+func vncSessionRecordPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sakpilot", "vnc-sessions.jsonl")
+}
+
+// emitEvent はフロントエンドへWailsイベントを送る。
+// e2eサーバーのようにWailsランタイムを持たないコンテキストで呼ばれた場合は何もしない。
+func (a *App) emitEvent(name string, data ...interface{}) {
+	if a.ctx == nil || a.ctx.Value("events") == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
+}
+
 func (a *App) GetZones() []sakura.ZoneInfo {
 	return sakura.GetZones()
 }
@@ -199,13 +232,46 @@ func (a *App) GetServerDetail(profileName, zone, serverID string) (*sakura.Serve
 	return service.Get(a.ctx, zone, serverID)
 }
 
+// ChangeServerPlan はプランを変更する。存在しない・利用できないプランの場合はAPIを呼ばずにエラーを返し、
+// 利用できる場合は確認したプランの世代・専有CPUかどうかを指定して変更する。
+// カタログ自体を取得できなかった場合のみ、確認せずにAPIの判定に任せる。
 func (a *App) ChangeServerPlan(profileName, zone, serverID string, cpu, memoryGB int) (*sakura.ServerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
 	service := sakura.NewServerService(client)
-	return service.ChangePlan(a.ctx, zone, serverID, cpu, memoryGB)
+	check, err := sakura.NewServerPlanService(client).CheckPlanChange(a.ctx, zone, serverID, cpu, memoryGB)
+	if errors.Is(err, sakura.ErrServerPlanCatalogUnavailable) {
+		return service.ChangePlan(a.ctx, zone, serverID, cpu, memoryGB)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(check.Errors) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(check.Errors, "\n"))
+	}
+	return service.ChangeToPlan(a.ctx, zone, serverID, check.Plan)
+}
+
+// GetServerPlans はゾーンのサーバープランのカタログを返す。
+func (a *App) GetServerPlans(profileName, zone string, filter sakura.ServerPlanFilter) ([]sakura.ServerPlanInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerPlanService(client)
+	return service.List(a.ctx, zone, filter)
+}
+
+// CheckServerPlanChange はプラン変更前の確認(プランの有無・停止の要否・料金の変化)を行う。
+func (a *App) CheckServerPlanChange(profileName, zone, serverID string, cpu, memoryGB int) (*sakura.ServerPlanChangeCheck, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerPlanService(client)
+	return service.CheckPlanChange(a.ctx, zone, serverID, cpu, memoryGB)
 }
 
 func (a *App) GetCDROMs(profileName, zone string) ([]sakura.CDROMInfo, error) {
@@ -217,6 +283,56 @@ func (a *App) GetCDROMs(profileName, zone string) ([]sakura.CDROMInfo, error) {
 	return service.List(a.ctx, zone)
 }
 
+// CreateCDROMFromISO はファイルダイアログで選んだISOファイルからISOイメージを作成する。
+// アップロードの進捗は "cdrom:upload:progress" イベントで (ISOイメージ名, ftps.Progress) として通知する。
+func (a *App) CreateCDROMFromISO(profileName, zone string, input sakura.CDROMCreateInput) (*sakura.CDROMInfo, error) {
+	localPath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "アップロードするISOファイルを選択",
+		Filters: []runtime.FileFilter{{DisplayName: "ISOイメージ (*.iso)", Pattern: "*.iso"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if localPath == "" {
+		return nil, fmt.Errorf("cancelled")
+	}
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewCDROMService(client)
+	return service.CreateFromISO(a.ctx, zone, input, localPath, func(p ftps.Progress) {
+		a.emitEvent("cdrom:upload:progress", input.Name, p)
+	})
+}
+
+func (a *App) UpdateCDROM(profileName, zone, cdromID, name, description string, tags []string) (*sakura.CDROMInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewCDROMService(client)
+	return service.Update(a.ctx, zone, cdromID, name, description, tags)
+}
+
+func (a *App) CloseCDROMFTP(profileName, zone, cdromID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewCDROMService(client)
+	return service.CloseFTP(a.ctx, zone, cdromID)
+}
+
+func (a *App) DeleteCDROM(profileName, zone, cdromID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewCDROMService(client)
+	return service.Delete(a.ctx, zone, cdromID)
+}
+
 func (a *App) InsertServerCDROM(profileName, zone, serverID, cdromID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
@@ -262,6 +378,224 @@ func (a *App) GetServerVNCProxy(profileName, zone, serverID string) (*sakura.VNC
 	return service.GetVNCProxy(a.ctx, zone, serverID)
 }
 
+// VNCConsoleInfo はアプリ内のVNCコンソールの接続情報。URLはnoVNC等のWebSocketクライアントで一度だけ使用できる。
+type VNCConsoleInfo struct {
+	SessionID string `json:"sessionId"`
+	URL       string `json:"url"`
+	Password  string `json:"password"`
+}
+
+// OpenServerConsole はサーバーのVNCサーバーに中継するローカルWebSocketを用意する。
+func (a *App) OpenServerConsole(profileName, zone, serverID string) (*VNCConsoleInfo, error) {
+	proxy, err := a.GetServerVNCProxy(profileName, zone, serverID)
+	if err != nil {
+		return nil, err
+	}
+	host := proxy.IOServerHost
+	if host == "" {
+		host = proxy.Host
+	}
+	url, sessionID, err := a.vnc.Open(vncbridge.Target{Host: host, Port: proxy.Port, Zone: zone, ServerID: serverID})
+	if err != nil {
+		return nil, err
+	}
+	return &VNCConsoleInfo{SessionID: sessionID, URL: url, Password: proxy.Password}, nil
+}
+
+func (a *App) CloseServerConsole(sessionID string) {
+	a.vnc.Disconnect(sessionID)
+}
+
+// GetServerConsoleSessions はこのアプリの起動以降に終了したVNCコンソールの接続記録を返す。
+func (a *App) GetServerConsoleSessions() []vncbridge.SessionRecord {
+	return a.vnc.Sessions()
+}
+
+// PasteToServerConsole はクリップボードのテキストをキー入力としてサーバーのコンソールに送信する。
+func (a *App) PasteToServerConsole(profileName, zone, serverID, text string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewServerService(client)
+	return service.PasteText(a.ctx, zone, serverID, text)
+}
+
+// CreateServer はサーバー・ブートディスク・NICをまとめて作成する。途中で失敗した場合は作成済みのリソースを削除する。
+func (a *App) CreateServer(profileName, zone string, input sakura.ServerCreateInput) (*sakura.ServerInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerService(client)
+	return service.Create(a.ctx, zone, input)
+}
+
+// CloneServer は既存サーバーと同じ構成のサーバーを作成する。
+// 進捗は "server:clone:progress" イベントで (複製元サーバーID, ServerCloneProgress) として通知する。
+func (a *App) CloneServer(profileName, zone, serverID, newName string, options sakura.ServerCloneOptions) (*sakura.ServerCloneResult, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerService(client)
+	return service.Clone(a.ctx, zone, serverID, newName, options, func(p sakura.ServerCloneProgress) {
+		a.emitEvent("server:clone:progress", serverID, p)
+	})
+}
+
+func serverMigrationStore() (*sakura.ServerMigrationStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return sakura.NewServerMigrationStore(filepath.Join(dir, "sakpilot", "migrations")), nil
+}
+
+// MigrateServer はサーバーを別ゾーンへ移行する。進捗は "server:migration:progress" イベントで通知する。
+// 移行の途中で失敗した場合はエラーメッセージに移行IDを含めるため、その移行IDでResumeServerMigrationを呼び出せる。
+// 移行IDはGetServerMigrationsの一覧からも確認できる。
+func (a *App) MigrateServer(profileName, zone, serverID string, options sakura.ServerMigrationOptions) (*sakura.ServerMigrationJournal, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := serverMigrationStore()
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerService(client)
+	return service.Migrate(a.ctx, zone, serverID, options, store, func(p sakura.ServerMigrationProgress) {
+		a.emitEvent("server:migration:progress", p)
+	})
+}
+
+// ResumeServerMigration は失敗・中断したサーバー移行を続きから再開する。
+func (a *App) ResumeServerMigration(profileName, migrationID string) (*sakura.ServerMigrationJournal, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := serverMigrationStore()
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerService(client)
+	return service.ResumeMigration(a.ctx, migrationID, store, func(p sakura.ServerMigrationProgress) {
+		a.emitEvent("server:migration:progress", p)
+	})
+}
+
+// GetServerMigrations は保存されているサーバー移行のジャーナルを新しい順に返す。
+func (a *App) GetServerMigrations() ([]sakura.ServerMigrationJournal, error) {
+	store, err := serverMigrationStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+func (a *App) DeleteServerMigration(migrationID string) error {
+	store, err := serverMigrationStore()
+	if err != nil {
+		return err
+	}
+	return store.Delete(migrationID)
+}
+
+// GetServerMonitorCPU はサーバーのCPU時間のグラフ用データを返す。intervalSecが0より大きい場合はaggregation("avg"/"max")で集約する。
+func (a *App) GetServerMonitorCPU(profileName, zone, serverID string, start, end, intervalSec int64, aggregation string) ([]sakura.ServerCPUTimeValueInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerService(client)
+	return service.MonitorCPU(a.ctx, zone, serverID, start, end, intervalSec, aggregation)
+}
+
+// Server NIC (Interface)
+func (a *App) GetServerInterfaces(profileName, zone string, serverID string) ([]sakura.InterfaceInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.List(a.ctx, zone, serverID)
+}
+
+func (a *App) AddServerInterface(profileName, zone string, serverID string) (*sakura.InterfaceInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.Add(a.ctx, zone, serverID)
+}
+
+func (a *App) DeleteServerInterface(profileName, zone string, interfaceID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.Delete(a.ctx, zone, interfaceID)
+}
+
+// ConnectInterfaceToSwitch はNICをスイッチに接続する。switchIDに "shared" を指定すると共有セグメントに接続する。
+func (a *App) ConnectInterfaceToSwitch(profileName, zone string, interfaceID, switchID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.ConnectToSwitch(a.ctx, zone, interfaceID, switchID)
+}
+
+func (a *App) DisconnectInterfaceFromSwitch(profileName, zone string, interfaceID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.DisconnectFromSwitch(a.ctx, zone, interfaceID)
+}
+
+func (a *App) ApplyInterfacePacketFilter(profileName, zone string, interfaceID, packetFilterID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.ApplyPacketFilter(a.ctx, zone, interfaceID, packetFilterID)
+}
+
+func (a *App) RemoveInterfacePacketFilter(profileName, zone string, interfaceID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.RemovePacketFilter(a.ctx, zone, interfaceID)
+}
+
+func (a *App) SetInterfaceUserIPAddress(profileName, zone string, interfaceID, ipAddress string) (*sakura.InterfaceInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.SetUserIPAddress(a.ctx, zone, interfaceID, ipAddress)
+}
+
+func (a *App) GetInterfaceMonitor(profileName, zone, interfaceID string, start, end, intervalSec int64, aggregation string) ([]sakura.InterfaceActivityValueInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInterfaceService(client)
+	return service.Monitor(a.ctx, zone, interfaceID, start, end, intervalSec, aggregation)
+}
+
 // Global resources (zone-independent)
 func (a *App) GetDNSList(profileName string) ([]sakura.DNSInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
@@ -416,87 +750,449 @@ func (a *App) DeleteContainerRegistryUser(profileName, registryId, userName stri
 	return service.DeleteContainerRegistryUser(a.ctx, registryId, userName)
 }
 
-// GSLB Detail
-func (a *App) GetGSLBDetail(profileName, gslbId string) (*sakura.GSLBInfo, error) {
+// SSH Keys
+func (a *App) GetSSHKeys(profileName string) ([]sakura.SSHKeyInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewGlobalService(client)
-	return service.GetGSLB(a.ctx, gslbId)
+	service := sakura.NewSSHKeyService(client)
+	return service.List(a.ctx)
 }
 
-func (a *App) DeleteGSLB(profileName, gslbId string) error {
+// CreateSSHKey はSSH公開鍵を登録する。鍵ペアを生成した場合、input.SaveToKeyringが指定されていれば秘密鍵をキーリングに保存する。
+// エラーを返すとWails側で結果が捨てられ秘密鍵を失うため、登録後のキーリング保存の失敗はresult.KeyringErrorで返す。
+func (a *App) CreateSSHKey(profileName string, input sakura.SSHKeyCreateInput) (*sakura.SSHKeyCreateResult, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	service := sakura.NewGlobalService(client)
-	return service.DeleteGSLB(a.ctx, gslbId)
+	service := sakura.NewSSHKeyService(client)
+	result, err := service.Create(a.ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if result.PrivateKey != "" && input.SaveToKeyring {
+		if err := sakura.SaveSSHPrivateKey(result.Key.ID, result.PrivateKey); errors.Is(err, sakura.ErrKeyringDataTooBig) {
+			result.KeyringError = "秘密鍵がキーリングに保存できる大きさを超えています。表示された秘密鍵をファイルに保存してください"
+		} else if err != nil {
+			result.KeyringError = fmt.Sprintf("秘密鍵のキーリングへの保存に失敗しました。表示された秘密鍵をファイルに保存してください: %v", err)
+		}
+	}
+	return result, nil
 }
 
-func (a *App) CreateGSLB(profileName, name, description string, settings sakura.GSLBSettingsInput) (*sakura.GSLBInfo, error) {
+func (a *App) UpdateSSHKey(profileName, sshKeyID, name, description string) (*sakura.SSHKeyInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewGlobalService(client)
-	return service.CreateGSLB(a.ctx, name, description, settings)
+	service := sakura.NewSSHKeyService(client)
+	return service.Update(a.ctx, sshKeyID, name, description)
 }
 
-func (a *App) UpdateGSLB(profileName, gslbId, name, description string) (*sakura.GSLBInfo, error) {
+// DeleteSSHKey はSSH公開鍵を削除し、キーリングに秘密鍵が保存されていれば併せて削除する。
+func (a *App) DeleteSSHKey(profileName, sshKeyID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	service := sakura.NewGlobalService(client)
-	return service.UpdateGSLB(a.ctx, gslbId, name, description)
+	service := sakura.NewSSHKeyService(client)
+	if err := service.Delete(a.ctx, sshKeyID); err != nil {
+		return err
+	}
+	if sakura.HasSSHPrivateKey(sshKeyID) {
+		return sakura.DeleteSSHPrivateKey(sshKeyID)
+	}
+	return nil
 }
 
-func (a *App) UpdateGSLBSettings(profileName, gslbId string, settings sakura.GSLBSettingsInput) (*sakura.GSLBInfo, error) {
-	client, err := sakura.NewClientFromProfile(profileName)
+func (a *App) GetSSHPrivateKey(sshKeyID string) (string, error) {
+	return sakura.GetSSHPrivateKey(sshKeyID)
+}
+
+func (a *App) HasSSHPrivateKey(sshKeyID string) bool {
+	return sakura.HasSSHPrivateKey(sshKeyID)
+}
+
+// SaveSSHPrivateKeyToFile は秘密鍵をファイルに保存する。保存先はダイアログで選択する。
+func (a *App) SaveSSHPrivateKeyToFile(privateKey, defaultFileName string) error {
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: defaultFileName,
+		Title:           "秘密鍵を保存",
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
-	service := sakura.NewGlobalService(client)
-	return service.UpdateGSLBSettings(a.ctx, gslbId, settings)
+	if savePath == "" {
+		return fmt.Errorf("cancelled")
+	}
+	return os.WriteFile(savePath, []byte(privateKey), 0o600)
 }
 
-// Switches
-func (a *App) GetSwitches(profileName, zone string) ([]sakura.SwitchInfo, error) {
+// Startup scripts (Note)
+// GetNotes はスタートアップスクリプトの一覧を返す。classに "shell"/"yaml_cloud_config" を指定すると絞り込む。
+func (a *App) GetNotes(profileName, class string) ([]sakura.NoteInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewSwitchService(client)
-	return service.List(a.ctx, zone)
+	service := sakura.NewNoteService(client)
+	return service.List(a.ctx, class)
 }
 
-func (a *App) GetSwitchDetail(profileName, zone, switchId string) (*sakura.SwitchInfo, error) {
+func (a *App) GetNote(profileName, noteID string) (*sakura.NoteInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewSwitchService(client)
-	return service.Get(a.ctx, zone, switchId)
+	service := sakura.NewNoteService(client)
+	return service.Get(a.ctx, noteID)
 }
 
-func (a *App) DeleteSwitch(profileName, zone, switchId string) error {
+func (a *App) CreateNote(profileName string, input sakura.NoteInput) (*sakura.NoteInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	service := sakura.NewSwitchService(client)
-	return service.Delete(a.ctx, zone, switchId)
+	service := sakura.NewNoteService(client)
+	return service.Create(a.ctx, input)
 }
 
-func (a *App) CreateSwitch(profileName, zone, name, description string, networkMaskLen int, defaultRoute string) (*sakura.SwitchInfo, error) {
+func (a *App) UpdateNote(profileName, noteID string, input sakura.NoteInput) (*sakura.NoteInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewSwitchService(client)
-	return service.Create(a.ctx, zone, name, description, networkMaskLen, defaultRoute)
+	service := sakura.NewNoteService(client)
+	return service.Update(a.ctx, noteID, input)
+}
+
+func (a *App) DeleteNote(profileName, noteID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewNoteService(client)
+	return service.Delete(a.ctx, noteID)
+}
+
+// ExportNotes は選択したスタートアップスクリプトをJSONファイルに保存する。保存先はダイアログで選択する。
+func (a *App) ExportNotes(profileName string, noteIDs []string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewNoteService(client)
+	data, err := service.Export(a.ctx, noteIDs)
+	if err != nil {
+		return err
+	}
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: "startup-scripts.json",
+		Title:           "スタートアップスクリプトをエクスポート",
+	})
+	if err != nil {
+		return err
+	}
+	if savePath == "" {
+		return fmt.Errorf("cancelled")
+	}
+	return os.WriteFile(savePath, data, 0o644)
+}
+
+// ImportNotes はExportNotesで保存したファイルを選択し、含まれるスタートアップスクリプトを新規に登録する。
+// 途中で失敗した場合、それまでに登録したスクリプト名はエラーメッセージに含まれる。
+func (a *App) ImportNotes(profileName string) ([]sakura.NoteInfo, error) {
+	localPath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "インポートするファイルを選択",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if localPath == "" {
+		return nil, fmt.Errorf("cancelled")
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewNoteService(client)
+	return service.Import(a.ctx, data)
+}
+
+// GSLB Detail
+func (a *App) GetGSLBDetail(profileName, gslbId string) (*sakura.GSLBInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewGlobalService(client)
+	return service.GetGSLB(a.ctx, gslbId)
+}
+
+func (a *App) DeleteGSLB(profileName, gslbId string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewGlobalService(client)
+	return service.DeleteGSLB(a.ctx, gslbId)
+}
+
+func (a *App) CreateGSLB(profileName, name, description string, settings sakura.GSLBSettingsInput) (*sakura.GSLBInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewGlobalService(client)
+	return service.CreateGSLB(a.ctx, name, description, settings)
+}
+
+func (a *App) UpdateGSLB(profileName, gslbId, name, description string) (*sakura.GSLBInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewGlobalService(client)
+	return service.UpdateGSLB(a.ctx, gslbId, name, description)
+}
+
+func (a *App) UpdateGSLBSettings(profileName, gslbId string, settings sakura.GSLBSettingsInput) (*sakura.GSLBInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewGlobalService(client)
+	return service.UpdateGSLBSettings(a.ctx, gslbId, settings)
+}
+
+// Private hosts
+func (a *App) GetPrivateHosts(profileName, zone string) ([]sakura.PrivateHostInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPrivateHostService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) GetPrivateHostPlans(profileName, zone string) ([]sakura.PrivateHostPlanInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPrivateHostService(client)
+	return service.ListPlans(a.ctx, zone)
+}
+
+func (a *App) CreatePrivateHost(profileName, zone string, input sakura.PrivateHostCreateInput) (*sakura.PrivateHostInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPrivateHostService(client)
+	return service.Create(a.ctx, zone, input)
+}
+
+func (a *App) DeletePrivateHost(profileName, zone, privateHostID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewPrivateHostService(client)
+	return service.Delete(a.ctx, zone, privateHostID)
+}
+
+// ChangeServerPrivateHost は停止中のサーバーを専有ホストへ移動する。privateHostIDが空文字の場合は共有ホストへ戻す。
+func (a *App) ChangeServerPrivateHost(profileName, zone, serverID, privateHostID string) (*sakura.ServerInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewServerService(client)
+	return service.ChangePrivateHost(a.ctx, zone, serverID, privateHostID)
+}
+
+// Routers (Internet)
+func (a *App) GetInternets(profileName, zone string) ([]sakura.InternetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) GetInternetDetail(profileName, zone, internetID string) (*sakura.InternetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.Get(a.ctx, zone, internetID)
+}
+
+func (a *App) CreateInternet(profileName, zone string, input sakura.InternetCreateInput) (*sakura.InternetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.Create(a.ctx, zone, input)
+}
+
+func (a *App) UpdateInternet(profileName, zone, internetID, name, description string, tags []string) (*sakura.InternetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.Update(a.ctx, zone, internetID, name, description, tags)
+}
+
+func (a *App) DeleteInternet(profileName, zone, internetID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInternetService(client)
+	return service.Delete(a.ctx, zone, internetID)
+}
+
+func (a *App) UpdateInternetBandWidth(profileName, zone, internetID string, bandWidthMbps int) (*sakura.InternetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.UpdateBandWidth(a.ctx, zone, internetID, bandWidthMbps)
+}
+
+func (a *App) AddInternetSubnet(profileName, zone, internetID string, networkMaskLen int, nextHop string) (*sakura.InternetSubnetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.AddSubnet(a.ctx, zone, internetID, networkMaskLen, nextHop)
+}
+
+func (a *App) DeleteInternetSubnet(profileName, zone, internetID, subnetID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInternetService(client)
+	return service.DeleteSubnet(a.ctx, zone, internetID, subnetID)
+}
+
+func (a *App) EnableInternetIPv6(profileName, zone, internetID string) (*sakura.IPv6NetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.EnableIPv6(a.ctx, zone, internetID)
+}
+
+func (a *App) DisableInternetIPv6(profileName, zone, internetID, ipv6NetID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewInternetService(client)
+	return service.DisableIPv6(a.ctx, zone, internetID, ipv6NetID)
+}
+
+// GetGlobalSubnets はゾーン内のグローバルIPアドレスのブロックを、割り当て先のルーター・スイッチと合わせて返す。
+func (a *App) GetGlobalSubnets(profileName, zone string) ([]sakura.GlobalSubnetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.ListSubnets(a.ctx, zone)
+}
+
+func (a *App) GetIPAddresses(profileName, zone string) ([]sakura.IPAddressInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.ListIPAddresses(a.ctx, zone)
+}
+
+func (a *App) GetIPv6Nets(profileName, zone string) ([]sakura.IPv6NetInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewInternetService(client)
+	return service.ListIPv6Nets(a.ctx, zone)
+}
+
+// PTR records
+// GetPTRRecords はゾーン内のグローバルIPアドレスと逆引きホスト名の一覧を返す。
+func (a *App) GetPTRRecords(profileName, zone string) ([]sakura.PTRRecordInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPTRService(client)
+	return service.List(a.ctx, zone)
+}
+
+// SetPTRHostName はIPアドレスの逆引きホスト名を設定する。正引きが一致しない場合はエラーを返す。hostNameが空文字の場合は削除する。
+func (a *App) SetPTRHostName(profileName, zone, ipAddress, hostName string) (*sakura.PTRRecordInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPTRService(client)
+	return service.SetHostName(a.ctx, zone, ipAddress, hostName)
+}
+
+// Switches
+func (a *App) GetSwitches(profileName, zone string) ([]sakura.SwitchInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewSwitchService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) GetSwitchDetail(profileName, zone, switchId string) (*sakura.SwitchInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewSwitchService(client)
+	return service.Get(a.ctx, zone, switchId)
+}
+
+func (a *App) DeleteSwitch(profileName, zone, switchId string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewSwitchService(client)
+	return service.Delete(a.ctx, zone, switchId)
+}
+
+func (a *App) CreateSwitch(profileName, zone, name, description string, networkMaskLen int, defaultRoute string) (*sakura.SwitchInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewSwitchService(client)
+	return service.Create(a.ctx, zone, name, description, networkMaskLen, defaultRoute)
 }
 
 func (a *App) UpdateSwitch(profileName, zone, switchId, name, description string, networkMaskLen int, defaultRoute string) (*sakura.SwitchInfo, error) {
@@ -504,400 +1200,1055 @@ func (a *App) UpdateSwitch(profileName, zone, switchId, name, description string
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewSwitchService(client)
-	return service.Update(a.ctx, zone, switchId, name, description, networkMaskLen, defaultRoute)
+	service := sakura.NewSwitchService(client)
+	return service.Update(a.ctx, zone, switchId, name, description, networkMaskLen, defaultRoute)
+}
+
+// Bridges
+func (a *App) GetBridges(profileName, zone string) ([]sakura.BridgeInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewBridgeService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) GetBridgeDetail(profileName, zone, bridgeId string) (*sakura.BridgeInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewBridgeService(client)
+	return service.Get(a.ctx, zone, bridgeId)
+}
+
+func (a *App) CreateBridge(profileName, zone, name, description string) (*sakura.BridgeInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewBridgeService(client)
+	return service.Create(a.ctx, zone, name, description)
+}
+
+func (a *App) DeleteBridge(profileName, zone, bridgeId string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewBridgeService(client)
+	return service.Delete(a.ctx, zone, bridgeId)
+}
+
+// ConnectSwitchToBridge はzoneのスイッチをブリッジに接続する。
+func (a *App) ConnectSwitchToBridge(profileName, zone, switchId, bridgeId string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewBridgeService(client)
+	return service.ConnectSwitch(a.ctx, zone, switchId, bridgeId)
+}
+
+func (a *App) DisconnectSwitchFromBridge(profileName, zone, switchId string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewBridgeService(client)
+	return service.DisconnectSwitch(a.ctx, zone, switchId)
+}
+
+// PacketFilters
+func (a *App) GetPacketFilters(profileName, zone string) ([]sakura.PacketFilterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPacketFilterService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) GetPacketFilterDetail(profileName, zone, pfId string) (*sakura.PacketFilterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPacketFilterService(client)
+	return service.Get(a.ctx, zone, pfId)
+}
+
+func (a *App) DeletePacketFilter(profileName, zone, pfId string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewPacketFilterService(client)
+	return service.Delete(a.ctx, zone, pfId)
+}
+
+func (a *App) CreatePacketFilter(profileName, zone, name, description string, rules []sakura.PacketFilterRuleInfo) (*sakura.PacketFilterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPacketFilterService(client)
+	return service.Create(a.ctx, zone, name, description, rules)
+}
+
+func (a *App) UpdatePacketFilter(profileName, zone, pfId, name, description string, rules []sakura.PacketFilterRuleInfo) (*sakura.PacketFilterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewPacketFilterService(client)
+	return service.Update(a.ctx, zone, pfId, name, description, rules)
+}
+
+// EvaluatePacketFilter はルールを上から順に評価し、テストパケットに一致するルールと許可・拒否の結果を返す。APIは呼び出さない。
+func (a *App) EvaluatePacketFilter(rules []sakura.PacketFilterRuleInfo, packet sakura.PacketFilterTestPacket) (*sakura.PacketFilterEvaluation, error) {
+	return sakura.EvaluatePacketFilter(rules, packet)
+}
+
+// LintPacketFilter は評価されないルールや末尾の全拒否の欠落など、ルールの並びの問題点を返す。APIは呼び出さない。
+func (a *App) LintPacketFilter(rules []sakura.PacketFilterRuleInfo) []sakura.PacketFilterLintIssue {
+	return sakura.LintPacketFilter(rules)
+}
+
+// Disks
+func (a *App) GetDisks(profileName, zone string) ([]sakura.DiskInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDiskService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) DeleteDisk(profileName, zone, diskID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewDiskService(client)
+	return service.Delete(a.ctx, zone, diskID)
+}
+
+func (a *App) GetDiskDetail(profileName, zone, diskID string) (*sakura.DiskInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDiskService(client)
+	return service.Get(a.ctx, zone, diskID)
+}
+
+func (a *App) CreateDisk(profileName, zone, name, description string, tags []string, sizeGB int, diskPlan, connection, sourceArchiveID, serverID string) (*sakura.DiskInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDiskService(client)
+	return service.Create(a.ctx, zone, name, description, tags, sizeGB, diskPlan, connection, sourceArchiveID, serverID)
+}
+
+func (a *App) UpdateDisk(profileName, zone, diskID, name, description string, tags []string) (*sakura.DiskInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDiskService(client)
+	return service.Update(a.ctx, zone, diskID, name, description, tags)
+}
+
+// CheckDiskResize はディスクを指定したサイズへ拡張できるかと、拡張後の月額料金を返す。
+func (a *App) CheckDiskResize(profileName, zone, diskID string, sizeGB int) (*sakura.DiskResizeCheck, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDiskService(client)
+	return service.CheckResize(a.ctx, zone, diskID, sizeGB)
+}
+
+// ResizeDisk はディスクを大きいサイズのディスクへコピーして差し替える。
+// 進捗は "disk:resize:progress" イベントで (元のディスクID, DiskResizeProgress) として通知する。
+func (a *App) ResizeDisk(profileName, zone, diskID string, input sakura.DiskResizeInput) (*sakura.DiskInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDiskService(client)
+	return service.Resize(a.ctx, zone, diskID, input, func(p sakura.DiskResizeProgress) {
+		a.emitEvent("disk:resize:progress", diskID, p)
+	})
+}
+
+func (a *App) ConnectDiskToServer(profileName, zone, diskID, serverID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewDiskService(client)
+	return service.ConnectToServer(a.ctx, zone, diskID, serverID)
+}
+
+func (a *App) DisconnectDiskFromServer(profileName, zone, diskID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewDiskService(client)
+	return service.DisconnectFromServer(a.ctx, zone, diskID)
+}
+
+func (a *App) GetDiskMonitor(profileName, zone, diskID string, start, end, intervalSec int64, aggregation string) ([]sakura.DiskActivityValueInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDiskService(client)
+	return service.Monitor(a.ctx, zone, diskID, start, end, intervalSec, aggregation)
+}
+
+// Archives
+func (a *App) GetArchives(profileName, zone string) ([]sakura.ArchiveInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) DeleteArchive(profileName, zone, archiveID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.Delete(a.ctx, zone, archiveID)
+}
+
+func (a *App) CreateArchive(profileName, zone, name, description string, tags []string, sourceDiskID, sourceArchiveID string) (*sakura.ArchiveInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.Create(a.ctx, zone, name, description, tags, sourceDiskID, sourceArchiveID)
+}
+
+func (a *App) CreateBlankArchive(profileName, zone, name, description string, tags []string, sizeGB int) (*sakura.ArchiveWithFTP, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.CreateBlank(a.ctx, zone, name, description, tags, sizeGB)
+}
+
+func (a *App) OpenArchiveFTP(profileName, zone, archiveID string, changePassword bool) (*sakura.FTPServerInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.OpenFTP(a.ctx, zone, archiveID, changePassword)
+}
+
+func (a *App) CloseArchiveFTP(profileName, zone, archiveID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.CloseFTP(a.ctx, zone, archiveID)
+}
+
+// selectDiskImage はアップロードするディスクイメージ(raw/qcow2)をファイルダイアログで選ぶ。
+func (a *App) selectDiskImage() (string, error) {
+	localPath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "アップロードするディスクイメージを選択",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "ディスクイメージ (*.raw, *.img, *.qcow2)", Pattern: "*.raw;*.img;*.qcow2"},
+			{DisplayName: "すべてのファイル", Pattern: "*"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if localPath == "" {
+		return "", fmt.Errorf("cancelled")
+	}
+	return localPath, nil
+}
+
+// UploadArchiveImage は既存のアーカイブにディスクイメージをアップロードし、完了したらFTPを閉じる。
+// 進捗は "archive:upload:progress" イベントで (アーカイブID, ftps.Progress) として通知する。
+func (a *App) UploadArchiveImage(profileName, zone, archiveID string) (*sakura.ArchiveInfo, error) {
+	localPath, err := a.selectDiskImage()
+	if err != nil {
+		return nil, err
+	}
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.UploadImage(a.ctx, zone, archiveID, localPath, func(p ftps.Progress) {
+		a.emitEvent("archive:upload:progress", archiveID, p)
+	})
+}
+
+// CreateArchiveFromImage は空のアーカイブを作成してディスクイメージをアップロードし、完了したらFTPを閉じる。
+// 進捗は "archive:upload:progress" イベントで (アーカイブ名, ftps.Progress) として通知する。
+// 転送に失敗した場合は "archive:upload:failed" イベントで (アーカイブ名, アーカイブID) を通知する。
+// 続きから送るにはそのアーカイブIDでUploadArchiveImageを呼ぶ。
+func (a *App) CreateArchiveFromImage(profileName, zone, name, description string, tags []string, sizeGB int) (*sakura.ArchiveInfo, error) {
+	localPath, err := a.selectDiskImage()
+	if err != nil {
+		return nil, err
+	}
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewArchiveService(client)
+	archive, err := service.CreateFromImage(a.ctx, zone, name, description, tags, sizeGB, localPath, func(p ftps.Progress) {
+		a.emitEvent("archive:upload:progress", name, p)
+	})
+	var uploadErr *sakura.ArchiveUploadError
+	if errors.As(err, &uploadErr) {
+		a.emitEvent("archive:upload:failed", name, uploadErr.ArchiveID)
+	}
+	return archive, err
+}
+
+func sharedArchiveStore() (*sakura.SharedArchiveStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return sakura.NewSharedArchiveStore(filepath.Join(dir, "sakpilot", "shared-archives.json")), nil
+}
+
+// ShareArchive はアーカイブの共有キーを発行し、共有したアーカイブの一覧に記録する。
+func (a *App) ShareArchive(profileName, zone, archiveID string) (string, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return "", err
+	}
+	store, err := sharedArchiveStore()
+	if err != nil {
+		return "", err
+	}
+	service := sakura.NewArchiveService(client)
+	key, err := service.Share(a.ctx, zone, archiveID)
+	if err != nil {
+		return "", err
+	}
+	record := sakura.SharedArchiveRecord{
+		Profile:   profileName,
+		Zone:      zone,
+		ArchiveID: archiveID,
+		SharedKey: key,
+		SharedAt:  time.Now().Format(time.RFC3339),
+	}
+	if err := store.Add(record); err != nil {
+		return key, fmt.Errorf("共有キー %s を発行しましたが、記録に失敗しました: %w", key, err)
+	}
+	return key, nil
+}
+
+// UnshareArchive はアーカイブの共有を解除し、発行済みの共有キーを無効にする。
+func (a *App) UnshareArchive(profileName, zone, archiveID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	store, err := sharedArchiveStore()
+	if err != nil {
+		return err
+	}
+	service := sakura.NewArchiveService(client)
+	if err := service.Unshare(a.ctx, zone, archiveID); err != nil {
+		return err
+	}
+	return store.MarkUnshared(profileName, zone, archiveID, time.Now())
+}
+
+// GetSharedArchives はこのプロファイルで共有したアーカイブの記録を、現在のアーカイブの状態と合わせて返す。
+func (a *App) GetSharedArchives(profileName string) ([]sakura.SharedArchiveInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := sharedArchiveStore()
+	if err != nil {
+		return nil, err
+	}
+	records, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	mine := make([]sakura.SharedArchiveRecord, 0, len(records))
+	for _, r := range records {
+		if r.Profile == profileName {
+			mine = append(mine, r)
+		}
+	}
+	service := sakura.NewArchiveService(client)
+	return service.ListShared(a.ctx, mine)
+}
+
+// GetArchiveTransfers は指定したゾーンで、コピー・転送・アップロード中のアーカイブを返す。
+func (a *App) GetArchiveTransfers(profileName string, zones []string) ([]sakura.ArchiveTransferInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewArchiveService(client)
+	return service.ListTransfers(a.ctx, zones)
 }
 
-// PacketFilters
-func (a *App) GetPacketFilters(profileName, zone string) ([]sakura.PacketFilterInfo, error) {
+func (a *App) CreateArchiveFromShared(profileName, destZone, sharedKey, name, description string, tags []string) (*sakura.ArchiveInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewPacketFilterService(client)
+	service := sakura.NewArchiveService(client)
+	return service.CreateFromShared(a.ctx, destZone, sharedKey, name, description, tags)
+}
+
+// AutoBackups
+func (a *App) GetAutoBackups(profileName, zone string) ([]sakura.AutoBackupInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewAutoBackupService(client)
 	return service.List(a.ctx, zone)
 }
 
-func (a *App) GetPacketFilterDetail(profileName, zone, pfId string) (*sakura.PacketFilterInfo, error) {
+func (a *App) CreateAutoBackup(profileName, zone string, input sakura.AutoBackupInput) (*sakura.AutoBackupInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewPacketFilterService(client)
-	return service.Get(a.ctx, zone, pfId)
+	service := sakura.NewAutoBackupService(client)
+	return service.Create(a.ctx, zone, input)
 }
 
-func (a *App) DeletePacketFilter(profileName, zone, pfId string) error {
+func (a *App) UpdateAutoBackup(profileName, zone, autoBackupID string, input sakura.AutoBackupInput) (*sakura.AutoBackupInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewAutoBackupService(client)
+	return service.Update(a.ctx, zone, autoBackupID, input)
+}
+
+func (a *App) DeleteAutoBackup(profileName, zone, autoBackupID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewPacketFilterService(client)
-	return service.Delete(a.ctx, zone, pfId)
+	service := sakura.NewAutoBackupService(client)
+	return service.Delete(a.ctx, zone, autoBackupID)
 }
 
-func (a *App) CreatePacketFilter(profileName, zone, name, description string, rules []sakura.PacketFilterRuleInfo) (*sakura.PacketFilterInfo, error) {
+// GetAutoBackupArchives は自動バックアップ対象のディスクから作成されたアーカイブ(手動で作成したものを含む)を新しい順に返す。
+func (a *App) GetAutoBackupArchives(profileName, zone, autoBackupID string) ([]sakura.ArchiveInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewPacketFilterService(client)
-	return service.Create(a.ctx, zone, name, description, rules)
+	service := sakura.NewAutoBackupService(client)
+	return service.ListArchives(a.ctx, zone, autoBackupID)
 }
 
-func (a *App) UpdatePacketFilter(profileName, zone, pfId, name, description string, rules []sakura.PacketFilterRuleInfo) (*sakura.PacketFilterInfo, error) {
+// RestoreAutoBackup は自動バックアップのアーカイブから新しいディスクを作成する。
+func (a *App) RestoreAutoBackup(profileName, zone, autoBackupID, archiveID string, input sakura.AutoBackupRestoreInput) (*sakura.DiskInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewPacketFilterService(client)
-	return service.Update(a.ctx, zone, pfId, name, description, rules)
+	service := sakura.NewAutoBackupService(client)
+	return service.Restore(a.ctx, zone, autoBackupID, archiveID, input)
 }
 
-// Disks
-func (a *App) GetDisks(profileName, zone string) ([]sakura.DiskInfo, error) {
+// VPC Routers
+func (a *App) GetVPCRouters(profileName, zone string) ([]sakura.VPCRouterInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDiskService(client)
+	service := sakura.NewVPCRouterService(client)
 	return service.List(a.ctx, zone)
 }
 
-func (a *App) DeleteDisk(profileName, zone, diskID string) error {
+func (a *App) GetVPCRouterDetail(profileName, zone, vpcRouterID string) (*sakura.VPCRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.Get(a.ctx, zone, vpcRouterID)
+}
+
+// CreateVPCRouter はVPCルーターを作成する。input.Bootが指定されていれば、利用可能になるのを待って起動する。
+func (a *App) CreateVPCRouter(profileName, zone string, input sakura.VPCRouterCreateInput) (*sakura.VPCRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.Create(a.ctx, zone, input)
+}
+
+func (a *App) UpdateVPCRouter(profileName, zone, vpcRouterID, name, description string, tags []string) (*sakura.VPCRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.Update(a.ctx, zone, vpcRouterID, name, description, tags)
+}
+
+func (a *App) DeleteVPCRouter(profileName, zone, vpcRouterID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewDiskService(client)
-	return service.Delete(a.ctx, zone, diskID)
+	service := sakura.NewVPCRouterService(client)
+	return service.Delete(a.ctx, zone, vpcRouterID)
 }
 
-func (a *App) GetDiskDetail(profileName, zone, diskID string) (*sakura.DiskInfo, error) {
+func (a *App) PowerOnVPCRouter(profileName, zone, vpcRouterID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.PowerOn(a.ctx, zone, vpcRouterID)
+}
+
+func (a *App) PowerOffVPCRouter(profileName, zone, vpcRouterID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.PowerOff(a.ctx, zone, vpcRouterID)
+}
+
+func (a *App) ForceStopVPCRouter(profileName, zone, vpcRouterID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.ForceStop(a.ctx, zone, vpcRouterID)
+}
+
+func (a *App) ResetVPCRouter(profileName, zone, vpcRouterID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.Reset(a.ctx, zone, vpcRouterID)
+}
+
+// UpdateVPCRouterSettings はNAT・ポートフォワーディング・ファイアウォール・DHCP・VPN・スタティックルート等の設定を保存し、VPCルーターに反映する。
+func (a *App) UpdateVPCRouterSettings(profileName, zone, vpcRouterID string, settings sakura.VPCRouterSettings) (*sakura.VPCRouterInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDiskService(client)
-	return service.Get(a.ctx, zone, diskID)
+	service := sakura.NewVPCRouterService(client)
+	return service.UpdateSettings(a.ctx, zone, vpcRouterID, settings)
 }
 
-func (a *App) CreateDisk(profileName, zone, name, description string, tags []string, sizeGB int, diskPlan, connection, sourceArchiveID, serverID string) (*sakura.DiskInfo, error) {
+// ConfigureVPCRouterInterface はプライベート側NICをスイッチに接続してIPアドレスを設定する。
+func (a *App) ConfigureVPCRouterInterface(profileName, zone, vpcRouterID string, input sakura.VPCRouterInterfaceInput) (*sakura.VPCRouterInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDiskService(client)
-	return service.Create(a.ctx, zone, name, description, tags, sizeGB, diskPlan, connection, sourceArchiveID, serverID)
+	service := sakura.NewVPCRouterService(client)
+	return service.ConfigureInterface(a.ctx, zone, vpcRouterID, input)
 }
 
-func (a *App) UpdateDisk(profileName, zone, diskID, name, description string, tags []string) (*sakura.DiskInfo, error) {
+func (a *App) DisconnectVPCRouterInterface(profileName, zone, vpcRouterID string, index int) (*sakura.VPCRouterInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDiskService(client)
-	return service.Update(a.ctx, zone, diskID, name, description, tags)
+	service := sakura.NewVPCRouterService(client)
+	return service.DisconnectInterface(a.ctx, zone, vpcRouterID, index)
 }
 
-func (a *App) ConnectDiskToServer(profileName, zone, diskID, serverID string) error {
+func (a *App) GetVPCRouterStatus(profileName, zone, vpcRouterID string) (*sakura.VPCRouterStatusInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.GetStatus(a.ctx, zone, vpcRouterID)
+}
+
+func (a *App) GetVPCRouterLog(profileName, zone, vpcRouterID string) (string, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return "", err
+	}
+	service := sakura.NewVPCRouterService(client)
+	return service.GetLog(a.ctx, zone, vpcRouterID)
+}
+
+// Databases
+func (a *App) GetDatabases(profileName, zone string) ([]sakura.DatabaseInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.List(a.ctx, zone)
+}
+
+func (a *App) GetDatabaseDetail(profileName, zone, databaseID string) (*sakura.DatabaseInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.Get(a.ctx, zone, databaseID)
+}
+
+func (a *App) CreateDatabase(profileName, zone string, params sakura.CreateDatabaseParams) (*sakura.DatabaseInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.Create(a.ctx, zone, params)
+}
+
+func (a *App) UpdateDatabase(profileName, zone, databaseID, name, description string, tags []string) (*sakura.DatabaseInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.Update(a.ctx, zone, databaseID, name, description, tags)
+}
+
+func (a *App) UpdateDatabaseSettings(profileName, zone, databaseID string, params sakura.DatabaseSettingsParams) (*sakura.DatabaseInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.UpdateSettings(a.ctx, zone, databaseID, params)
+}
+
+func (a *App) GetDatabaseParameter(profileName, zone, databaseID string) (*sakura.DatabaseParameterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.GetParameter(a.ctx, zone, databaseID)
+}
+
+func (a *App) SetDatabaseParameter(profileName, zone, databaseID string, params map[string]any) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewDiskService(client)
-	return service.ConnectToServer(a.ctx, zone, diskID, serverID)
+	service := sakura.NewDatabaseService(client)
+	return service.SetParameter(a.ctx, zone, databaseID, params)
 }
 
-func (a *App) DisconnectDiskFromServer(profileName, zone, diskID string) error {
+func (a *App) PowerOnDatabase(profileName, zone, databaseID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewDiskService(client)
-	return service.DisconnectFromServer(a.ctx, zone, diskID)
+	service := sakura.NewDatabaseService(client)
+	return service.PowerOn(a.ctx, zone, databaseID)
 }
 
-// Archives
-func (a *App) GetArchives(profileName, zone string) ([]sakura.ArchiveInfo, error) {
+func (a *App) PowerOffDatabase(profileName, zone, databaseID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.PowerOff(a.ctx, zone, databaseID)
+}
+
+func (a *App) ForceStopDatabase(profileName, zone, databaseID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.ForceStop(a.ctx, zone, databaseID)
+}
+
+func (a *App) ResetDatabase(profileName, zone, databaseID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.Reset(a.ctx, zone, databaseID)
+}
+
+func (a *App) DeleteDatabase(profileName, zone, databaseID string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.Delete(a.ctx, zone, databaseID)
+}
+
+func (a *App) GetDatabaseStatus(profileName, zone, databaseID string) (string, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return "", err
+	}
+	service := sakura.NewDatabaseService(client)
+	return service.GetStatus(a.ctx, zone, databaseID)
+}
+
+// NFS
+func (a *App) GetNFSList(profileName, zone string) ([]sakura.NFSInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewArchiveService(client)
+	service := sakura.NewNFSService(client)
 	return service.List(a.ctx, zone)
 }
 
-func (a *App) DeleteArchive(profileName, zone, archiveID string) error {
+func (a *App) PowerOnNFS(profileName, zone, nfsID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewArchiveService(client)
-	return service.Delete(a.ctx, zone, archiveID)
+	service := sakura.NewNFSService(client)
+	return service.PowerOn(a.ctx, zone, nfsID)
 }
 
-func (a *App) CreateArchive(profileName, zone, name, description string, tags []string, sourceDiskID, sourceArchiveID string) (*sakura.ArchiveInfo, error) {
+func (a *App) PowerOffNFS(profileName, zone, nfsID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	service := sakura.NewArchiveService(client)
-	return service.Create(a.ctx, zone, name, description, tags, sourceDiskID, sourceArchiveID)
+	service := sakura.NewNFSService(client)
+	return service.PowerOff(a.ctx, zone, nfsID)
 }
 
-func (a *App) CreateBlankArchive(profileName, zone, name, description string, tags []string, sizeGB int) (*sakura.ArchiveWithFTP, error) {
+func (a *App) ForceStopNFS(profileName, zone, nfsID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	service := sakura.NewArchiveService(client)
-	return service.CreateBlank(a.ctx, zone, name, description, tags, sizeGB)
+	service := sakura.NewNFSService(client)
+	return service.ForceStop(a.ctx, zone, nfsID)
 }
 
-func (a *App) OpenArchiveFTP(profileName, zone, archiveID string, changePassword bool) (*sakura.FTPServerInfo, error) {
+func (a *App) DeleteNFS(profileName, zone, nfsID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	service := sakura.NewArchiveService(client)
-	return service.OpenFTP(a.ctx, zone, archiveID, changePassword)
+	service := sakura.NewNFSService(client)
+	return service.Delete(a.ctx, zone, nfsID)
 }
 
-func (a *App) CloseArchiveFTP(profileName, zone, archiveID string) error {
+func (a *App) ResetNFS(profileName, zone, nfsID string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewArchiveService(client)
-	return service.CloseFTP(a.ctx, zone, archiveID)
+	service := sakura.NewNFSService(client)
+	return service.Reset(a.ctx, zone, nfsID)
 }
 
-func (a *App) ShareArchive(profileName, zone, archiveID string) (string, error) {
+func (a *App) GetNFSStatus(profileName, zone, nfsID string) (string, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return "", err
 	}
-	service := sakura.NewArchiveService(client)
-	return service.Share(a.ctx, zone, archiveID)
+	service := sakura.NewNFSService(client)
+	return service.GetStatus(a.ctx, zone, nfsID)
 }
 
-func (a *App) CreateArchiveFromShared(profileName, destZone, sharedKey, name, description string, tags []string) (*sakura.ArchiveInfo, error) {
+func (a *App) GetNFSDetail(profileName, zone, nfsID string) (*sakura.NFSInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewArchiveService(client)
-	return service.CreateFromShared(a.ctx, destZone, sharedKey, name, description, tags)
+	service := sakura.NewNFSService(client)
+	return service.Get(a.ctx, zone, nfsID)
 }
 
-// Databases
-func (a *App) GetDatabases(profileName, zone string) ([]sakura.DatabaseInfo, error) {
+func (a *App) CreateNFS(profileName, zone string, params sakura.NFSCreateParams) (*sakura.NFSInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.List(a.ctx, zone)
+	service := sakura.NewNFSService(client)
+	return service.Create(a.ctx, zone, params)
 }
 
-func (a *App) GetDatabaseDetail(profileName, zone, databaseID string) (*sakura.DatabaseInfo, error) {
+func (a *App) UpdateNFS(profileName, zone, nfsID, name, description string, tags []string) (*sakura.NFSInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.Get(a.ctx, zone, databaseID)
+	service := sakura.NewNFSService(client)
+	return service.Update(a.ctx, zone, nfsID, name, description, tags)
 }
 
-func (a *App) CreateDatabase(profileName, zone string, params sakura.CreateDatabaseParams) (*sakura.DatabaseInfo, error) {
+// LoadBalancer
+func (a *App) GetLoadBalancers(profileName, zone string) ([]sakura.LoadBalancerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.Create(a.ctx, zone, params)
+	service := sakura.NewLoadBalancerService(client)
+	return service.List(a.ctx, zone)
 }
 
-func (a *App) UpdateDatabase(profileName, zone, databaseID, name, description string, tags []string) (*sakura.DatabaseInfo, error) {
+func (a *App) GetLoadBalancerDetail(profileName, zone, loadBalancerId string) (*sakura.LoadBalancerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.Update(a.ctx, zone, databaseID, name, description, tags)
+	service := sakura.NewLoadBalancerService(client)
+	return service.Get(a.ctx, zone, loadBalancerId)
 }
 
-func (a *App) UpdateDatabaseSettings(profileName, zone, databaseID string, params sakura.DatabaseSettingsParams) (*sakura.DatabaseInfo, error) {
+func (a *App) GetLoadBalancerHealth(profileName, zone, loadBalancerId string) (*sakura.LoadBalancerHealthInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.UpdateSettings(a.ctx, zone, databaseID, params)
+	service := sakura.NewLoadBalancerService(client)
+	return service.GetHealth(a.ctx, zone, loadBalancerId)
 }
 
-func (a *App) GetDatabaseParameter(profileName, zone, databaseID string) (*sakura.DatabaseParameterInfo, error) {
+func (a *App) CreateLoadBalancer(profileName, zone string, input sakura.LoadBalancerCreateInput) (*sakura.LoadBalancerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.GetParameter(a.ctx, zone, databaseID)
+	service := sakura.NewLoadBalancerService(client)
+	return service.Create(a.ctx, zone, input)
 }
 
-func (a *App) SetDatabaseParameter(profileName, zone, databaseID string, params map[string]any) error {
+func (a *App) UpdateLoadBalancer(profileName, zone, loadBalancerId, name, description string, tags []string) (*sakura.LoadBalancerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.SetParameter(a.ctx, zone, databaseID, params)
+	service := sakura.NewLoadBalancerService(client)
+	return service.Update(a.ctx, zone, loadBalancerId, name, description, tags)
 }
 
-func (a *App) PowerOnDatabase(profileName, zone, databaseID string) error {
+func (a *App) UpdateLoadBalancerSettings(profileName, zone, loadBalancerId string, input sakura.LoadBalancerSettingsInput) (*sakura.LoadBalancerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.PowerOn(a.ctx, zone, databaseID)
+	service := sakura.NewLoadBalancerService(client)
+	return service.UpdateSettings(a.ctx, zone, loadBalancerId, input)
 }
 
-func (a *App) PowerOffDatabase(profileName, zone, databaseID string) error {
+func (a *App) DeleteLoadBalancer(profileName, zone, loadBalancerId string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.PowerOff(a.ctx, zone, databaseID)
+	service := sakura.NewLoadBalancerService(client)
+	return service.Delete(a.ctx, zone, loadBalancerId)
 }
 
-func (a *App) ForceStopDatabase(profileName, zone, databaseID string) error {
+func (a *App) PowerOnLoadBalancer(profileName, zone, loadBalancerId string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.ForceStop(a.ctx, zone, databaseID)
+	service := sakura.NewLoadBalancerService(client)
+	return service.PowerOn(a.ctx, zone, loadBalancerId)
 }
 
-func (a *App) ResetDatabase(profileName, zone, databaseID string) error {
+func (a *App) PowerOffLoadBalancer(profileName, zone, loadBalancerId string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.Reset(a.ctx, zone, databaseID)
+	service := sakura.NewLoadBalancerService(client)
+	return service.PowerOff(a.ctx, zone, loadBalancerId)
 }
 
-func (a *App) DeleteDatabase(profileName, zone, databaseID string) error {
+func (a *App) ForceStopLoadBalancer(profileName, zone, loadBalancerId string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.Delete(a.ctx, zone, databaseID)
+	service := sakura.NewLoadBalancerService(client)
+	return service.ForceStop(a.ctx, zone, loadBalancerId)
 }
 
-func (a *App) GetDatabaseStatus(profileName, zone, databaseID string) (string, error) {
+func (a *App) ResetLoadBalancer(profileName, zone, loadBalancerId string) error {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return "", err
+		return err
 	}
-	service := sakura.NewDatabaseService(client)
-	return service.GetStatus(a.ctx, zone, databaseID)
+	service := sakura.NewLoadBalancerService(client)
+	return service.Reset(a.ctx, zone, loadBalancerId)
 }
 
-// NFS
-func (a *App) GetNFSList(profileName, zone string) ([]sakura.NFSInfo, error) {
+// Bulk operations
+// RunBulkOperation はサーバー・データベース・NFSに一括で電源操作または削除を行う。
+// 各対象の進捗は "bulk:progress" イベントで BulkItemResult として通知し、全対象の完了後に結果をまとめて返す。
+func (a *App) RunBulkOperation(profileName string, input sakura.BulkOperationInput) (*sakura.BulkOperationSummary, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewNFSService(client)
-	return service.List(a.ctx, zone)
+	service := sakura.NewBulkService(client)
+	return service.Run(a.ctx, input, func(item sakura.BulkItemResult) {
+		a.emitEvent("bulk:progress", item)
+	})
 }
 
-func (a *App) PowerOnNFS(profileName, zone, nfsID string) error {
-	client, err := sakura.NewClientFromProfile(profileName)
-	if err != nil {
-		return err
+// State waiting
+// 状態待ちは呼び出し側が指定したwaitIDで CancelWait により中断できる。
+// 途中経過は "state:progress" イベントで (waitID, WaitStateProgress) として通知する。
+
+// beginWait はwaitIDで中断できるcontextを返す。doneは待ち終了時に必ず呼ぶ。
+func (a *App) beginWait(waitID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(a.ctx)
+	if waitID == "" {
+		return ctx, cancel
+	}
+	a.waitsMu.Lock()
+	if a.waits == nil {
+		a.waits = make(map[string]context.CancelFunc)
+	}
+	a.waits[waitID] = cancel
+	a.waitsMu.Unlock()
+	return ctx, func() {
+		a.waitsMu.Lock()
+		delete(a.waits, waitID)
+		a.waitsMu.Unlock()
+		cancel()
 	}
-	service := sakura.NewNFSService(client)
-	return service.PowerOn(a.ctx, zone, nfsID)
 }
 
-func (a *App) PowerOffNFS(profileName, zone, nfsID string) error {
-	client, err := sakura.NewClientFromProfile(profileName)
-	if err != nil {
-		return err
+func (a *App) waitProgress(waitID string) func(sakura.WaitStateProgress) {
+	return func(p sakura.WaitStateProgress) {
+		a.emitEvent("state:progress", waitID, p)
 	}
-	service := sakura.NewNFSService(client)
-	return service.PowerOff(a.ctx, zone, nfsID)
 }
 
-func (a *App) ForceStopNFS(profileName, zone, nfsID string) error {
+// CancelWait は実行中の状態待ちを中断する。電源操作自体は取り消されない。
+func (a *App) CancelWait(waitID string) {
+	a.waitsMu.Lock()
+	defer a.waitsMu.Unlock()
+	if cancel, ok := a.waits[waitID]; ok {
+		cancel()
+	}
+}
+
+// PowerServerAndWait は電源操作(powerOn/powerOff/forceStop/reset)を行い、サーバーが起動または停止するまで待つ。
+func (a *App) PowerServerAndWait(profileName, zone, serverID, action, waitID string, timeoutSec int) (*sakura.ServerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	service := sakura.NewNFSService(client)
-	return service.ForceStop(a.ctx, zone, nfsID)
+	ctx, done := a.beginWait(waitID)
+	defer done()
+	service := sakura.NewServerService(client)
+	return service.PowerAndWait(ctx, zone, serverID, action, timeoutSec, a.waitProgress(waitID))
 }
 
-func (a *App) DeleteNFS(profileName, zone, nfsID string) error {
+func (a *App) PowerDatabaseAndWait(profileName, zone, databaseID, action, waitID string, timeoutSec int) (*sakura.DatabaseInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	service := sakura.NewNFSService(client)
-	return service.Delete(a.ctx, zone, nfsID)
+	ctx, done := a.beginWait(waitID)
+	defer done()
+	service := sakura.NewDatabaseService(client)
+	return service.PowerAndWait(ctx, zone, databaseID, action, timeoutSec, a.waitProgress(waitID))
 }
 
-func (a *App) ResetNFS(profileName, zone, nfsID string) error {
+func (a *App) PowerNFSAndWait(profileName, zone, nfsID, action, waitID string, timeoutSec int) (*sakura.NFSInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	ctx, done := a.beginWait(waitID)
+	defer done()
 	service := sakura.NewNFSService(client)
-	return service.Reset(a.ctx, zone, nfsID)
+	return service.PowerAndWait(ctx, zone, nfsID, action, timeoutSec, a.waitProgress(waitID))
 }
 
-func (a *App) GetNFSStatus(profileName, zone, nfsID string) (string, error) {
+func (a *App) PowerVPCRouterAndWait(profileName, zone, vpcRouterID, action, waitID string, timeoutSec int) (*sakura.VPCRouterInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	service := sakura.NewNFSService(client)
-	return service.GetStatus(a.ctx, zone, nfsID)
+	ctx, done := a.beginWait(waitID)
+	defer done()
+	service := sakura.NewVPCRouterService(client)
+	return service.PowerAndWait(ctx, zone, vpcRouterID, action, timeoutSec, a.waitProgress(waitID))
 }
 
-func (a *App) GetNFSDetail(profileName, zone, nfsID string) (*sakura.NFSInfo, error) {
+func (a *App) PowerLoadBalancerAndWait(profileName, zone, loadBalancerId, action, waitID string, timeoutSec int) (*sakura.LoadBalancerInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewNFSService(client)
-	return service.Get(a.ctx, zone, nfsID)
+	ctx, done := a.beginWait(waitID)
+	defer done()
+	service := sakura.NewLoadBalancerService(client)
+	return service.PowerAndWait(ctx, zone, loadBalancerId, action, timeoutSec, a.waitProgress(waitID))
 }
 
-func (a *App) CreateNFS(profileName, zone string, params sakura.NFSCreateParams) (*sakura.NFSInfo, error) {
+// WaitDiskReady はディスクの作成・コピーが完了して利用可能になるまで待つ。
+func (a *App) WaitDiskReady(profileName, zone, diskID, waitID string, timeoutSec int) (*sakura.DiskInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewNFSService(client)
-	return service.Create(a.ctx, zone, params)
+	ctx, done := a.beginWait(waitID)
+	defer done()
+	service := sakura.NewDiskService(client)
+	return service.WaitReady(ctx, zone, diskID, timeoutSec, a.waitProgress(waitID))
 }
 
-func (a *App) UpdateNFS(profileName, zone, nfsID, name, description string, tags []string) (*sakura.NFSInfo, error) {
+// WaitArchiveReady はアーカイブの作成・コピーが完了して利用可能になるまで待つ。
+func (a *App) WaitArchiveReady(profileName, zone, archiveID, waitID string, timeoutSec int) (*sakura.ArchiveInfo, error) {
 	client, err := sakura.NewClientFromProfile(profileName)
 	if err != nil {
 		return nil, err
 	}
-	service := sakura.NewNFSService(client)
-	return service.Update(a.ctx, zone, nfsID, name, description, tags)
+	ctx, done := a.beginWait(waitID)
+	defer done()
+	service := sakura.NewArchiveService(client)
+	return service.WaitReady(ctx, zone, archiveID, timeoutSec, a.waitProgress(waitID))
 }
 
 // DNS Detail
@@ -2634,6 +3985,89 @@ func (a *App) GetProxyLBMonitorConnection(profileName, proxyLBId string, start,
 	return service.MonitorConnection(a.ctx, proxyLBId, start, end)
 }
 
+// LocalRouter
+func (a *App) GetLocalRouters(profileName string) ([]sakura.LocalRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.List(a.ctx)
+}
+
+func (a *App) GetLocalRouterDetail(profileName, localRouterId string) (*sakura.LocalRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.Get(a.ctx, localRouterId)
+}
+
+func (a *App) GetLocalRouterHealth(profileName, localRouterId string) (*sakura.LocalRouterHealthInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.GetHealth(a.ctx, localRouterId)
+}
+
+func (a *App) CreateLocalRouter(profileName, name, description string, tags []string) (*sakura.LocalRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.Create(a.ctx, name, description, tags)
+}
+
+func (a *App) UpdateLocalRouter(profileName, localRouterId, name, description string, tags []string) (*sakura.LocalRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.Update(a.ctx, localRouterId, name, description, tags)
+}
+
+func (a *App) UpdateLocalRouterSettings(profileName, localRouterId string, input sakura.LocalRouterSettingsInput) (*sakura.LocalRouterInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.UpdateSettings(a.ctx, localRouterId, input)
+}
+
+// GetLocalRouterPeerSecretKey はピアとして追加するローカルルーターのシークレットキーを返す。
+func (a *App) GetLocalRouterPeerSecretKey(profileName, peerId string) (string, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return "", err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.PeerSecretKey(a.ctx, peerId)
+}
+
+func (a *App) DeleteLocalRouter(profileName, localRouterId string) error {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.Delete(a.ctx, localRouterId)
+}
+
+func (a *App) GetLocalRouterMonitorTraffic(profileName, localRouterId string, start, end int64) ([]sakura.LocalRouterTrafficValueInfo, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	service := sakura.NewLocalRouterService(client)
+	return service.MonitorTraffic(a.ctx, localRouterId, start, end)
+}
+
 // SimpleMQ
 func (a *App) GetSimpleMQQueues(profileName string) ([]simplemq.QueueInfo, error) {
 	service, err := simplemq.NewService(profileName)
@@ -3645,3 +5079,123 @@ func (a *App) DeleteCloudHSMLicense(profileName, id string) error {
 	}
 	return service.DeleteLicense(a.ctx, id)
 }
+
+// Resource dependency graph
+func (a *App) GetResourceDependencyGraph(profileName string, zones []string, buckets []sakura.DependencyBucketRef) (*sakura.DependencyGraph, error) {
+	return a.buildDependencyGraph(profileName, zones, buckets)
+}
+
+// GetResourceDependents は指定ノードを利用しているリソースの一覧を返す。削除前の警告表示に使う。
+func (a *App) GetResourceDependents(profileName string, zones []string, buckets []sakura.DependencyBucketRef, nodeID string) ([]sakura.DependencyNode, error) {
+	graph, err := a.buildDependencyGraph(profileName, zones, buckets)
+	if err != nil {
+		return nil, err
+	}
+	return graph.Dependents(nodeID), nil
+}
+
+func (a *App) buildDependencyGraph(profileName string, zones []string, buckets []sakura.DependencyBucketRef) (*sakura.DependencyGraph, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := sakura.NewDependencyService(client).Build(a.ctx, zones)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.addKMSReferences(client, profileName, graph, buckets); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// addKMSReferences はKMSキーと、それを参照するVault・バケットをグラフに追加する。
+func (a *App) addKMSReferences(client *sakura.Client, profileName string, graph *sakura.DependencyGraph, buckets []sakura.DependencyBucketRef) error {
+	kmsService, err := kms.NewService(profileName)
+	if err != nil {
+		return err
+	}
+	keys, err := kmsService.ListKeys(a.ctx)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		graph.AddKMSKey(k.ID, k.Name, k.Status)
+	}
+
+	smService, err := secretmanager.NewService(profileName)
+	if err != nil {
+		return err
+	}
+	vaults, err := smService.ListVaults(a.ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range vaults {
+		graph.AddVaultEncryption(v.ID, v.Name, v.KmsKeyID)
+	}
+
+	osService := sakura.NewObjectStorageService(client)
+	for _, b := range buckets {
+		enc, err := osService.ReadBucketEncryption(a.ctx, b.SiteID, b.BucketName)
+		if err != nil {
+			return err
+		}
+		graph.AddBucketEncryption(b.SiteID, b.BucketName, enc.KMSKeyID)
+	}
+	return nil
+}
+
+// Waste detector
+// GetWasteReport は使われていないリソースを検出する。KMSキーはbucketsで指定したバケットとVaultからの参照有無で判定する。
+func (a *App) GetWasteReport(profileName string, zones []string, archiveUnusedDays int, buckets []sakura.DependencyBucketRef) (*sakura.WasteReport, error) {
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	report, err := sakura.NewWasteService(client).Analyze(a.ctx, zones, archiveUnusedDays)
+	if err != nil {
+		return nil, err
+	}
+	// KMSキーの参照元はIaaSリソースに含まれないため、IaaSを再走査せずKMS関連のノードだけでグラフを作る
+	graph := sakura.NewDependencyGraph()
+	if err := a.addKMSReferences(client, profileName, graph, buckets); err != nil {
+		return nil, err
+	}
+	return sakura.NewWasteReport(append(report.Findings, sakura.DetectUnreferencedKMSKeys(graph)...)), nil
+}
+
+func (a *App) CleanupWasteFinding(profileName string, finding sakura.WasteFinding) error {
+	if finding.Kind == sakura.WasteKindUnreferencedKMSKey {
+		return a.suspendUnreferencedKMSKey(profileName, finding)
+	}
+	client, err := sakura.NewClientFromProfile(profileName)
+	if err != nil {
+		return err
+	}
+	return sakura.NewWasteService(client).Cleanup(a.ctx, finding)
+}
+
+// suspendUnreferencedKMSKey は検出時から参照元が増えていないことをVaultの全件から確かめ直してKMSキーを一時停止する。
+// バケットは全件を列挙できないため、見落とした参照があっても復旧できるよう削除ではなく一時停止にとどめる。
+func (a *App) suspendUnreferencedKMSKey(profileName string, finding sakura.WasteFinding) error {
+	smService, err := secretmanager.NewService(profileName)
+	if err != nil {
+		return err
+	}
+	vaults, err := smService.ListVaults(a.ctx)
+	if err != nil {
+		return fmt.Errorf("Vaultの一覧を取得できないため、KMSキー %s の参照元を確認できません: %w", finding.ResourceName, err)
+	}
+	for _, v := range vaults {
+		if v.KmsKeyID == finding.ResourceID {
+			return fmt.Errorf("KMSキー %s はVault %s から参照されているため一時停止できません", finding.ResourceName, v.Name)
+		}
+	}
+
+	kmsService, err := kms.NewService(profileName)
+	if err != nil {
+		return err
+	}
+	return kmsService.ChangeKeyStatus(a.ctx, finding.ResourceID, "suspended")
+}
@@ -0,0 +1,364 @@
+// Package ftps はさくらのクラウドのアーカイブやISOイメージのアップロードに使う、明示的FTPS(AUTH TLS)のクライアント。
+//
+// アップロードに必要な最低限のコマンドだけを実装している。データ接続もTLSで保護し(PROT P)、
+// 制御接続のTLSセッションを再利用してデータ接続を張る。
+// 転送が途中で切れた場合は、SIZEでサーバー上のサイズを確認し、RESTでその続きから送り直せる。
+package ftps
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPort = 21
+	dialTimeout = 30 * time.Second
+	bufferSize  = 256 * 1024
+	// progressInterval はProgressを通知する最小の転送量。
+	progressInterval = 1 << 20
+)
+
+// Config はFTPSサーバーへの接続情報。
+type Config struct {
+	Host     string
+	Port     int // 0の場合は21
+	User     string
+	Password string
+	// TLSConfig はnilの場合、Hostでサーバー証明書を検証する既定の設定を使う。
+	TLSConfig *tls.Config
+}
+
+// Progress はアップロードの進捗。途中から再開した場合、Sentには再開前に送信済みの量を含む。
+type Progress struct {
+	Sent    int64 `json:"sent"`
+	Total   int64 `json:"total"`
+	Attempt int   `json:"attempt"` // 何回目の転送か(1始まり)
+}
+
+// Options はアップロードの動作の指定。
+type Options struct {
+	// Resume はサーバーに途中までのファイルがあれば、その続きから送る。
+	Resume bool
+	// Retries は転送が途中で失敗した場合に、再接続して続きから送り直す回数。
+	Retries int
+}
+
+// retryDelay は再接続までの待ち時間。再試行のたびに延ばす。
+var retryDelay = 2 * time.Second
+
+// Upload はlocalPathのファイルをremoteNameとしてアップロードする。remoteNameが空の場合はローカルのファイル名を使う。
+// progressには一定量の転送ごとと完了時に通知する。
+func Upload(ctx context.Context, cfg Config, localPath string, remoteName string, opts Options, progress func(Progress)) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if remoteName == "" {
+		remoteName = filepath.Base(localPath)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resume := opts.Resume || attempt > 1
+		err := upload(ctx, cfg, f, stat.Size(), remoteName, resume, attempt, progress)
+		if err == nil || attempt > opts.Retries || !retryable(ctx, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay * time.Duration(attempt)):
+		}
+	}
+}
+
+func upload(ctx context.Context, cfg Config, f *os.File, total int64, remoteName string, resume bool, attempt int, progress func(Progress)) error {
+	c, err := dial(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	var offset int64
+	if resume {
+		offset = c.resumeOffset(remoteName, total)
+	}
+	w := &progressWriter{sent: offset, reported: offset, total: total, attempt: attempt, report: progress}
+	if offset == total && total > 0 {
+		w.done()
+		return nil
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	return c.stor(ctx, remoteName, f, w)
+}
+
+// retryable は送り直せば成功する見込みのあるエラーかを返す。認証や証明書の検証の失敗は再試行しない。
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code == 530 {
+		return false
+	}
+	var certErr *tls.CertificateVerificationError
+	return !errors.As(err, &certErr)
+}
+
+type client struct {
+	conn      net.Conn
+	text      *textproto.Conn
+	host      string
+	tlsConfig *tls.Config
+	stop      func() bool
+}
+
+func dial(ctx context.Context, cfg Config) (*client, error) {
+	port := cfg.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	d := net.Dialer{Timeout: dialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{conn: conn, text: textproto.NewConn(conn), host: cfg.Host, tlsConfig: clientTLSConfig(cfg)}
+	// キャンセル時はTCP接続を閉じて、TLSを含む読み書き中の処理をエラーで戻す
+	c.stop = context.AfterFunc(ctx, func() { _ = conn.Close() })
+
+	if err := c.login(ctx, cfg); err != nil {
+		c.close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func clientTLSConfig(cfg Config) *tls.Config {
+	var tlsConfig *tls.Config
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig.Clone()
+	} else {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = cfg.Host
+	}
+	// サーバーによってはデータ接続で制御接続のTLSセッションの再利用を必須にしている
+	if tlsConfig.ClientSessionCache == nil {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+	}
+	return tlsConfig
+}
+
+func (c *client) login(ctx context.Context, cfg Config) error {
+	if _, _, err := c.text.ReadResponse(220); err != nil {
+		return err
+	}
+	if _, _, err := c.cmd(234, "AUTH TLS"); err != nil {
+		return err
+	}
+	tlsConn := tls.Client(c.conn, c.tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("FTPサーバーとのTLS接続に失敗しました: %w", err)
+	}
+	c.conn = tlsConn
+	c.text = textproto.NewConn(tlsConn)
+
+	code, _, err := c.cmd(0, "USER %s", cfg.User)
+	if err != nil {
+		return err
+	}
+	if code == 331 {
+		if _, _, err := c.cmd(230, "PASS %s", cfg.Password); err != nil {
+			return err
+		}
+	} else if code != 230 {
+		return fmt.Errorf("FTPサーバーにログインできません: %d", code)
+	}
+
+	for _, line := range []string{"PBSZ 0", "PROT P", "TYPE I"} {
+		if _, _, err := c.cmd(200, "%s", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cmd はコマンドを送り、応答コードとメッセージを返す。応答コードがexpectCodeでなければエラーとする。
+// expectCodeが0の場合は応答コードを検査しない。
+func (c *client) cmd(expectCode int, format string, args ...any) (int, string, error) {
+	if _, err := c.text.Cmd(format, args...); err != nil {
+		return 0, "", err
+	}
+	code, msg, err := c.text.ReadResponse(expectCode)
+	if err != nil {
+		return code, msg, fmt.Errorf("%s: %w", strings.Fields(format)[0], err)
+	}
+	return code, msg, nil
+}
+
+// openDataConn はパッシブモードでデータ接続を張る。NAT越しでも動くよう、接続先は制御接続と同じホストとする。
+func (c *client) openDataConn(ctx context.Context) (net.Conn, error) {
+	port, err := c.passivePort()
+	if err != nil {
+		return nil, err
+	}
+	d := net.Dialer{Timeout: dialTimeout}
+	return d.DialContext(ctx, "tcp", net.JoinHostPort(c.host, strconv.Itoa(port)))
+}
+
+func (c *client) passivePort() (int, error) {
+	if _, msg, err := c.cmd(229, "EPSV"); err == nil {
+		return parseEPSV(msg)
+	}
+	_, msg, err := c.cmd(227, "PASV")
+	if err != nil {
+		return 0, err
+	}
+	return parsePASV(msg)
+}
+
+// resumeOffset はサーバー上の途中までのファイルの続きから送るための位置を返し、REST で転送開始位置を設定する。
+// サーバーがSIZEやRESTに対応していない場合や、ファイルがない場合は0を返す。
+func (c *client) resumeOffset(name string, total int64) int64 {
+	_, msg, err := c.cmd(213, "SIZE %s", name)
+	if err != nil {
+		return 0
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(msg), 10, 64)
+	if err != nil || size <= 0 || size > total {
+		return 0
+	}
+	if size == total {
+		return total
+	}
+	if _, _, err := c.cmd(350, "REST %d", size); err != nil {
+		return 0
+	}
+	return size
+}
+
+// stor はrをnameとして保存する。
+func (c *client) stor(ctx context.Context, name string, r io.Reader, w *progressWriter) error {
+	raw, err := c.openDataConn(ctx)
+	if err != nil {
+		return c.wrapErr(ctx, err)
+	}
+	defer raw.Close()
+	stop := context.AfterFunc(ctx, func() { _ = raw.Close() })
+	defer stop()
+
+	if _, _, err := c.cmd(1, "STOR %s", name); err != nil {
+		return c.wrapErr(ctx, err)
+	}
+	data := tls.Client(raw, c.tlsConfig)
+	if err := data.HandshakeContext(ctx); err != nil {
+		return c.wrapErr(ctx, fmt.Errorf("データ接続のTLS接続に失敗しました: %w", err))
+	}
+
+	if _, err := io.CopyBuffer(io.MultiWriter(data, w), r, make([]byte, bufferSize)); err != nil {
+		return c.wrapErr(ctx, err)
+	}
+	if err := data.Close(); err != nil {
+		return c.wrapErr(ctx, err)
+	}
+	if _, _, err := c.text.ReadResponse(2); err != nil {
+		return c.wrapErr(ctx, fmt.Errorf("STOR: %w", err))
+	}
+	w.done()
+	return nil
+}
+
+// wrapErr はキャンセルで接続を閉じたことによるエラーをctxのエラーに置き換える。
+func (c *client) wrapErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func (c *client) close() {
+	c.stop()
+	if c.text != nil {
+		_, _ = c.text.Cmd("QUIT")
+	}
+	_ = c.conn.Close()
+}
+
+// parseEPSV は "Entering Extended Passive Mode (|||port|)" からポート番号を取り出す。
+func parseEPSV(msg string) (int, error) {
+	start := strings.Index(msg, "(|||")
+	end := strings.LastIndex(msg, "|)")
+	if start < 0 || end <= start+4 {
+		return 0, fmt.Errorf("EPSVの応答を解釈できません: %s", msg)
+	}
+	port, err := strconv.Atoi(msg[start+4 : end])
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("EPSVの応答を解釈できません: %s", msg)
+	}
+	return port, nil
+}
+
+// parsePASV は "Entering Passive Mode (h1,h2,h3,h4,p1,p2)" からポート番号を取り出す。
+func parsePASV(msg string) (int, error) {
+	start := strings.Index(msg, "(")
+	end := strings.LastIndex(msg, ")")
+	if start < 0 || end <= start {
+		return 0, fmt.Errorf("PASVの応答を解釈できません: %s", msg)
+	}
+	fields := strings.Split(msg[start+1:end], ",")
+	if len(fields) != 6 {
+		return 0, fmt.Errorf("PASVの応答を解釈できません: %s", msg)
+	}
+	p1, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+	p2, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+	if err1 != nil || err2 != nil || p1 < 0 || p1 > 255 || p2 < 0 || p2 > 255 {
+		return 0, fmt.Errorf("PASVの応答を解釈できません: %s", msg)
+	}
+	return p1<<8 | p2, nil
+}
+
+// progressWriter は書き込まれた量を数え、progressIntervalごとに通知する。
+type progressWriter struct {
+	sent     int64
+	total    int64
+	reported int64
+	attempt  int
+	report   func(Progress)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.sent += int64(len(p))
+	if w.report != nil && w.sent-w.reported >= progressInterval {
+		w.reported = w.sent
+		w.report(Progress{Sent: w.sent, Total: w.total, Attempt: w.attempt})
+	}
+	return len(p), nil
+}
+
+func (w *progressWriter) done() {
+	if w.report != nil {
+		w.report(Progress{Sent: w.sent, Total: w.total, Attempt: w.attempt})
+	}
+}
@@ -0,0 +1,394 @@
+package ftps
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer はテスト用の明示的FTPSサーバー。アップロードされたファイルをメモリに保持する。
+type testServer struct {
+	t         *testing.T
+	listener  net.Listener
+	tlsConfig *tls.Config
+	password  string
+
+	mu    sync.Mutex
+	files map[string][]byte
+	// storHook はSTORのデータ受信中に呼ばれる。エラーを返すとそこまでの内容を保存してデータ接続を切断する。
+	storHook func(received int) error
+	stors    int
+	logins   int
+}
+
+func newTestServer(t *testing.T) (*testServer, Config) {
+	t.Helper()
+	cert, pool := testCertificate(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &testServer{
+		t:         t,
+		listener:  l,
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		password:  "secret",
+		files:     make(map[string][]byte),
+	}
+	go s.serve()
+	t.Cleanup(func() { _ = l.Close() })
+
+	addr := l.Addr().(*net.TCPAddr)
+	return s, Config{
+		Host:      "127.0.0.1",
+		Port:      addr.Port,
+		User:      "user",
+		Password:  "secret",
+		TLSConfig: &tls.Config{RootCAs: pool},
+	}
+}
+
+func testCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func (s *testServer) file(name string) []byte {
+	data, _ := s.lookup(name)
+	return data
+}
+
+func (s *testServer) lookup(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[name]
+	return data, ok
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	var ctrl net.Conn = conn
+	r := bufio.NewReader(ctrl)
+	reply := func(format string, args ...any) {
+		fmt.Fprintf(ctrl, format+"\r\n", args...)
+	}
+
+	reply("220 test server")
+	var passive net.Listener
+	var rest int64
+	defer func() {
+		if passive != nil {
+			passive.Close()
+		}
+	}()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		switch strings.ToUpper(cmd) {
+		case "AUTH":
+			reply("234 AUTH TLS ok")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			ctrl = tlsConn
+			r = bufio.NewReader(ctrl)
+		case "USER":
+			if _, ok := ctrl.(*tls.Conn); !ok {
+				reply("530 TLS required")
+				continue
+			}
+			reply("331 password required")
+		case "PASS":
+			s.mu.Lock()
+			s.logins++
+			s.mu.Unlock()
+			if arg != s.password {
+				reply("530 login incorrect")
+				continue
+			}
+			reply("230 logged in")
+		case "PBSZ", "PROT", "TYPE":
+			reply("200 ok")
+		case "SIZE":
+			data, ok := s.lookup(arg)
+			if !ok {
+				reply("550 no such file")
+				continue
+			}
+			reply("213 %d", len(data))
+		case "REST":
+			n, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				reply("501 bad offset")
+				continue
+			}
+			rest = n
+			reply("350 restarting at %d", n)
+		case "EPSV":
+			if passive != nil {
+				passive.Close()
+			}
+			passive, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				reply("425 cannot open passive port")
+				continue
+			}
+			reply("229 Entering Extended Passive Mode (|||%d|)", passive.Addr().(*net.TCPAddr).Port)
+		case "STOR":
+			if passive == nil {
+				reply("425 use EPSV first")
+				continue
+			}
+			s.stor(passive, arg, rest, reply)
+			passive.Close()
+			passive = nil
+			rest = 0
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 %s not implemented", cmd)
+		}
+	}
+}
+
+func (s *testServer) stor(passive net.Listener, name string, rest int64, reply func(string, ...any)) {
+	raw, err := passive.Accept()
+	if err != nil {
+		reply("425 cannot open data connection")
+		return
+	}
+	defer raw.Close()
+	s.mu.Lock()
+	s.stors++
+	s.mu.Unlock()
+	reply("150 ok to send data")
+	data := tls.Server(raw, s.tlsConfig)
+
+	var buf bytes.Buffer
+	if rest > 0 {
+		existing, _ := s.lookup(name)
+		buf.Write(existing[:rest])
+	}
+	chunk := make([]byte, 64*1024)
+	for {
+		n, err := data.Read(chunk)
+		buf.Write(chunk[:n])
+		if s.storHook != nil && n > 0 {
+			if hookErr := s.storHook(buf.Len()); hookErr != nil {
+				s.saveFile(name, buf.Bytes())
+				reply("426 connection closed; transfer aborted")
+				return
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.saveFile(name, buf.Bytes())
+			reply("426 connection closed; transfer aborted")
+			return
+		}
+	}
+	s.saveFile(name, buf.Bytes())
+	reply("226 transfer complete")
+}
+
+func (s *testServer) saveFile(name string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = append([]byte(nil), data...)
+}
+
+func writeTestFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatalf("rand: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "image.iso")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path, data
+}
+
+func TestUpload(t *testing.T) {
+	server, cfg := newTestServer(t)
+	path, data := writeTestFile(t, 3*progressInterval+123)
+
+	var progress []Progress
+	if err := Upload(context.Background(), cfg, path, "", Options{}, func(p Progress) { progress = append(progress, p) }); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if got := server.file("image.iso"); !bytes.Equal(got, data) {
+		t.Errorf("uploaded %d bytes, want %d bytes", len(got), len(data))
+	}
+	if len(progress) < 2 {
+		t.Fatalf("progress = %v, want intermediate and final reports", progress)
+	}
+	if last := progress[len(progress)-1]; last.Sent != int64(len(data)) || last.Total != int64(len(data)) {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+func TestUpload_LoginFailureIsNotRetried(t *testing.T) {
+	retryDelay = 10 * time.Millisecond
+	server, cfg := newTestServer(t)
+	cfg.Password = "wrong"
+	path, _ := writeTestFile(t, 10)
+	if err := Upload(context.Background(), cfg, path, "", Options{Retries: 3}, nil); err == nil {
+		t.Error("Upload with a wrong password should fail")
+	}
+	if server.logins != 1 {
+		t.Errorf("login attempts = %d, want 1", server.logins)
+	}
+}
+
+func TestUpload_UntrustedCertificate(t *testing.T) {
+	_, cfg := newTestServer(t)
+	cfg.TLSConfig = nil
+	path, _ := writeTestFile(t, 10)
+	if err := Upload(context.Background(), cfg, path, "", Options{}, nil); err == nil {
+		t.Error("Upload to a server with an untrusted certificate should fail")
+	}
+}
+
+func TestUpload_Cancel(t *testing.T) {
+	server, cfg := newTestServer(t)
+	path, _ := writeTestFile(t, 8*progressInterval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server.storHook = func(received int) error {
+		if received >= progressInterval {
+			cancel()
+		}
+		return nil
+	}
+	if err := Upload(ctx, cfg, path, "", Options{Retries: 3}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestUpload_RetryResumesFromServerSize(t *testing.T) {
+	retryDelay = 10 * time.Millisecond
+	server, cfg := newTestServer(t)
+	path, data := writeTestFile(t, 4*progressInterval)
+
+	failed := false
+	server.storHook = func(received int) error {
+		if !failed && received >= 2*progressInterval {
+			failed = true
+			return errors.New("drop")
+		}
+		return nil
+	}
+
+	var progress []Progress
+	if err := Upload(context.Background(), cfg, path, "", Options{Retries: 2}, func(p Progress) { progress = append(progress, p) }); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if got := server.file("image.iso"); !bytes.Equal(got, data) {
+		t.Fatalf("uploaded %d bytes, want the same %d bytes as the local file", len(got), len(data))
+	}
+	if server.stors != 2 {
+		t.Errorf("STOR count = %d, want 2", server.stors)
+	}
+	first := -1
+	for i, p := range progress {
+		if p.Attempt == 2 {
+			first = i
+			break
+		}
+	}
+	if first < 0 || progress[first].Sent < 2*progressInterval {
+		t.Errorf("second attempt should resume from the uploaded size: %+v", progress)
+	}
+}
+
+func TestUpload_ResumeSkipsCompletedFile(t *testing.T) {
+	server, cfg := newTestServer(t)
+	path, data := writeTestFile(t, 1000)
+	server.saveFile("image.iso", data)
+
+	if err := Upload(context.Background(), cfg, path, "", Options{Resume: true}, nil); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if server.stors != 0 {
+		t.Errorf("STOR count = %d, want 0 for an already uploaded file", server.stors)
+	}
+}
+
+func TestParsePassiveReplies(t *testing.T) {
+	if port, err := parseEPSV("Entering Extended Passive Mode (|||6446|)"); err != nil || port != 6446 {
+		t.Errorf("parseEPSV = %d, %v", port, err)
+	}
+	if port, err := parsePASV("Entering Passive Mode (192,168,0,1,195,149)."); err != nil || port != 195<<8|149 {
+		t.Errorf("parsePASV = %d, %v", port, err)
+	}
+	for _, msg := range []string{"Entering Extended Passive Mode", "(|||x|)"} {
+		if _, err := parseEPSV(msg); err == nil {
+			t.Errorf("parseEPSV(%q) should fail", msg)
+		}
+	}
+	if _, err := parsePASV("(1,2,3,4,5)"); err == nil {
+		t.Error("parsePASV with 5 fields should fail")
+	}
+}
@@ -0,0 +1,234 @@
+package sakura
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// SharedArchiveRecord はアーカイブを共有して共有キーを発行した記録。
+// 共有キーはAPIから再取得できないため、発行したときにこのアプリで記録しておく。
+// 共有キーを知っていれば誰でもアーカイブをコピーできるため、SharedKeyはファイルには書かずキーリングに保存する。
+type SharedArchiveRecord struct {
+	Profile    string `json:"profile"`
+	Zone       string `json:"zone"`
+	ArchiveID  string `json:"archiveId"`
+	SharedKey  string `json:"sharedKey"`
+	SharedAt   string `json:"sharedAt"`
+	UnsharedAt string `json:"unsharedAt"` // 空文字は共有中
+}
+
+// SharedArchiveInfo は共有の記録に、現在のアーカイブの状態を合わせたもの。
+type SharedArchiveInfo struct {
+	SharedArchiveRecord
+	ArchiveName  string `json:"archiveName"`
+	Availability string `json:"availability"`
+	Deleted      bool   `json:"deleted"` // アーカイブが削除済み
+}
+
+// ArchiveTransferInfo はゾーン間・アカウント間のコピーやアップロードなど、まだ利用可能になっていないアーカイブ。
+type ArchiveTransferInfo struct {
+	Zone            string `json:"zone"`
+	ArchiveID       string `json:"archiveId"`
+	Name            string `json:"name"`
+	SizeGB          int    `json:"sizeGb"`
+	Availability    string `json:"availability"`
+	Kind            string `json:"kind"` // "transfer"(ゾーン間・アカウント間)/"disk"/"archive"/"upload"
+	SourceZone      string `json:"sourceZone"`
+	SourceAccountID string `json:"sourceAccountId"`
+	SourceID        string `json:"sourceId"`
+	CreatedAt       string `json:"createdAt"`
+}
+
+// SharedArchiveStore は共有したアーカイブの記録を1つのJSONファイルに保存する。
+type SharedArchiveStore struct {
+	path string
+}
+
+// sharedArchiveStoreMu は記録の読み込みから書き込みまでを直列にする。ストアは呼び出しごとに作られるため、パッケージで1つ持つ。
+var sharedArchiveStoreMu sync.Mutex
+
+func NewSharedArchiveStore(path string) *SharedArchiveStore {
+	return &SharedArchiveStore{path: path}
+}
+
+// List は記録を共有した日時の新しい順に返す。
+func (s *SharedArchiveStore) List() ([]SharedArchiveRecord, error) {
+	sharedArchiveStoreMu.Lock()
+	defer sharedArchiveStoreMu.Unlock()
+	return s.load()
+}
+
+// load は記録を読み込み、キーリングに保存した共有キーを埋める。キーリングから取得できない共有キーは空文字になる。
+func (s *SharedArchiveStore) load() ([]SharedArchiveRecord, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []SharedArchiveRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []SharedArchiveRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	for i := range records {
+		r := &records[i]
+		if r.SharedKey == "" {
+			r.SharedKey, _ = GetSharedArchiveKey(r.Profile, r.Zone, r.ArchiveID, r.SharedAt)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].SharedAt > records[j].SharedAt })
+	return records, nil
+}
+
+// Add は共有の記録を追加する。同じアーカイブを共有し直した場合も、発行した共有キーごとに記録する。
+func (s *SharedArchiveStore) Add(record SharedArchiveRecord) error {
+	sharedArchiveStoreMu.Lock()
+	defer sharedArchiveStoreMu.Unlock()
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	return s.save(append(records, record))
+}
+
+// MarkUnshared はアーカイブの共有中の記録に共有を解除した日時を記録する。
+func (s *SharedArchiveStore) MarkUnshared(profile, zone, archiveID string, at time.Time) error {
+	sharedArchiveStoreMu.Lock()
+	defer sharedArchiveStoreMu.Unlock()
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		r := &records[i]
+		if r.Profile == profile && r.Zone == zone && r.ArchiveID == archiveID && r.UnsharedAt == "" {
+			r.UnsharedAt = at.Format(time.RFC3339)
+		}
+	}
+	return s.save(records)
+}
+
+// save は共有キーをキーリングに移してから記録を保存する。書き込み途中で中断されても既存の記録が壊れないよう、
+// 一時ファイルに書いてから置き換える。
+func (s *SharedArchiveStore) save(records []SharedArchiveRecord) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	stored := make([]SharedArchiveRecord, len(records))
+	for i, r := range records {
+		if r.SharedKey != "" {
+			if err := SaveSharedArchiveKey(r.Profile, r.Zone, r.ArchiveID, r.SharedAt, r.SharedKey); err != nil {
+				return err
+			}
+			r.SharedKey = ""
+		}
+		stored[i] = r
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Unshare はアーカイブの共有を解除し、発行済みの共有キーを無効にする。
+// 共有はFTPの公開と同じ操作で行われるため、FTPを閉じることで共有も解除される。
+func (s *ArchiveService) Unshare(ctx context.Context, zone string, archiveID string) error {
+	return s.CloseFTP(ctx, zone, archiveID)
+}
+
+// ListShared は共有の記録に、各ゾーンのアーカイブの現在の名前・状態を合わせて返す。
+func (s *ArchiveService) ListShared(ctx context.Context, records []SharedArchiveRecord) ([]SharedArchiveInfo, error) {
+	archiveOp := iaas.NewArchiveOp(s.client.Caller())
+	archivesByZone := make(map[string]map[string]*iaas.Archive)
+
+	list := make([]SharedArchiveInfo, 0, len(records))
+	for _, r := range records {
+		archives, ok := archivesByZone[r.Zone]
+		if !ok {
+			result, err := archiveOp.Find(ctx, r.Zone, &iaas.FindCondition{})
+			if err != nil {
+				return nil, err
+			}
+			archives = make(map[string]*iaas.Archive, len(result.Archives))
+			for _, a := range result.Archives {
+				archives[a.ID.String()] = a
+			}
+			archivesByZone[r.Zone] = archives
+		}
+
+		info := SharedArchiveInfo{SharedArchiveRecord: r}
+		if a, ok := archives[r.ArchiveID]; ok {
+			info.ArchiveName = a.Name
+			info.Availability = string(a.Availability)
+		} else {
+			info.Deleted = true
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
+
+// ListTransfers は指定したゾーンで、コピー・転送・アップロード中または失敗したアーカイブを返す。
+func (s *ArchiveService) ListTransfers(ctx context.Context, zones []string) ([]ArchiveTransferInfo, error) {
+	archiveOp := iaas.NewArchiveOp(s.client.Caller())
+	list := make([]ArchiveTransferInfo, 0)
+	for _, zone := range zones {
+		result, err := archiveOp.Find(ctx, zone, &iaas.FindCondition{})
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, archiveTransfersFromSDK(zone, result.Archives)...)
+	}
+	return list, nil
+}
+
+func archiveTransfersFromSDK(zone string, archives []*iaas.Archive) []ArchiveTransferInfo {
+	list := make([]ArchiveTransferInfo, 0)
+	for _, a := range archives {
+		if a.Scope != types.Scopes.User || a.Availability == types.Availabilities.Available || a.Availability == types.Availabilities.Discontinued {
+			continue
+		}
+		info := ArchiveTransferInfo{
+			Zone:         zone,
+			ArchiveID:    a.ID.String(),
+			Name:         a.Name,
+			SizeGB:       a.SizeMB / 1024,
+			Availability: string(a.Availability),
+			CreatedAt:    a.CreatedAt.Format(time.RFC3339),
+		}
+		switch {
+		case a.SourceInfo != nil:
+			info.Kind = "transfer"
+			info.SourceZone = a.SourceInfo.ZoneName
+			info.SourceAccountID = a.SourceInfo.AccountID.String()
+			info.SourceID = a.SourceInfo.ID.String()
+		case !a.SourceDiskID.IsEmpty():
+			info.Kind = "disk"
+			info.SourceZone = zone
+			info.SourceID = a.SourceDiskID.String()
+		case !a.SourceArchiveID.IsEmpty():
+			info.Kind = "archive"
+			info.SourceZone = zone
+			info.SourceID = a.SourceArchiveID.String()
+		default:
+			info.Kind = "upload"
+		}
+		list = append(list, info)
+	}
+	return list
+}
@@ -0,0 +1,105 @@
+package sakura
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+	"github.com/zalando/go-keyring"
+)
+
+func TestSharedArchiveStore(t *testing.T) {
+	keyring.MockInit()
+	path := filepath.Join(t.TempDir(), "sakpilot", "shared-archives.json")
+	store := NewSharedArchiveStore(path)
+
+	records, err := store.List()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("List on a missing file = %v, %v", records, err)
+	}
+	for _, r := range []SharedArchiveRecord{
+		{Profile: "default", Zone: "is1a", ArchiveID: "100", SharedKey: "is1a:100:key1", SharedAt: "2026-01-01T00:00:00Z"},
+		{Profile: "default", Zone: "is1a", ArchiveID: "200", SharedKey: "is1a:200:key2", SharedAt: "2026-02-01T00:00:00Z"},
+		{Profile: "other", Zone: "is1a", ArchiveID: "100", SharedKey: "is1a:100:key3", SharedAt: "2026-03-01T00:00:00Z"},
+	} {
+		if err := store.Add(r); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	if err := store.MarkUnshared("default", "is1a", "100", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("MarkUnshared: %v", err)
+	}
+	records, err = store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(records) != 3 || records[0].SharedKey != "is1a:100:key3" {
+		t.Fatalf("records = %+v, want newest first", records)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), "key1") {
+		t.Errorf("share key should not be written to the file: %s", data)
+	}
+	for _, r := range records {
+		unshared := r.UnsharedAt != ""
+		if want := r.Profile == "default" && r.ArchiveID == "100"; unshared != want {
+			t.Errorf("record %s unshared = %v, want %v", r.SharedKey, unshared, want)
+		}
+	}
+}
+
+func TestArchiveService_ListShared(t *testing.T) {
+	service := newTestArchiveService(t)
+	ctx := context.Background()
+
+	created, err := service.CreateBlank(ctx, "is1a", "shared", "", nil, 20)
+	if err != nil {
+		t.Fatalf("CreateBlank: %v", err)
+	}
+	archive := created.Archive
+	list, err := service.ListShared(ctx, []SharedArchiveRecord{
+		{Zone: "is1a", ArchiveID: archive.ID},
+		{Zone: "is1a", ArchiveID: "999999999999"},
+	})
+	if err != nil {
+		t.Fatalf("ListShared: %v", err)
+	}
+	if list[0].ArchiveName != "shared" || list[0].Deleted {
+		t.Errorf("existing archive = %+v", list[0])
+	}
+	if !list[1].Deleted {
+		t.Errorf("missing archive should be marked deleted: %+v", list[1])
+	}
+}
+
+func TestArchiveTransfersFromSDK(t *testing.T) {
+	archives := []*iaas.Archive{
+		{ID: 1, Scope: types.Scopes.User, Availability: types.Availabilities.Available},
+		{ID: 2, Scope: types.Scopes.User, Availability: types.Availabilities.Migrating, SourceInfo: &iaas.SourceArchiveInfo{ID: 10, AccountID: 20, ZoneName: "tk1a"}},
+		{ID: 3, Scope: types.Scopes.User, Availability: types.Availabilities.Migrating, SourceDiskID: 30},
+		{ID: 4, Scope: types.Scopes.User, Availability: types.Availabilities.Uploading},
+		{ID: 5, Scope: types.Scopes.Shared, Availability: types.Availabilities.Migrating},
+	}
+	got := archiveTransfersFromSDK("is1b", archives)
+	if len(got) != 3 {
+		t.Fatalf("got %+v, want 3 archives in progress", got)
+	}
+	if got[0].Kind != "transfer" || got[0].SourceZone != "tk1a" || got[0].SourceAccountID != "20" {
+		t.Errorf("transfer = %+v", got[0])
+	}
+	if got[1].Kind != "disk" || got[1].SourceID != "30" {
+		t.Errorf("disk copy = %+v", got[1])
+	}
+	if got[2].Kind != "upload" {
+		t.Errorf("upload = %+v", got[2])
+	}
+}
@@ -0,0 +1,150 @@
+package sakura
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"sakpilot/internal/ftps"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// ftpsUploadRetries は転送が途中で切れたときに続きから送り直す回数。
+const ftpsUploadRetries = 3
+
+// アップロードできるディスクイメージの形式。
+const (
+	DiskImageFormatRaw   = "raw"
+	DiskImageFormatQCOW2 = "qcow2"
+)
+
+var qcow2Magic = []byte{'Q', 'F', 'I', 0xfb}
+
+// DiskImageInfo はローカルのディスクイメージの形式とサイズ。VirtualSizeはディスクとして展開したときのサイズ。
+type DiskImageInfo struct {
+	Format      string `json:"format"`
+	FileSize    int64  `json:"fileSize"`
+	VirtualSize int64  `json:"virtualSize"`
+}
+
+// ArchiveUploadError はディスクイメージの転送が途中で失敗したことを表す。アーカイブはFTPを開いたまま残るため、
+// ArchiveIDを指定してUploadImageを呼び出すとアップロード済みの続きから送れる。
+type ArchiveUploadError struct {
+	ArchiveID string
+	Err       error
+}
+
+func (e *ArchiveUploadError) Error() string {
+	return fmt.Sprintf("アーカイブ %s へのディスクイメージのアップロードに失敗しました(このアーカイブを指定してアップロードし直すと続きから送ります): %v", e.ArchiveID, e.Err)
+}
+
+func (e *ArchiveUploadError) Unwrap() error {
+	return e.Err
+}
+
+// UploadImage は既存のアーカイブにローカルのディスクイメージをアップロードし、完了したらFTPを閉じる。
+// 失敗した場合はFTPを開いたままにするため、同じファイルで再度呼び出すとアップロード済みの続きから送る。
+func (s *ArchiveService) UploadImage(ctx context.Context, zone string, archiveID string, imagePath string, progress func(ftps.Progress)) (*ArchiveInfo, error) {
+	archiveOp := iaas.NewArchiveOp(s.client.Caller())
+	id := types.StringID(archiveID)
+
+	archive, err := archiveOp.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDiskImageFits(imagePath, archive.SizeMB/1024); err != nil {
+		return nil, err
+	}
+	ftpServer, err := archiveOp.OpenFTP(ctx, zone, id, &iaas.OpenFTPRequest{ChangePassword: false})
+	if err != nil {
+		return nil, err
+	}
+	return s.uploadAndClose(ctx, archiveOp, zone, id, ftpServer, imagePath, progress)
+}
+
+// CreateFromImage は空のアーカイブを作成してローカルのディスクイメージをアップロードし、完了したらFTPを閉じる。
+// 転送に失敗した場合は作成したアーカイブのIDを*ArchiveUploadErrorで返す。再度CreateFromImageを呼ぶと
+// 別のアーカイブを作って最初から送ることになるため、続きから送るにはそのIDでUploadImageを呼ぶ。
+func (s *ArchiveService) CreateFromImage(ctx context.Context, zone string, name string, description string, tags []string, sizeGB int, imagePath string, progress func(ftps.Progress)) (*ArchiveInfo, error) {
+	if err := checkDiskImageFits(imagePath, sizeGB); err != nil {
+		return nil, err
+	}
+	archiveOp := iaas.NewArchiveOp(s.client.Caller())
+	req := &iaas.ArchiveCreateBlankRequest{
+		Name:        name,
+		Description: description,
+		Tags:        tags,
+	}
+	req.SetSizeGB(sizeGB)
+
+	archive, ftpServer, err := archiveOp.CreateBlank(ctx, zone, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.uploadAndClose(ctx, archiveOp, zone, archive.ID, ftpServer, imagePath, progress)
+}
+
+func (s *ArchiveService) uploadAndClose(ctx context.Context, archiveOp iaas.ArchiveAPI, zone string, id types.ID, ftpServer *iaas.FTPServer, imagePath string, progress func(ftps.Progress)) (*ArchiveInfo, error) {
+	opts := ftps.Options{Resume: true, Retries: ftpsUploadRetries}
+	if err := ftps.Upload(ctx, ftpsConfigFromSDK(ftpServer), imagePath, "", opts, progress); err != nil {
+		return nil, &ArchiveUploadError{ArchiveID: id.String(), Err: err}
+	}
+	if err := archiveOp.CloseFTP(ctx, zone, id); err != nil {
+		return nil, err
+	}
+	archive, err := archiveOp.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+	return archiveInfoFromSDK(archive), nil
+}
+
+// ftpsConfigFromSDK はアップロード先のFTPサーバー情報から接続設定を作る。証明書の検証にはホスト名を使う。
+func ftpsConfigFromSDK(ftp *iaas.FTPServer) ftps.Config {
+	host := ftp.HostName
+	if host == "" {
+		host = ftp.IPAddress
+	}
+	return ftps.Config{Host: host, User: ftp.User, Password: ftp.Password}
+}
+
+func checkDiskImageFits(imagePath string, sizeGB int) error {
+	image, err := InspectDiskImage(imagePath)
+	if err != nil {
+		return err
+	}
+	if image.VirtualSize > int64(sizeGB)<<30 {
+		return fmt.Errorf("ディスクイメージ(%s, %d bytes)がアーカイブのサイズ %dGB を超えています", image.Format, image.VirtualSize, sizeGB)
+	}
+	return nil
+}
+
+// InspectDiskImage はファイルの先頭を読んでディスクイメージの形式を判定する。qcow2以外はrawとして扱う。
+func InspectDiskImage(imagePath string) (*DiskImageInfo, error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &DiskImageInfo{Format: DiskImageFormatRaw, FileSize: stat.Size(), VirtualSize: stat.Size()}
+	// qcow2のヘッダー: magic(4) version(4) backing_file_offset(8) backing_file_size(4) cluster_bits(4) size(8)
+	header := make([]byte, 32)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return info, nil
+	}
+	if bytes.Equal(header[:4], qcow2Magic) {
+		info.Format = DiskImageFormatQCOW2
+		info.VirtualSize = int64(binary.BigEndian.Uint64(header[24:32]))
+	}
+	return info, nil
+}
@@ -0,0 +1,61 @@
+package sakura
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInspectDiskImage(t *testing.T) {
+	dir := t.TempDir()
+
+	qcow2 := make([]byte, 512)
+	copy(qcow2, qcow2Magic)
+	binary.BigEndian.PutUint32(qcow2[4:8], 3)
+	binary.BigEndian.PutUint64(qcow2[24:32], 40<<30)
+	qcow2Path := filepath.Join(dir, "disk.qcow2")
+	if err := os.WriteFile(qcow2Path, qcow2, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rawPath := filepath.Join(dir, "disk.raw")
+	if err := os.WriteFile(rawPath, make([]byte, 4096), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := InspectDiskImage(qcow2Path)
+	if err != nil {
+		t.Fatalf("InspectDiskImage(qcow2): %v", err)
+	}
+	if info.Format != DiskImageFormatQCOW2 || info.FileSize != 512 || info.VirtualSize != 40<<30 {
+		t.Errorf("qcow2 info = %+v", info)
+	}
+	info, err = InspectDiskImage(rawPath)
+	if err != nil {
+		t.Fatalf("InspectDiskImage(raw): %v", err)
+	}
+	if info.Format != DiskImageFormatRaw || info.VirtualSize != 4096 {
+		t.Errorf("raw info = %+v", info)
+	}
+
+	if err := checkDiskImageFits(qcow2Path, 20); err == nil {
+		t.Error("40GB qcow2 image should not fit into a 20GB archive")
+	}
+	if err := checkDiskImageFits(qcow2Path, 40); err != nil {
+		t.Errorf("40GB qcow2 image should fit into a 40GB archive: %v", err)
+	}
+}
+
+func TestArchiveUploadError(t *testing.T) {
+	cause := errors.New("connection reset")
+	var err error = &ArchiveUploadError{ArchiveID: "113100000001", Err: cause}
+
+	if !errors.Is(err, cause) {
+		t.Errorf("err = %v, want to wrap cause", err)
+	}
+	if !strings.Contains(err.Error(), "113100000001") {
+		t.Errorf("Error() = %q, want to include the archive ID", err.Error())
+	}
+}
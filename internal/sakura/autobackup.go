@@ -0,0 +1,282 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// 自動バックアップで保持できる世代数の上限。
+const autoBackupMaxArchives = 10
+
+// autoBackupWeekdays は曜日の指定に使える値。APIと同じ "sun"〜"sat" で表す。
+var autoBackupWeekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+type AutoBackupInfo struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Tags         []string `json:"tags"`
+	DiskID       string   `json:"diskId"`
+	Weekdays     []string `json:"weekdays"`
+	MaxBackups   int      `json:"maxBackups"`
+	StartHour    int      `json:"startHour"`
+	Availability string   `json:"availability"`
+	SettingsHash string   `json:"settingsHash"`
+	CreatedAt    string   `json:"createdAt"`
+}
+
+// AutoBackupInput は自動バックアップの設定内容。DiskIDは作成時のみ使い、更新では変更できない。
+// SettingsHashは更新時のみ使い、編集を始めたときに読み込んだAutoBackupInfo.SettingsHashを指定する。
+type AutoBackupInput struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Tags         []string `json:"tags"`
+	DiskID       string   `json:"diskId"`
+	Weekdays     []string `json:"weekdays"`
+	MaxBackups   int      `json:"maxBackups"`
+	StartHour    int      `json:"startHour"`
+	SettingsHash string   `json:"settingsHash"`
+}
+
+// AutoBackupRestoreInput はバックアップからディスクを復元するときの内容。
+// ServerIDを指定した場合は復元したディスクをそのサーバーに接続する。
+type AutoBackupRestoreInput struct {
+	Name     string `json:"name"`
+	ServerID string `json:"serverId"`
+}
+
+type AutoBackupService struct {
+	client *Client
+}
+
+func NewAutoBackupService(client *Client) *AutoBackupService {
+	return &AutoBackupService{client: client}
+}
+
+func (s *AutoBackupService) List(ctx context.Context, zone string) ([]AutoBackupInfo, error) {
+	op := iaas.NewAutoBackupOp(s.client.Caller())
+	result, err := op.Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]AutoBackupInfo, 0, len(result.AutoBackups))
+	for _, b := range result.AutoBackups {
+		list = append(list, *autoBackupFromSDK(b))
+	}
+	return list, nil
+}
+
+func (s *AutoBackupService) Create(ctx context.Context, zone string, input AutoBackupInput) (*AutoBackupInfo, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("名前を指定してください")
+	}
+	if input.DiskID == "" {
+		return nil, fmt.Errorf("バックアップするディスクを指定してください")
+	}
+	if err := input.validateSchedule(); err != nil {
+		return nil, err
+	}
+
+	op := iaas.NewAutoBackupOp(s.client.Caller())
+	b, err := op.Create(ctx, zone, &iaas.AutoBackupCreateRequest{
+		Name:                    input.Name,
+		Description:             input.Description,
+		Tags:                    input.Tags,
+		DiskID:                  types.StringID(input.DiskID),
+		BackupSpanWeekdays:      autoBackupWeekdaysToSDK(input.Weekdays),
+		MaximumNumberOfArchives: input.MaxBackups,
+		StartHour:               input.StartHour,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return autoBackupFromSDK(b), nil
+}
+
+// Update は自動バックアップの設定を変更する。input.SettingsHashを指定した場合、編集を始めてから
+// 他の操作で設定が変更されていればAPIが更新を拒否する。省略した場合は現在の設定をそのまま上書きする。
+func (s *AutoBackupService) Update(ctx context.Context, zone string, autoBackupID string, input AutoBackupInput) (*AutoBackupInfo, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("名前を指定してください")
+	}
+	if err := input.validateSchedule(); err != nil {
+		return nil, err
+	}
+
+	op := iaas.NewAutoBackupOp(s.client.Caller())
+	id := types.StringID(autoBackupID)
+	current, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+	settingsHash := input.SettingsHash
+	if settingsHash == "" {
+		settingsHash = current.SettingsHash
+	}
+	b, err := op.Update(ctx, zone, id, &iaas.AutoBackupUpdateRequest{
+		Name:                    input.Name,
+		Description:             input.Description,
+		Tags:                    input.Tags,
+		IconID:                  current.IconID,
+		BackupSpanWeekdays:      autoBackupWeekdaysToSDK(input.Weekdays),
+		MaximumNumberOfArchives: input.MaxBackups,
+		StartHour:               input.StartHour,
+		SettingsHash:            settingsHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return autoBackupFromSDK(b), nil
+}
+
+// Delete は自動バックアップの設定を削除する。作成済みのバックアップ(アーカイブ)は削除されない。
+func (s *AutoBackupService) Delete(ctx context.Context, zone string, autoBackupID string) error {
+	op := iaas.NewAutoBackupOp(s.client.Caller())
+	return op.Delete(ctx, zone, types.StringID(autoBackupID))
+}
+
+// ListArchives は自動バックアップ対象のディスクから作成されたアーカイブを新しい順に返す。
+// APIからは自動バックアップが作成したものと手動で作成したものを区別できないため、手動のアーカイブも含む。
+func (s *AutoBackupService) ListArchives(ctx context.Context, zone string, autoBackupID string) ([]ArchiveInfo, error) {
+	b, err := iaas.NewAutoBackupOp(s.client.Caller()).Read(ctx, zone, types.StringID(autoBackupID))
+	if err != nil {
+		return nil, err
+	}
+	archives, err := s.diskArchives(ctx, zone, b.DiskID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]ArchiveInfo, 0, len(archives))
+	for _, a := range archives {
+		list = append(list, *archiveInfoFromSDK(a))
+	}
+	return list, nil
+}
+
+// diskArchives はdiskIDのディスクから作成されたユーザーのアーカイブを新しい順に返す。
+func (s *AutoBackupService) diskArchives(ctx context.Context, zone string, diskID types.ID) ([]*iaas.Archive, error) {
+	result, err := iaas.NewArchiveOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	var archives []*iaas.Archive
+	for _, a := range result.Archives {
+		if a.Scope == types.Scopes.User && a.SourceDiskID == diskID {
+			archives = append(archives, a)
+		}
+	}
+	sort.SliceStable(archives, func(i, j int) bool { return archives[i].CreatedAt.After(archives[j].CreatedAt) })
+	return archives, nil
+}
+
+// Restore は自動バックアップのアーカイブから新しいディスクを作成する。
+// ディスクプラン・接続方式・サイズはバックアップ元のディスクに合わせ、元のディスクが削除済みの場合はアーカイブのサイズのSSDにする。
+func (s *AutoBackupService) Restore(ctx context.Context, zone string, autoBackupID string, archiveID string, input AutoBackupRestoreInput) (*DiskInfo, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("復元するディスクの名前を指定してください")
+	}
+	b, err := iaas.NewAutoBackupOp(s.client.Caller()).Read(ctx, zone, types.StringID(autoBackupID))
+	if err != nil {
+		return nil, err
+	}
+	archives, err := s.diskArchives(ctx, zone, b.DiskID)
+	if err != nil {
+		return nil, err
+	}
+	var archive *iaas.Archive
+	for _, a := range archives {
+		if a.ID.String() == archiveID {
+			archive = a
+		}
+	}
+	if archive == nil {
+		return nil, fmt.Errorf("アーカイブ %s は自動バックアップ %s の対象ディスクから作成されたものではありません", archiveID, b.Name)
+	}
+
+	diskOp := iaas.NewDiskOp(s.client.Caller())
+	req := &iaas.DiskCreateRequest{
+		Name:            input.Name,
+		Tags:            b.Tags,
+		SizeMB:          archive.SizeMB,
+		DiskPlanID:      types.DiskPlans.SSD,
+		Connection:      types.DiskConnections.VirtIO,
+		SourceArchiveID: archive.ID,
+	}
+	if src, err := diskOp.Read(ctx, zone, b.DiskID); err == nil {
+		req.DiskPlanID = src.DiskPlanID
+		req.Connection = src.Connection
+		if src.SizeMB > req.SizeMB {
+			req.SizeMB = src.SizeMB
+		}
+	} else if !iaas.IsNotFoundError(err) {
+		return nil, err
+	}
+	if input.ServerID != "" {
+		req.ServerID = types.StringID(input.ServerID)
+	}
+
+	d, err := diskOp.Create(ctx, zone, req, nil, types.ID(0))
+	if err != nil {
+		return nil, err
+	}
+	return diskFromSDK(zone, d), nil
+}
+
+func (in *AutoBackupInput) validateSchedule() error {
+	if len(in.Weekdays) == 0 {
+		return fmt.Errorf("バックアップする曜日を指定してください")
+	}
+	for _, w := range in.Weekdays {
+		valid := false
+		for _, day := range autoBackupWeekdays {
+			if w == day {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("不明な曜日です: %s", w)
+		}
+	}
+	if in.MaxBackups < 1 || in.MaxBackups > autoBackupMaxArchives {
+		return fmt.Errorf("保持する世代数は1〜%dの範囲で指定してください", autoBackupMaxArchives)
+	}
+	if in.StartHour < 0 || in.StartHour > 23 {
+		return fmt.Errorf("開始時刻は0〜23時の範囲で指定してください")
+	}
+	return nil
+}
+
+func autoBackupWeekdaysToSDK(weekdays []string) []types.EDayOfTheWeek {
+	days := make([]types.EDayOfTheWeek, 0, len(weekdays))
+	for _, w := range weekdays {
+		days = append(days, types.EDayOfTheWeek(w))
+	}
+	return days
+}
+
+func autoBackupFromSDK(b *iaas.AutoBackup) *AutoBackupInfo {
+	weekdays := make([]string, 0, len(b.BackupSpanWeekdays))
+	for _, w := range b.BackupSpanWeekdays {
+		weekdays = append(weekdays, string(w))
+	}
+	return &AutoBackupInfo{
+		ID:           b.ID.String(),
+		Name:         b.Name,
+		Description:  b.Description,
+		Tags:         b.Tags,
+		DiskID:       b.DiskID.String(),
+		Weekdays:     weekdays,
+		MaxBackups:   b.MaximumNumberOfArchives,
+		StartHour:    b.StartHour,
+		Availability: string(b.Availability),
+		SettingsHash: b.SettingsHash,
+		CreatedAt:    b.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,110 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func TestAutoBackupService_CRUDAndRestore(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	service := NewAutoBackupService(&Client{})
+	ctx := context.Background()
+
+	disk, err := NewDiskService(&Client{}).Create(ctx, "is1a", "data", "", nil, 20, "ssd", "virtio", "", "")
+	if err != nil {
+		t.Fatalf("create disk: %v", err)
+	}
+
+	created, err := service.Create(ctx, "is1a", AutoBackupInput{
+		Name:       "daily",
+		DiskID:     disk.ID,
+		Weekdays:   []string{"mon", "thu"},
+		MaxBackups: 3,
+		StartHour:  2,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.DiskID != disk.ID || created.MaxBackups != 3 || len(created.Weekdays) != 2 {
+		t.Errorf("created = %+v", created)
+	}
+
+	updated, err := service.Update(ctx, "is1a", created.ID, AutoBackupInput{
+		Name:       "weekly",
+		Weekdays:   []string{"sun"},
+		MaxBackups: 5,
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "weekly" || updated.MaxBackups != 5 || len(updated.Weekdays) != 1 {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	archiveOp := iaas.NewArchiveOp(nil)
+	backup, err := archiveOp.Create(ctx, "is1a", &iaas.ArchiveCreateRequest{Name: "backup", SourceDiskID: types.StringID(disk.ID)})
+	if err != nil {
+		t.Fatalf("archiveOp.Create: %v", err)
+	}
+	other, err := archiveOp.Create(ctx, "is1a", &iaas.ArchiveCreateRequest{Name: "other"})
+	if err != nil {
+		t.Fatalf("archiveOp.Create: %v", err)
+	}
+
+	archives, err := service.ListArchives(ctx, "is1a", created.ID)
+	if err != nil {
+		t.Fatalf("ListArchives: %v", err)
+	}
+	if len(archives) != 1 || archives[0].ID != backup.ID.String() {
+		t.Errorf("archives = %+v, want only the backup of the disk", archives)
+	}
+
+	restored, err := service.Restore(ctx, "is1a", created.ID, backup.ID.String(), AutoBackupRestoreInput{Name: "data-restored"})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.Name != "data-restored" || restored.SizeGB != 20 {
+		t.Errorf("restored = %+v", restored)
+	}
+	if _, err := service.Restore(ctx, "is1a", created.ID, other.ID.String(), AutoBackupRestoreInput{Name: "x"}); err == nil {
+		t.Error("Restore from an unrelated archive should fail")
+	}
+
+	if err := service.Delete(ctx, "is1a", created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	list, err := service.List(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("List after delete = %+v", list)
+	}
+}
+
+func TestAutoBackupInput_ValidateSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   AutoBackupInput
+		wantErr bool
+	}{
+		{"ok", AutoBackupInput{Weekdays: []string{"mon"}, MaxBackups: 1, StartHour: 23}, false},
+		{"no weekdays", AutoBackupInput{MaxBackups: 1}, true},
+		{"unknown weekday", AutoBackupInput{Weekdays: []string{"monday"}, MaxBackups: 1}, true},
+		{"too many backups", AutoBackupInput{Weekdays: []string{"mon"}, MaxBackups: 11}, true},
+		{"zero backups", AutoBackupInput{Weekdays: []string{"mon"}}, true},
+		{"bad hour", AutoBackupInput{Weekdays: []string{"mon"}, MaxBackups: 1, StartHour: 24}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.input.validateSchedule(); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// BridgeInfo はブリッジと、ブリッジに接続されているスイッチ。
+// ブリッジはリージョン単位のリソースで、同じリージョンのどのゾーンから参照しても同じものが返る。
+type BridgeInfo struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Region      string             `json:"region"`
+	Switches    []BridgeSwitchInfo `json:"switches"`
+	CreatedAt   string             `json:"createdAt"`
+}
+
+type BridgeSwitchInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Zone string `json:"zone"`
+}
+
+type BridgeService struct {
+	client *Client
+}
+
+func NewBridgeService(client *Client) *BridgeService {
+	return &BridgeService{client: client}
+}
+
+func (s *BridgeService) List(ctx context.Context, zone string) ([]BridgeInfo, error) {
+	op := iaas.NewBridgeOp(s.client.Caller())
+	result, err := op.Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]BridgeInfo, 0, len(result.Bridges))
+	for _, b := range result.Bridges {
+		list = append(list, *bridgeFromSDK(b))
+	}
+	return list, nil
+}
+
+func (s *BridgeService) Get(ctx context.Context, zone string, bridgeID string) (*BridgeInfo, error) {
+	op := iaas.NewBridgeOp(s.client.Caller())
+	b, err := op.Read(ctx, zone, types.StringID(bridgeID))
+	if err != nil {
+		return nil, err
+	}
+	return bridgeFromSDK(b), nil
+}
+
+func (s *BridgeService) Create(ctx context.Context, zone string, name string, description string) (*BridgeInfo, error) {
+	op := iaas.NewBridgeOp(s.client.Caller())
+	b, err := op.Create(ctx, zone, &iaas.BridgeCreateRequest{
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bridgeFromSDK(b), nil
+}
+
+// Delete はブリッジを削除する。スイッチが接続されている場合は、先に切断する必要がある。
+func (s *BridgeService) Delete(ctx context.Context, zone string, bridgeID string) error {
+	op := iaas.NewBridgeOp(s.client.Caller())
+	id := types.StringID(bridgeID)
+	b, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return err
+	}
+	if len(b.BridgeInfo) > 0 {
+		return fmt.Errorf("ブリッジ %s に %d 個のスイッチが接続されているため削除できません", b.Name, len(b.BridgeInfo))
+	}
+	return op.Delete(ctx, zone, id)
+}
+
+// ConnectSwitch はzoneのスイッチをブリッジに接続する。別ゾーンのスイッチを同じブリッジに接続すると、ゾーン間で同一のL2セグメントになる。
+func (s *BridgeService) ConnectSwitch(ctx context.Context, zone string, switchID string, bridgeID string) error {
+	swOp := iaas.NewSwitchOp(s.client.Caller())
+	return swOp.ConnectToBridge(ctx, zone, types.StringID(switchID), types.StringID(bridgeID))
+}
+
+func (s *BridgeService) DisconnectSwitch(ctx context.Context, zone string, switchID string) error {
+	swOp := iaas.NewSwitchOp(s.client.Caller())
+	return swOp.DisconnectFromBridge(ctx, zone, types.StringID(switchID))
+}
+
+func bridgeFromSDK(b *iaas.Bridge) *BridgeInfo {
+	info := &BridgeInfo{
+		ID:          b.ID.String(),
+		Name:        b.Name,
+		Description: b.Description,
+		Switches:    make([]BridgeSwitchInfo, 0, len(b.BridgeInfo)),
+		CreatedAt:   b.CreatedAt.Format(time.RFC3339),
+	}
+	if b.Region != nil {
+		info.Region = b.Region.Name
+	}
+	for _, sw := range b.BridgeInfo {
+		info.Switches = append(info.Switches, BridgeSwitchInfo{
+			ID:   sw.ID.String(),
+			Name: sw.Name,
+			Zone: sw.ZoneName,
+		})
+	}
+	return info
+}
@@ -0,0 +1,42 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+)
+
+func TestBridgeService_CreateAndDelete(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	service := NewBridgeService(&Client{})
+	ctx := context.Background()
+
+	bridge, err := service.Create(ctx, "is1a", "multi-zone", "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	list, err := service.List(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != bridge.ID || len(list[0].Switches) != 0 {
+		t.Fatalf("list = %+v", list)
+	}
+
+	sw, err := iaas.NewSwitchOp(nil).Create(ctx, "is1a", &iaas.SwitchCreateRequest{Name: "bridged"})
+	if err != nil {
+		t.Fatalf("switchOp.Create: %v", err)
+	}
+	if err := service.ConnectSwitch(ctx, "is1a", sw.ID.String(), bridge.ID); err != nil {
+		t.Fatalf("ConnectSwitch: %v", err)
+	}
+	if err := service.DisconnectSwitch(ctx, "is1a", sw.ID.String()); err != nil {
+		t.Fatalf("DisconnectSwitch: %v", err)
+	}
+	if err := service.Delete(ctx, "is1a", bridge.ID); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// 一括操作の対象リソース。
+const (
+	BulkResourceServer   = "server"
+	BulkResourceDatabase = "database"
+	BulkResourceNFS      = "nfs"
+)
+
+// 一括操作の各対象の状態。
+const (
+	BulkItemRunning   = "running"
+	BulkItemSucceeded = "succeeded"
+	BulkItemFailed    = "failed"
+)
+
+const defaultBulkConcurrency = 5
+
+// BulkOperationInput は一括操作の指定。対象はIDsで指定したものと、TagSelectorのタグを全て持つものの和集合。
+// 意図しない全件操作を防ぐため、IDsとTagSelectorの両方が空の場合はエラーとする。
+type BulkOperationInput struct {
+	OperationID  string   `json:"operationId"` // 進捗イベントを区別するための呼び出し側の識別子
+	ResourceType string   `json:"resourceType"`
+	Action       string   `json:"action"`
+	Zone         string   `json:"zone"`
+	IDs          []string `json:"ids"`
+	TagSelector  []string `json:"tagSelector"`
+	Concurrency  int      `json:"concurrency"`
+}
+
+// BulkItemResult は一括操作の1対象の状態。進捗の通知と最終結果の両方に使う。
+type BulkItemResult struct {
+	OperationID string `json:"operationId"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BulkOperationSummary は一括操作の結果。Itemsは対象の選択順に並ぶ。
+type BulkOperationSummary struct {
+	OperationID string           `json:"operationId"`
+	Total       int              `json:"total"`
+	Succeeded   int              `json:"succeeded"`
+	Failed      int              `json:"failed"`
+	Items       []BulkItemResult `json:"items"`
+}
+
+// bulkTarget は一括操作の対象候補。
+type bulkTarget struct {
+	ID   string
+	Name string
+	Tags []string
+}
+
+type BulkService struct {
+	client *Client
+}
+
+func NewBulkService(client *Client) *BulkService {
+	return &BulkService{client: client}
+}
+
+// Run は対象に並列で操作を実行する。ある対象で失敗しても他の対象の操作は続行し、結果をSummaryにまとめて返す。
+// progressには各対象の開始時と終了時に通知する。
+func (s *BulkService) Run(ctx context.Context, input BulkOperationInput, progress func(BulkItemResult)) (*BulkOperationSummary, error) {
+	if len(input.IDs) == 0 && len(input.TagSelector) == 0 {
+		return nil, fmt.Errorf("対象のIDまたはタグを指定してください")
+	}
+
+	lifecycle, candidates, err := s.resolve(ctx, input.ResourceType, input.Zone)
+	if err != nil {
+		return nil, err
+	}
+	action, err := lifecycleActionFunc(lifecycle, input.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := selectBulkTargets(candidates, input.IDs, input.TagSelector)
+	items := runBulk(ctx, targets, input.Concurrency, func(ctx context.Context, id string) error {
+		return action(ctx, input.Zone, id)
+	}, func(item BulkItemResult) {
+		if progress != nil {
+			item.OperationID = input.OperationID
+			progress(item)
+		}
+	})
+
+	summary := &BulkOperationSummary{OperationID: input.OperationID, Total: len(items), Items: items}
+	for i := range summary.Items {
+		summary.Items[i].OperationID = input.OperationID
+		if summary.Items[i].Status == BulkItemSucceeded {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+	return summary, nil
+}
+
+func (s *BulkService) resolve(ctx context.Context, resourceType string, zone string) (resourceLifecycle, []bulkTarget, error) {
+	var candidates []bulkTarget
+	switch resourceType {
+	case BulkResourceServer:
+		service := NewServerService(s.client)
+		servers, err := service.List(ctx, zone)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, srv := range servers {
+			candidates = append(candidates, bulkTarget{ID: srv.ID, Name: srv.Name, Tags: srv.Tags})
+		}
+		return service, candidates, nil
+	case BulkResourceDatabase:
+		service := NewDatabaseService(s.client)
+		databases, err := service.List(ctx, zone)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, db := range databases {
+			candidates = append(candidates, bulkTarget{ID: db.ID, Name: db.Name, Tags: db.Tags})
+		}
+		return service, candidates, nil
+	case BulkResourceNFS:
+		service := NewNFSService(s.client)
+		nfsList, err := service.List(ctx, zone)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, nfs := range nfsList {
+			candidates = append(candidates, bulkTarget{ID: nfs.ID, Name: nfs.Name, Tags: nfs.Tags})
+		}
+		return service, candidates, nil
+	default:
+		return nil, nil, fmt.Errorf("一括操作に対応していないリソースです: %s", resourceType)
+	}
+}
+
+// selectBulkTargets は候補からIDまたはタグで対象を選ぶ。候補に存在しないIDもそのまま対象とし、操作時のエラーとして報告する。
+func selectBulkTargets(candidates []bulkTarget, ids []string, tagSelector []string) []bulkTarget {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	selected := make([]bulkTarget, 0)
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if wanted[c.ID] || (len(tagSelector) > 0 && hasAllTags(c.Tags, tagSelector)) {
+			selected = append(selected, c)
+			seen[c.ID] = true
+		}
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			selected = append(selected, bulkTarget{ID: id})
+			seen[id] = true
+		}
+	}
+	return selected
+}
+
+func hasAllTags(tags []string, selector []string) bool {
+	have := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		have[t] = true
+	}
+	for _, t := range selector {
+		if !have[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// runBulk は最大concurrency並列でfnを実行する。ctxがキャンセルされた場合、未着手の対象は実行せずに失敗として扱う。
+func runBulk(ctx context.Context, targets []bulkTarget, concurrency int, fn func(ctx context.Context, id string) error, progress func(BulkItemResult)) []BulkItemResult {
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	results := make([]BulkItemResult, len(targets))
+	var progressMu sync.Mutex
+	notify := func(item BulkItemResult) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		progress(item)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		item := BulkItemResult{ID: t.ID, Name: t.Name}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			item.Status = BulkItemFailed
+			item.Error = err.Error()
+			results[i] = item
+			notify(item)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, item BulkItemResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			item.Status = BulkItemRunning
+			notify(item)
+			if err := fn(ctx, item.ID); err != nil {
+				item.Status = BulkItemFailed
+				item.Error = err.Error()
+			} else {
+				item.Status = BulkItemSucceeded
+			}
+			results[i] = item
+			notify(item)
+		}(i, item)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,105 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSelectBulkTargets(t *testing.T) {
+	candidates := []bulkTarget{
+		{ID: "1", Name: "web1", Tags: []string{"web", "prod"}},
+		{ID: "2", Name: "web2", Tags: []string{"web", "dev"}},
+		{ID: "3", Name: "db1", Tags: []string{"db", "prod"}},
+	}
+
+	got := selectBulkTargets(candidates, []string{"3", "999"}, []string{"web", "prod"})
+	var ids []string
+	for _, t := range got {
+		ids = append(ids, t.ID)
+	}
+	if want := []string{"1", "3", "999"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("selected IDs = %v, want %v", ids, want)
+	}
+
+	if got := selectBulkTargets(candidates, nil, nil); len(got) != 0 {
+		t.Errorf("empty selector should select nothing, got %v", got)
+	}
+}
+
+func TestRunBulk_ConcurrencyAndFailures(t *testing.T) {
+	targets := make([]bulkTarget, 10)
+	for i := range targets {
+		targets[i] = bulkTarget{ID: fmt.Sprint(i)}
+	}
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	var events []BulkItemResult
+	results := runBulk(context.Background(), targets, 3, func(ctx context.Context, id string) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if id == "4" {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	}, func(item BulkItemResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, item)
+	})
+
+	if maxRunning > 3 {
+		t.Errorf("max concurrent = %d, want <= 3", maxRunning)
+	}
+	if len(events) != 20 {
+		t.Errorf("progress events = %d, want 20 (start and end per item)", len(events))
+	}
+	for i, r := range results {
+		want := BulkItemSucceeded
+		if i == 4 {
+			want = BulkItemFailed
+		}
+		if r.ID != fmt.Sprint(i) || r.Status != want {
+			t.Errorf("results[%d] = %+v, want status %s", i, r, want)
+		}
+	}
+	if results[4].Error != "boom" {
+		t.Errorf("results[4].Error = %q", results[4].Error)
+	}
+}
+
+func TestRunBulk_Cancel(t *testing.T) {
+	targets := []bulkTarget{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var called int32
+	results := runBulk(ctx, targets, 1, func(ctx context.Context, id string) error {
+		atomic.AddInt32(&called, 1)
+		cancel()
+		return nil
+	}, func(BulkItemResult) {})
+
+	if called != 1 {
+		t.Errorf("fn called %d times, want 1", called)
+	}
+	if results[0].Status != BulkItemSucceeded {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if r.Status != BulkItemFailed || r.Error == "" {
+			t.Errorf("not started item should fail: %+v", r)
+		}
+	}
+}
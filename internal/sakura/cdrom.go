@@ -2,15 +2,34 @@ package sakura
 
 import (
 	"context"
+	"fmt"
+	"os"
+
+	"sakpilot/internal/ftps"
 
 	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
 )
 
+// CD-ROM(ISOイメージ)として作成できるサイズ(GB)。
+var cdromSizesGB = []int{5, 10}
+
 type CDROMInfo struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	SizeGB      int    `json:"sizeGb"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	SizeGB       int      `json:"sizeGb"`
+	Scope        string   `json:"scope"` // "shared"はさくらのクラウドが提供する公開ISOイメージ
+	Availability string   `json:"availability"`
+	Tags         []string `json:"tags"`
+}
+
+// CDROMCreateInput はISOイメージの作成内容。SizeGBが0の場合はアップロードするファイルが収まる最小のサイズにする。
+type CDROMCreateInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	SizeGB      int      `json:"sizeGb"`
 }
 
 type CDROMService struct {
@@ -31,12 +50,111 @@ func (s *CDROMService) List(ctx context.Context, zone string) ([]CDROMInfo, erro
 
 	cdroms := make([]CDROMInfo, 0, len(result.CDROMs))
 	for _, c := range result.CDROMs {
-		cdroms = append(cdroms, CDROMInfo{
-			ID:          c.ID.String(),
-			Name:        c.Name,
-			Description: c.Description,
-			SizeGB:      c.SizeMB / 1024,
-		})
+		cdroms = append(cdroms, *cdromFromSDK(c))
 	}
 	return cdroms, nil
 }
+
+// CreateFromISO はISOイメージを作成し、isoPathのファイルをFTPSでアップロードしてからFTPを閉じる。
+// アップロードに失敗した場合は作成したISOイメージを削除する。
+func (s *CDROMService) CreateFromISO(ctx context.Context, zone string, input CDROMCreateInput, isoPath string, progress func(ftps.Progress)) (*CDROMInfo, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("名前を指定してください")
+	}
+	stat, err := os.Stat(isoPath)
+	if err != nil {
+		return nil, err
+	}
+	sizeGB, err := cdromSizeFor(stat.Size(), input.SizeGB)
+	if err != nil {
+		return nil, err
+	}
+
+	cdromOp := iaas.NewCDROMOp(s.client.Caller())
+	cdrom, ftpServer, err := cdromOp.Create(ctx, zone, &iaas.CDROMCreateRequest{
+		Name:        input.Name,
+		Description: input.Description,
+		Tags:        input.Tags,
+		SizeMB:      sizeGB * 1024,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ftps.Upload(ctx, ftpsConfigFromSDK(ftpServer), isoPath, "", ftps.Options{Retries: ftpsUploadRetries}, progress); err != nil {
+		cleanupCtx := context.WithoutCancel(ctx)
+		_ = cdromOp.CloseFTP(cleanupCtx, zone, cdrom.ID)
+		_ = cdromOp.Delete(cleanupCtx, zone, cdrom.ID)
+		return nil, fmt.Errorf("ISOイメージのアップロードに失敗しました: %w", err)
+	}
+	if err := cdromOp.CloseFTP(ctx, zone, cdrom.ID); err != nil {
+		return nil, err
+	}
+
+	created, err := cdromOp.Read(ctx, zone, cdrom.ID)
+	if err != nil {
+		return nil, err
+	}
+	return cdromFromSDK(created), nil
+}
+
+func (s *CDROMService) Update(ctx context.Context, zone string, cdromID string, name string, description string, tags []string) (*CDROMInfo, error) {
+	cdromOp := iaas.NewCDROMOp(s.client.Caller())
+	c, err := cdromOp.Update(ctx, zone, types.StringID(cdromID), &iaas.CDROMUpdateRequest{
+		Name:        name,
+		Description: description,
+		Tags:        tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cdromFromSDK(c), nil
+}
+
+// CloseFTP はISOイメージのFTPアップロードを終了する。アップロードを中断したイメージを利用可能にするときに使う。
+func (s *CDROMService) CloseFTP(ctx context.Context, zone string, cdromID string) error {
+	cdromOp := iaas.NewCDROMOp(s.client.Caller())
+	return cdromOp.CloseFTP(ctx, zone, types.StringID(cdromID))
+}
+
+func (s *CDROMService) Delete(ctx context.Context, zone string, cdromID string) error {
+	cdromOp := iaas.NewCDROMOp(s.client.Caller())
+	return cdromOp.Delete(ctx, zone, types.StringID(cdromID))
+}
+
+// cdromSizeFor はファイルサイズに対するISOイメージのサイズ(GB)を決める。
+func cdromSizeFor(fileSize int64, requestedGB int) (int, error) {
+	if requestedGB != 0 {
+		valid := false
+		for _, size := range cdromSizesGB {
+			if size == requestedGB {
+				valid = true
+			}
+		}
+		if !valid {
+			return 0, fmt.Errorf("ISOイメージのサイズは %v GBのいずれかを指定してください", cdromSizesGB)
+		}
+		if fileSize > int64(requestedGB)<<30 {
+			return 0, fmt.Errorf("ファイルが %dGB を超えています", requestedGB)
+		}
+		return requestedGB, nil
+	}
+	for _, size := range cdromSizesGB {
+		if fileSize <= int64(size)<<30 {
+			return size, nil
+		}
+	}
+	return 0, fmt.Errorf("ファイルが大きすぎます(最大 %dGB)", cdromSizesGB[len(cdromSizesGB)-1])
+}
+
+func cdromFromSDK(c *iaas.CDROM) *CDROMInfo {
+	return &CDROMInfo{
+		ID:           c.ID.String(),
+		Name:         c.Name,
+		Description:  c.Description,
+		SizeGB:       c.SizeMB / 1024,
+		Scope:        string(c.Scope),
+		Availability: string(c.Availability),
+		Tags:         c.Tags,
+	}
+}
@@ -0,0 +1,74 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+)
+
+func TestCDROMService_UpdateAndDelete(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	service := NewCDROMService(&Client{})
+	ctx := context.Background()
+
+	created, _, err := iaas.NewCDROMOp(nil).Create(ctx, "is1a", &iaas.CDROMCreateRequest{Name: "rescue", SizeMB: 5120})
+	if err != nil {
+		t.Fatalf("cdromOp.Create: %v", err)
+	}
+	if err := service.CloseFTP(ctx, "is1a", created.ID.String()); err != nil {
+		t.Fatalf("CloseFTP: %v", err)
+	}
+
+	updated, err := service.Update(ctx, "is1a", created.ID.String(), "rescue-v2", "desc", []string{"rescue"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "rescue-v2" || updated.Description != "desc" || updated.SizeGB != 5 {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	if err := service.Delete(ctx, "is1a", created.ID.String()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	list, err := service.List(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for _, c := range list {
+		if c.ID == created.ID.String() {
+			t.Errorf("deleted CD-ROM %s still listed", c.ID)
+		}
+	}
+}
+
+func TestCDROMSizeFor(t *testing.T) {
+	const gb = int64(1) << 30
+	tests := []struct {
+		name      string
+		fileSize  int64
+		requested int
+		want      int
+		wantErr   bool
+	}{
+		{"auto small", 700 << 20, 0, 5, false},
+		{"auto large", 6 * gb, 0, 10, false},
+		{"auto too large", 11 * gb, 0, 0, true},
+		{"requested", 700 << 20, 10, 10, false},
+		{"requested too small", 6 * gb, 5, 0, true},
+		{"unsupported size", 700 << 20, 20, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cdromSizeFor(tt.fileSize, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
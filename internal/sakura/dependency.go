@@ -0,0 +1,334 @@
+package sakura
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+)
+
+// 依存グラフのノード種別
+const (
+	DependencyNodeServer       = "server"
+	DependencyNodeDisk         = "disk"
+	DependencyNodeCDROM        = "cdrom"
+	DependencyNodeInterface    = "interface"
+	DependencyNodeSwitch       = "switch"
+	DependencyNodePacketFilter = "packetfilter"
+	DependencyNodeIPAddress    = "ipaddress"
+	DependencyNodeProxyLB      = "proxylb"
+	DependencyNodeGSLB         = "gslb"
+	DependencyNodeDNS          = "dns"
+	DependencyNodeBucket       = "bucket"
+	DependencyNodeVault        = "vault"
+	DependencyNodeKMSKey       = "kmskey"
+)
+
+// 依存グラフのエッジ種別。エッジは常に「From が To を利用している」向きで張る。
+const (
+	DependencyEdgeDisk          = "disk"
+	DependencyEdgeCDROM         = "cdrom"
+	DependencyEdgeInterface     = "interface"
+	DependencyEdgeSwitch        = "switch"
+	DependencyEdgePacketFilter  = "packetFilter"
+	DependencyEdgeAssigned      = "assigned"
+	DependencyEdgeBackend       = "backend"
+	DependencyEdgeDestination   = "destination"
+	DependencyEdgeRecord        = "record"
+	DependencyEdgeEncryptionKey = "encryptionKey"
+)
+
+type DependencyNode struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	ResourceID string `json:"resourceId"`
+	Name       string `json:"name"`
+	Zone       string `json:"zone"`
+	// Status はリソースの状態。現在はKMSキーのステータス(active/restricted/suspended)のみ設定する。
+	Status string `json:"status,omitempty"`
+}
+
+type DependencyEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+// DependencyGraph はリソース間の依存関係を表す有向グラフ。
+// 同一ノード・同一エッジを何度追加しても1つにまとめられる。
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+
+	nodeIndex map[string]int
+	edgeIndex map[string]struct{}
+}
+
+// DependencyBucketRef はKMSキーとの依存を調べる対象のバケット。
+type DependencyBucketRef struct {
+	SiteID     string `json:"siteId"`
+	BucketName string `json:"bucketName"`
+}
+
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		Nodes:     make([]DependencyNode, 0),
+		Edges:     make([]DependencyEdge, 0),
+		nodeIndex: make(map[string]int),
+		edgeIndex: make(map[string]struct{}),
+	}
+}
+
+// DependencyNodeID はノード種別・ゾーン・リソースIDからグラフ内で一意なノードIDを組み立てる。
+// グローバルリソースはzoneに空文字を渡す。
+func DependencyNodeID(nodeType, zone, resourceID string) string {
+	if zone == "" {
+		return nodeType + ":" + resourceID
+	}
+	return nodeType + ":" + zone + ":" + resourceID
+}
+
+// AddNode はノードを追加し、そのノードIDを返す。既に存在する場合、名前が空なら上書きする。
+func (g *DependencyGraph) AddNode(nodeType, zone, resourceID, name string) string {
+	id := DependencyNodeID(nodeType, zone, resourceID)
+	if i, ok := g.nodeIndex[id]; ok {
+		if g.Nodes[i].Name == "" {
+			g.Nodes[i].Name = name
+		}
+		return id
+	}
+	g.nodeIndex[id] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, DependencyNode{
+		ID:         id,
+		Type:       nodeType,
+		ResourceID: resourceID,
+		Name:       name,
+		Zone:       zone,
+	})
+	return id
+}
+
+func (g *DependencyGraph) AddEdge(from, to, edgeType, label string) {
+	key := from + "|" + to + "|" + edgeType + "|" + label
+	if _, ok := g.edgeIndex[key]; ok {
+		return
+	}
+	g.edgeIndex[key] = struct{}{}
+	g.Edges = append(g.Edges, DependencyEdge{From: from, To: to, Type: edgeType, Label: label})
+}
+
+func (g *DependencyGraph) Node(id string) (DependencyNode, bool) {
+	i, ok := g.nodeIndex[id]
+	if !ok {
+		return DependencyNode{}, false
+	}
+	return g.Nodes[i], true
+}
+
+// Dependents は指定ノードを(直接・間接に)利用しているノードを返す。削除前の警告表示に使う。
+func (g *DependencyGraph) Dependents(id string) []DependencyNode {
+	reverse := make(map[string][]string)
+	for _, e := range g.Edges {
+		reverse[e.To] = append(reverse[e.To], e.From)
+	}
+
+	visited := map[string]bool{id: true}
+	queue := []string{id}
+	result := make([]DependencyNode, 0)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, from := range reverse[current] {
+			if visited[from] {
+				continue
+			}
+			visited[from] = true
+			queue = append(queue, from)
+			if n, ok := g.Node(from); ok {
+				result = append(result, n)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (g *DependencyGraph) addIPAddress(ip string) string {
+	return g.AddNode(DependencyNodeIPAddress, "", ip, ip)
+}
+
+func (g *DependencyGraph) addServer(zone string, srv *iaas.Server) {
+	serverID := g.AddNode(DependencyNodeServer, zone, srv.ID.String(), srv.Name)
+
+	for _, d := range srv.Disks {
+		diskID := g.AddNode(DependencyNodeDisk, zone, d.ID.String(), d.Name)
+		g.AddEdge(serverID, diskID, DependencyEdgeDisk, "")
+	}
+	if !srv.CDROMID.IsEmpty() {
+		cdromID := g.AddNode(DependencyNodeCDROM, zone, srv.CDROMID.String(), "")
+		g.AddEdge(serverID, cdromID, DependencyEdgeCDROM, "")
+	}
+
+	for _, iface := range srv.Interfaces {
+		ifaceID := g.AddNode(DependencyNodeInterface, zone, iface.ID.String(), iface.MACAddress)
+		g.AddEdge(serverID, ifaceID, DependencyEdgeInterface, "")
+
+		if !iface.SwitchID.IsEmpty() {
+			swID := g.AddNode(DependencyNodeSwitch, zone, iface.SwitchID.String(), iface.SwitchName)
+			g.AddEdge(ifaceID, swID, DependencyEdgeSwitch, "")
+		}
+		if !iface.PacketFilterID.IsEmpty() {
+			pfID := g.AddNode(DependencyNodePacketFilter, zone, iface.PacketFilterID.String(), iface.PacketFilterName)
+			g.AddEdge(ifaceID, pfID, DependencyEdgePacketFilter, "")
+		}
+
+		ip := iface.IPAddress
+		if ip == "" {
+			ip = iface.UserIPAddress
+		}
+		if ip != "" {
+			g.AddEdge(g.addIPAddress(ip), serverID, DependencyEdgeAssigned, "")
+		}
+	}
+}
+
+func (g *DependencyGraph) addProxyLB(p *ProxyLBInfo) {
+	id := g.AddNode(DependencyNodeProxyLB, "", p.ID, p.Name)
+	for _, srv := range p.Servers {
+		g.AddEdge(id, g.addIPAddress(srv.IPAddress), DependencyEdgeBackend, "")
+	}
+}
+
+func (g *DependencyGraph) addGSLB(gslb *GSLBInfo) {
+	id := g.AddNode(DependencyNodeGSLB, "", gslb.ID, gslb.Name)
+	for _, srv := range gslb.Servers {
+		g.AddEdge(id, g.addIPAddress(srv.IPAddress), DependencyEdgeDestination, "")
+	}
+}
+
+func (g *DependencyGraph) addDNS(d *DNSInfo) {
+	id := g.AddNode(DependencyNodeDNS, "", d.ID, d.Name)
+	for _, r := range d.Records {
+		if r.Type != "A" && r.Type != "AAAA" {
+			continue
+		}
+		label := r.Name + "." + d.Name
+		if r.Name == "@" || r.Name == "" {
+			label = d.Name
+		}
+		g.AddEdge(id, g.addIPAddress(r.RData), DependencyEdgeRecord, label)
+	}
+}
+
+// AddBucketEncryption はバケットがKMSキーで暗号化されている依存を追加する。kmsKeyIDが空なら何もしない。
+func (g *DependencyGraph) AddBucketEncryption(siteID, bucketName, kmsKeyID string) {
+	if kmsKeyID == "" {
+		return
+	}
+	bucketID := g.AddNode(DependencyNodeBucket, "", siteID+"/"+bucketName, bucketName)
+	keyID := g.AddNode(DependencyNodeKMSKey, "", kmsKeyID, "")
+	g.AddEdge(bucketID, keyID, DependencyEdgeEncryptionKey, "")
+}
+
+// AddVaultEncryption はシークレットマネージャーのVaultがKMSキーで暗号化されている依存を追加する。
+func (g *DependencyGraph) AddVaultEncryption(vaultID, vaultName, kmsKeyID string) {
+	if kmsKeyID == "" {
+		return
+	}
+	vID := g.AddNode(DependencyNodeVault, "", vaultID, vaultName)
+	keyID := g.AddNode(DependencyNodeKMSKey, "", kmsKeyID, "")
+	g.AddEdge(vID, keyID, DependencyEdgeEncryptionKey, "")
+}
+
+// AddKMSKey はKMSキーのノードをステータスとともに追加する。どこからも参照されていないキーもグラフに含めるために使う。
+func (g *DependencyGraph) AddKMSKey(keyID, name, status string) {
+	id := g.AddNode(DependencyNodeKMSKey, "", keyID, name)
+	g.Nodes[g.nodeIndex[id]].Status = status
+}
+
+type DependencyService struct {
+	client *Client
+}
+
+func NewDependencyService(client *Client) *DependencyService {
+	return &DependencyService{client: client}
+}
+
+// Build は指定ゾーンのIaaSリソースとグローバルリソース(ELB/GSLB/DNS)から依存グラフを組み立てる。
+// zonesが空の場合は全ゾーンを対象とする。KMSキーを参照するバケット・Vaultは呼び出し側で追加する。
+func (s *DependencyService) Build(ctx context.Context, zones []string) (*DependencyGraph, error) {
+	if len(zones) == 0 {
+		zones = Zones
+	}
+	g := NewDependencyGraph()
+
+	for _, zone := range zones {
+		if err := s.addZone(ctx, g, zone); err != nil {
+			return nil, err
+		}
+	}
+
+	proxyLBs, err := NewProxyLBService(s.client).List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range proxyLBs {
+		g.addProxyLB(&proxyLBs[i])
+	}
+
+	global := NewGlobalService(s.client)
+	gslbs, err := global.ListGSLB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range gslbs {
+		g.addGSLB(&gslbs[i])
+	}
+
+	dnsList, err := global.ListDNS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range dnsList {
+		g.addDNS(&dnsList[i])
+	}
+
+	return g, nil
+}
+
+func (s *DependencyService) addZone(ctx context.Context, g *DependencyGraph, zone string) error {
+	// サーバーに接続されていないスイッチ・パケットフィルタ・ディスクもノードとして表示するため先に登録する
+	switches, err := iaas.NewSwitchOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return err
+	}
+	for _, sw := range switches.Switches {
+		g.AddNode(DependencyNodeSwitch, zone, sw.ID.String(), sw.Name)
+	}
+
+	pfs, err := iaas.NewPacketFilterOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return err
+	}
+	for _, pf := range pfs.PacketFilters {
+		g.AddNode(DependencyNodePacketFilter, zone, pf.ID.String(), pf.Name)
+	}
+
+	disks, err := iaas.NewDiskOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return err
+	}
+	for _, d := range disks.Disks {
+		g.AddNode(DependencyNodeDisk, zone, d.ID.String(), d.Name)
+	}
+
+	servers, err := iaas.NewServerOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return err
+	}
+	for _, srv := range servers.Servers {
+		g.addServer(zone, srv)
+	}
+	return nil
+}
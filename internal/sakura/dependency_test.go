@@ -0,0 +1,135 @@
+package sakura
+
+import (
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func newTestDependencyGraph() *DependencyGraph {
+	g := NewDependencyGraph()
+	g.addServer("is1a", &iaas.Server{
+		ID:      types.ID(100),
+		Name:    "web1",
+		CDROMID: types.ID(300),
+		Disks: []*iaas.ServerConnectedDisk{
+			{ID: types.ID(200), Name: "web1-disk"},
+		},
+		Interfaces: []*iaas.InterfaceView{
+			{
+				ID:               types.ID(400),
+				MACAddress:       "00:00:5e:00:53:01",
+				IPAddress:        "192.0.2.10",
+				SwitchID:         types.ID(500),
+				SwitchName:       "shared",
+				PacketFilterID:   types.ID(600),
+				PacketFilterName: "web-pf",
+			},
+		},
+	})
+	g.addProxyLB(&ProxyLBInfo{
+		ID:      "700",
+		Name:    "elb",
+		Servers: []ProxyLBServerInfo{{IPAddress: "192.0.2.10", Port: 80, Enabled: true}},
+	})
+	g.addDNS(&DNSInfo{
+		ID:   "800",
+		Name: "example.com",
+		Records: []DNSRecord{
+			{Name: "www", Type: "A", RData: "192.0.2.10"},
+			{Name: "@", Type: "MX", RData: "10 mail.example.com."},
+		},
+	})
+	return g
+}
+
+func dependencyNodeIDs(nodes []DependencyNode) map[string]bool {
+	ids := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		ids[n.ID] = true
+	}
+	return ids
+}
+
+func TestDependencyGraph_AddServer(t *testing.T) {
+	g := newTestDependencyGraph()
+
+	for _, id := range []string{
+		"server:is1a:100",
+		"disk:is1a:200",
+		"cdrom:is1a:300",
+		"interface:is1a:400",
+		"switch:is1a:500",
+		"packetfilter:is1a:600",
+		"ipaddress:192.0.2.10",
+	} {
+		if _, ok := g.Node(id); !ok {
+			t.Errorf("node %q not found in graph: %+v", id, g.Nodes)
+		}
+	}
+
+	sw, _ := g.Node("switch:is1a:500")
+	if sw.Name != "shared" {
+		t.Errorf("switch Name = %q, want %q", sw.Name, "shared")
+	}
+}
+
+func TestDependencyGraph_Dedup(t *testing.T) {
+	g := NewDependencyGraph()
+	a := g.AddNode(DependencyNodeSwitch, "is1a", "1", "")
+	g.AddNode(DependencyNodeSwitch, "is1a", "1", "sw")
+	b := g.AddNode(DependencyNodePacketFilter, "is1a", "2", "pf")
+	g.AddEdge(a, b, DependencyEdgePacketFilter, "")
+	g.AddEdge(a, b, DependencyEdgePacketFilter, "")
+
+	if len(g.Nodes) != 2 {
+		t.Errorf("len(Nodes) = %d, want 2", len(g.Nodes))
+	}
+	if len(g.Edges) != 1 {
+		t.Errorf("len(Edges) = %d, want 1", len(g.Edges))
+	}
+	if n, _ := g.Node(a); n.Name != "sw" {
+		t.Errorf("Name = %q, want %q (empty name should be filled later)", n.Name, "sw")
+	}
+}
+
+func TestDependencyGraph_Dependents(t *testing.T) {
+	g := newTestDependencyGraph()
+
+	// スイッチを利用しているのはNIC経由でサーバー、さらにそのIPを参照するELB・DNS
+	got := dependencyNodeIDs(g.Dependents("switch:is1a:500"))
+	for _, want := range []string{"interface:is1a:400", "server:is1a:100", "ipaddress:192.0.2.10", "proxylb:700", "dns:800"} {
+		if !got[want] {
+			t.Errorf("Dependents(switch) does not contain %q: %v", want, got)
+		}
+	}
+	if got["disk:is1a:200"] {
+		t.Errorf("Dependents(switch) should not contain disk: %v", got)
+	}
+
+	// ELBは誰からも利用されていない
+	if deps := g.Dependents("proxylb:700"); len(deps) != 0 {
+		t.Errorf("Dependents(proxylb) = %+v, want empty", deps)
+	}
+}
+
+func TestDependencyGraph_KMSReferences(t *testing.T) {
+	g := NewDependencyGraph()
+	g.AddKMSKey("key-1", "main-key", "active")
+	g.AddKMSKey("key-2", "unused-key", "active")
+	g.AddBucketEncryption("isk01", "logs", "key-1")
+	g.AddVaultEncryption("vault-1", "app-secrets", "key-1")
+	g.AddBucketEncryption("isk01", "plain", "")
+
+	got := dependencyNodeIDs(g.Dependents("kmskey:key-1"))
+	if !got["bucket:isk01/logs"] || !got["vault:vault-1"] {
+		t.Errorf("Dependents(key-1) = %v, want bucket and vault", got)
+	}
+	if deps := g.Dependents("kmskey:key-2"); len(deps) != 0 {
+		t.Errorf("Dependents(key-2) = %+v, want empty", deps)
+	}
+	if _, ok := g.Node("bucket:isk01/plain"); ok {
+		t.Error("unencrypted bucket should not be added to the graph")
+	}
+}
@@ -0,0 +1,257 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// DiskResizeInput はディスクのサイズ拡張の内容。
+// さくらのクラウドのディスクはサイズを直接変更できないため、大きいサイズのディスクへコピーして差し替える。
+type DiskResizeInput struct {
+	SizeGB          int  `json:"sizeGb"`
+	ResizePartition bool `json:"resizePartition"` // コピー後に最後のパーティションとファイルシステムを拡張する
+	KeepSource      bool `json:"keepSource"`      // 差し替え後も元のディスクを削除せずに残す
+}
+
+// DiskResizeCheck はサイズ拡張の事前確認の結果。Errorsが空でなければ実行できない。
+type DiskResizeCheck struct {
+	CurrentSizeGB       int      `json:"currentSizeGb"`
+	NewSizeGB           int      `json:"newSizeGb"`
+	AvailableSizesGB    []int    `json:"availableSizesGb"`
+	CurrentMonthlyPrice int      `json:"currentMonthlyPrice"` // 0は料金不明
+	NewMonthlyPrice     int      `json:"newMonthlyPrice"`
+	ServerID            string   `json:"serverId"`
+	ServerName          string   `json:"serverName"`
+	Errors              []string `json:"errors"`
+	Warnings            []string `json:"warnings"`
+}
+
+// DiskResizeProgress はサイズ拡張の各ステップの進捗。
+type DiskResizeProgress struct {
+	Step       int    `json:"step"`
+	TotalSteps int    `json:"totalSteps"`
+	Message    string `json:"message"`
+}
+
+// CheckResize はディスクを指定したサイズへ拡張できるか確認し、拡張前後の月額料金を返す。
+func (s *DiskService) CheckResize(ctx context.Context, zone string, diskID string, sizeGB int) (*DiskResizeCheck, error) {
+	disk, err := iaas.NewDiskOp(s.client.Caller()).Read(ctx, zone, types.StringID(diskID))
+	if err != nil {
+		return nil, err
+	}
+	var srv *iaas.Server
+	if !disk.ServerID.IsEmpty() {
+		srv, err = iaas.NewServerOp(s.client.Caller()).Read(ctx, zone, disk.ServerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	plan, err := iaas.NewDiskPlanOp(s.client.Caller()).Read(ctx, zone, disk.DiskPlanID)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := NewWasteService(s.client).priceCatalog(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return checkDiskResize(disk, srv, diskPlanSizesGB(plan), prices, sizeGB), nil
+}
+
+func checkDiskResize(disk *iaas.Disk, srv *iaas.Server, availableSizesGB []int, prices wastePriceCatalog, sizeGB int) *DiskResizeCheck {
+	currentGB := disk.SizeMB / 1024
+	check := &DiskResizeCheck{
+		CurrentSizeGB:       currentGB,
+		NewSizeGB:           sizeGB,
+		AvailableSizesGB:    availableSizesGB,
+		CurrentMonthlyPrice: prices[diskServiceClassPath(disk)],
+		NewMonthlyPrice:     prices[diskServiceClassPath(&iaas.Disk{DiskPlanID: disk.DiskPlanID, SizeMB: sizeGB * 1024})],
+		Errors:              []string{},
+		Warnings:            []string{},
+	}
+
+	if sizeGB <= currentGB {
+		check.Errors = append(check.Errors, fmt.Sprintf("現在のサイズ(%dGB)より大きいサイズを指定してください", currentGB))
+	}
+	// プランのサイズ一覧が取得できない場合はAPIの検証に任せる
+	if len(availableSizesGB) > 0 {
+		found := false
+		for _, size := range availableSizesGB {
+			if size == sizeGB {
+				found = true
+			}
+		}
+		if !found {
+			check.Errors = append(check.Errors, fmt.Sprintf("%s では %dGB のディスクを作成できません", disk.DiskPlanName, sizeGB))
+		}
+	}
+	if disk.Availability != types.Availabilities.Available {
+		check.Errors = append(check.Errors, fmt.Sprintf("ディスクが利用可能な状態ではありません(%s)", disk.Availability))
+	}
+	if srv != nil {
+		check.ServerID = srv.ID.String()
+		check.ServerName = srv.Name
+		if srv.InstanceStatus != types.ServerInstanceStatuses.Down {
+			check.Errors = append(check.Errors, fmt.Sprintf("サーバー %s を停止してから実行してください", srv.Name))
+		}
+	}
+
+	check.Warnings = append(check.Warnings, "新しいディスクへコピーして差し替えるため、ディスクのIDが変わります")
+	if check.CurrentMonthlyPrice > 0 && check.NewMonthlyPrice > 0 {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("月額料金が %d円 から %d円 になります", check.CurrentMonthlyPrice, check.NewMonthlyPrice))
+	}
+	return check
+}
+
+// diskPlanSizesGB はディスクプランで作成できるサイズ(GB)を昇順で返す。
+func diskPlanSizesGB(plan *iaas.DiskPlan) []int {
+	sizes := make([]int, 0, len(plan.Size))
+	for _, size := range plan.Size {
+		if size.Availability != types.Availabilities.Available {
+			continue
+		}
+		sizes = append(sizes, size.SizeMB/1024)
+	}
+	sort.Ints(sizes)
+	return sizes
+}
+
+// Resize はディスクを大きいサイズの新しいディスクへコピーし、サーバーに接続されていれば同じ接続順で差し替える。
+// 途中で失敗した場合は元のディスクの接続を戻し、作成したディスクを削除する。
+func (s *DiskService) Resize(ctx context.Context, zone string, diskID string, input DiskResizeInput, progress func(DiskResizeProgress)) (*DiskInfo, error) {
+	check, err := s.CheckResize(ctx, zone, diskID, input.SizeGB)
+	if err != nil {
+		return nil, err
+	}
+	if len(check.Errors) > 0 {
+		return nil, fmt.Errorf("%s", check.Errors[0])
+	}
+
+	diskOp := iaas.NewDiskOp(s.client.Caller())
+	src, err := diskOp.Read(ctx, zone, types.StringID(diskID))
+	if err != nil {
+		return nil, err
+	}
+	// 差し替えるディスクより後ろに接続されているディスクは、接続順を保つために一度外して付け直す
+	var following []types.ID
+	if !src.ServerID.IsEmpty() {
+		srv, err := iaas.NewServerOp(s.client.Caller()).Read(ctx, zone, src.ServerID)
+		if err != nil {
+			return nil, err
+		}
+		following = disksConnectedAfter(srv.Disks, src.ID)
+	}
+
+	total := 2
+	if input.ResizePartition {
+		total++
+	}
+	if !src.ServerID.IsEmpty() {
+		total++
+	}
+	if !input.KeepSource {
+		total++
+	}
+	step := 0
+	report := func(format string, args ...interface{}) {
+		step++
+		if progress != nil {
+			progress(DiskResizeProgress{Step: step, TotalSteps: total, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+	waitReady := func(id types.ID) error {
+		_, err := iaas.WaiterForReady(func() (interface{}, error) {
+			return diskOp.Read(ctx, zone, id)
+		}).WaitForState(ctx)
+		return err
+	}
+	rollback := &rollbackStack{}
+
+	report("ディスク %s を %dGB のディスクへコピーしています", src.Name, input.SizeGB)
+	disk, err := diskOp.Create(ctx, zone, &iaas.DiskCreateRequest{
+		Name:         src.Name,
+		Description:  src.Description,
+		Tags:         src.Tags,
+		IconID:       src.IconID,
+		SizeMB:       input.SizeGB * 1024,
+		DiskPlanID:   src.DiskPlanID,
+		Connection:   src.Connection,
+		SourceDiskID: src.ID,
+	}, nil, types.ID(0))
+	if err != nil {
+		return nil, err
+	}
+	rollback.push(func(ctx context.Context) error {
+		return diskOp.Delete(ctx, zone, disk.ID)
+	})
+	report("コピーの完了を待っています")
+	if err := waitReady(disk.ID); err != nil {
+		return nil, rollback.run(ctx, err)
+	}
+
+	if input.ResizePartition {
+		report("パーティションを拡張しています")
+		if err := diskOp.ResizePartition(ctx, zone, disk.ID, &iaas.DiskResizePartitionRequest{Background: true}); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+		if err := waitReady(disk.ID); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+	}
+
+	if !src.ServerID.IsEmpty() {
+		report("サーバー %s のディスクを差し替えています", check.ServerName)
+		detach := append([]types.ID{src.ID}, following...)
+		for i := len(detach) - 1; i >= 0; i-- {
+			id := detach[i]
+			if err := diskOp.DisconnectFromServer(ctx, zone, id); err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+			rollback.push(func(ctx context.Context) error {
+				return diskOp.ConnectToServer(ctx, zone, id, src.ServerID)
+			})
+		}
+		for _, id := range append([]types.ID{disk.ID}, following...) {
+			if err := diskOp.ConnectToServer(ctx, zone, id, src.ServerID); err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+			rollback.push(func(ctx context.Context) error {
+				return diskOp.DisconnectFromServer(ctx, zone, id)
+			})
+		}
+	}
+
+	if !input.KeepSource {
+		report("元のディスクを削除しています")
+		if err := diskOp.Delete(ctx, zone, src.ID); err != nil {
+			return nil, fmt.Errorf("新しいディスク %s への差し替えは完了しましたが、元のディスクを削除できませんでした: %w", disk.ID, err)
+		}
+	}
+
+	resized, err := diskOp.Read(ctx, zone, disk.ID)
+	if err != nil {
+		return nil, err
+	}
+	return diskFromSDK(zone, resized), nil
+}
+
+// disksConnectedAfter はサーバーの接続順でdiskIDより後ろに接続されているディスクを接続順に返す。
+func disksConnectedAfter(disks []*iaas.ServerConnectedDisk, diskID types.ID) []types.ID {
+	sorted := append([]*iaas.ServerConnectedDisk(nil), disks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ConnectionOrder < sorted[j].ConnectionOrder })
+
+	var after []types.ID
+	found := false
+	for _, d := range sorted {
+		if found {
+			after = append(after, d.ID)
+		}
+		if d.ID == diskID {
+			found = true
+		}
+	}
+	return after
+}
@@ -0,0 +1,75 @@
+package sakura
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func TestCheckDiskResize(t *testing.T) {
+	disk := &iaas.Disk{
+		ID:           types.ID(100),
+		SizeMB:       20 * 1024,
+		DiskPlanID:   types.DiskPlans.SSD,
+		DiskPlanName: "SSDプラン",
+		Availability: types.Availabilities.Available,
+	}
+	sizes := []int{20, 40, 100}
+	prices := wastePriceCatalog{"cloud/disk/ssd/20g": 1980, "cloud/disk/ssd/40g": 3960}
+
+	t.Run("ok", func(t *testing.T) {
+		check := checkDiskResize(disk, nil, sizes, prices, 40)
+		if len(check.Errors) != 0 {
+			t.Fatalf("errors = %v", check.Errors)
+		}
+		if check.CurrentMonthlyPrice != 1980 || check.NewMonthlyPrice != 3960 {
+			t.Errorf("prices = %d -> %d", check.CurrentMonthlyPrice, check.NewMonthlyPrice)
+		}
+	})
+
+	t.Run("running server", func(t *testing.T) {
+		srv := &iaas.Server{ID: types.ID(200), Name: "web", InstanceStatus: types.ServerInstanceStatuses.Up}
+		check := checkDiskResize(disk, srv, sizes, prices, 40)
+		if len(check.Errors) != 1 || check.ServerName != "web" {
+			t.Errorf("check = %+v, want an error for the running server", check)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		sizeGB int
+	}{
+		{"same size", 20},
+		{"smaller", 10},
+		{"not in plan", 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if check := checkDiskResize(disk, nil, sizes, prices, tt.sizeGB); len(check.Errors) == 0 {
+				t.Errorf("%dGB should be rejected", tt.sizeGB)
+			}
+		})
+	}
+
+	t.Run("unknown plan sizes", func(t *testing.T) {
+		if check := checkDiskResize(disk, nil, nil, prices, 60); len(check.Errors) != 0 {
+			t.Errorf("errors = %v, want none when the plan sizes are unknown", check.Errors)
+		}
+	})
+}
+
+func TestDisksConnectedAfter(t *testing.T) {
+	disks := []*iaas.ServerConnectedDisk{
+		{ID: types.ID(3), ConnectionOrder: 3},
+		{ID: types.ID(1), ConnectionOrder: 1},
+		{ID: types.ID(2), ConnectionOrder: 2},
+	}
+	if got := disksConnectedAfter(disks, types.ID(1)); !reflect.DeepEqual(got, []types.ID{2, 3}) {
+		t.Errorf("after 1 = %v", got)
+	}
+	if got := disksConnectedAfter(disks, types.ID(3)); len(got) != 0 {
+		t.Errorf("after the last disk = %v, want none", got)
+	}
+}
@@ -0,0 +1,149 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// InterfaceInfo はサーバーに接続されたNIC。
+// 共有セグメントに接続されたNICはIPAddressがAPIから割り当てられ、スイッチに接続されたNICはUserIPAddressを利用者が設定する。
+type InterfaceInfo struct {
+	ID               string `json:"id"`
+	Index            int    `json:"index"`
+	MACAddress       string `json:"macAddress"`
+	IPAddress        string `json:"ipAddress"`
+	UserIPAddress    string `json:"userIpAddress"`
+	HostName         string `json:"hostName"`
+	SwitchID         string `json:"switchId"`
+	SwitchName       string `json:"switchName"`
+	SwitchScope      string `json:"switchScope"` // "shared"は共有セグメント、"user"はスイッチ、空文字は未接続
+	PacketFilterID   string `json:"packetFilterId"`
+	PacketFilterName string `json:"packetFilterName"`
+}
+
+type InterfaceService struct {
+	client *Client
+}
+
+func NewInterfaceService(client *Client) *InterfaceService {
+	return &InterfaceService{client: client}
+}
+
+// List はサーバーのNICを接続順に返す。
+func (s *InterfaceService) List(ctx context.Context, zone string, serverID string) ([]InterfaceInfo, error) {
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	srv, err := serverOp.Read(ctx, zone, types.StringID(serverID))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]InterfaceInfo, 0, len(srv.Interfaces))
+	for i, iface := range srv.Interfaces {
+		list = append(list, interfaceInfoFromView(i, iface))
+	}
+	return list, nil
+}
+
+// Add はサーバーにNICを追加する。追加直後のNICはどこにも接続されていない。
+// サーバーが停止していない場合はAPIエラーとなる。
+func (s *InterfaceService) Add(ctx context.Context, zone string, serverID string) (*InterfaceInfo, error) {
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	iface, err := interfaceOp.Create(ctx, zone, &iaas.InterfaceCreateRequest{ServerID: types.StringID(serverID)})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ctx, zone, iface.ServerID, iface.ID)
+}
+
+// Delete はNICを削除する。サーバーが停止していない場合はAPIエラーとなる。
+func (s *InterfaceService) Delete(ctx context.Context, zone string, interfaceID string) error {
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	return interfaceOp.Delete(ctx, zone, types.StringID(interfaceID))
+}
+
+// ConnectToSwitch はNICをスイッチに接続する。switchIDに "shared" を指定した場合は共有セグメントに接続する。
+func (s *InterfaceService) ConnectToSwitch(ctx context.Context, zone string, interfaceID string, switchID string) error {
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	id := types.StringID(interfaceID)
+	if switchID == ServerNICUpstreamShared {
+		return interfaceOp.ConnectToSharedSegment(ctx, zone, id)
+	}
+	return interfaceOp.ConnectToSwitch(ctx, zone, id, types.StringID(switchID))
+}
+
+// DisconnectFromSwitch はNICをスイッチ(共有セグメントを含む)から切断する。
+func (s *InterfaceService) DisconnectFromSwitch(ctx context.Context, zone string, interfaceID string) error {
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	return interfaceOp.DisconnectFromSwitch(ctx, zone, types.StringID(interfaceID))
+}
+
+// ApplyPacketFilter はNICにパケットフィルタを適用する。
+func (s *InterfaceService) ApplyPacketFilter(ctx context.Context, zone string, interfaceID string, packetFilterID string) error {
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	return interfaceOp.ConnectToPacketFilter(ctx, zone, types.StringID(interfaceID), types.StringID(packetFilterID))
+}
+
+// RemovePacketFilter はNICに適用されているパケットフィルタを解除する。
+func (s *InterfaceService) RemovePacketFilter(ctx context.Context, zone string, interfaceID string) error {
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	return interfaceOp.DisconnectFromPacketFilter(ctx, zone, types.StringID(interfaceID))
+}
+
+// SetUserIPAddress はスイッチに接続されたNICのIPアドレスを設定する。空文字を指定すると設定を解除する。
+// この値はコントロールパネル上の表示用で、サーバー内のOSのネットワーク設定は変更されない。
+func (s *InterfaceService) SetUserIPAddress(ctx context.Context, zone string, interfaceID string, ipAddress string) (*InterfaceInfo, error) {
+	if err := validateUserIPAddress(ipAddress); err != nil {
+		return nil, err
+	}
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	iface, err := interfaceOp.Update(ctx, zone, types.StringID(interfaceID), &iaas.InterfaceUpdateRequest{UserIPAddress: ipAddress})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ctx, zone, iface.ServerID, iface.ID)
+}
+
+// find はサーバーのNIC一覧から指定したNICを探す。接続順(Index)やスイッチ名はサーバー側の情報にしか含まれないため。
+func (s *InterfaceService) find(ctx context.Context, zone string, serverID types.ID, interfaceID types.ID) (*InterfaceInfo, error) {
+	list, err := s.List(ctx, zone, serverID.String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].ID == interfaceID.String() {
+			return &list[i], nil
+		}
+	}
+	return nil, fmt.Errorf("NICが見つかりません: %s", interfaceID)
+}
+
+func validateUserIPAddress(ipAddress string) error {
+	if ipAddress == "" {
+		return nil
+	}
+	ip := net.ParseIP(ipAddress)
+	if ip == nil || ip.To4() == nil {
+		return fmt.Errorf("IPv4アドレスの形式が不正です: %s", ipAddress)
+	}
+	return nil
+}
+
+func interfaceInfoFromView(index int, iface *iaas.InterfaceView) InterfaceInfo {
+	return InterfaceInfo{
+		ID:               iface.ID.String(),
+		Index:            index,
+		MACAddress:       iface.MACAddress,
+		IPAddress:        iface.IPAddress,
+		UserIPAddress:    iface.UserIPAddress,
+		HostName:         iface.HostName,
+		SwitchID:         iface.SwitchID.String(),
+		SwitchName:       iface.SwitchName,
+		SwitchScope:      string(iface.SwitchScope),
+		PacketFilterID:   iface.PacketFilterID.String(),
+		PacketFilterName: iface.PacketFilterName,
+	}
+}
@@ -0,0 +1,114 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func newTestInterfaceService(t *testing.T) *InterfaceService {
+	t.Helper()
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	return NewInterfaceService(&Client{})
+}
+
+func TestInterfaceService_Lifecycle(t *testing.T) {
+	service := newTestInterfaceService(t)
+	ctx := context.Background()
+
+	srv, err := iaas.NewServerOp(nil).Create(ctx, "is1a", &iaas.ServerCreateRequest{
+		CPU:               1,
+		MemoryMB:          1024,
+		Name:              "nic-test-server",
+		ConnectedSwitches: []*iaas.ConnectedSwitch{{Scope: types.Scopes.Shared}},
+	})
+	if err != nil {
+		t.Fatalf("create server: %v", err)
+	}
+	sw, err := iaas.NewSwitchOp(nil).Create(ctx, "is1a", &iaas.SwitchCreateRequest{Name: "nic-test-switch"})
+	if err != nil {
+		t.Fatalf("create switch: %v", err)
+	}
+	pf, err := iaas.NewPacketFilterOp(nil).Create(ctx, "is1a", &iaas.PacketFilterCreateRequest{Name: "nic-test-pf"})
+	if err != nil {
+		t.Fatalf("create packet filter: %v", err)
+	}
+
+	list, err := service.List(ctx, "is1a", srv.ID.String())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("len(List) = %d, want 1", len(list))
+	}
+	if list[0].MACAddress == "" {
+		t.Error("MACAddress should not be empty")
+	}
+
+	added, err := service.Add(ctx, "is1a", srv.ID.String())
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added.Index != 1 {
+		t.Errorf("added.Index = %d, want 1", added.Index)
+	}
+
+	if err := service.ConnectToSwitch(ctx, "is1a", added.ID, sw.ID.String()); err != nil {
+		t.Fatalf("ConnectToSwitch: %v", err)
+	}
+	updated, err := service.SetUserIPAddress(ctx, "is1a", added.ID, "192.168.0.10")
+	if err != nil {
+		t.Fatalf("SetUserIPAddress: %v", err)
+	}
+	if updated.UserIPAddress != "192.168.0.10" {
+		t.Errorf("UserIPAddress = %q, want %q", updated.UserIPAddress, "192.168.0.10")
+	}
+	if updated.SwitchID != sw.ID.String() {
+		t.Errorf("SwitchID = %q, want %q", updated.SwitchID, sw.ID.String())
+	}
+
+	if err := service.ApplyPacketFilter(ctx, "is1a", added.ID, pf.ID.String()); err != nil {
+		t.Fatalf("ApplyPacketFilter: %v", err)
+	}
+	list, err = service.List(ctx, "is1a", srv.ID.String())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if list[1].PacketFilterID != pf.ID.String() {
+		t.Errorf("PacketFilterID = %q, want %q", list[1].PacketFilterID, pf.ID.String())
+	}
+
+	if err := service.RemovePacketFilter(ctx, "is1a", added.ID); err != nil {
+		t.Fatalf("RemovePacketFilter: %v", err)
+	}
+	if err := service.DisconnectFromSwitch(ctx, "is1a", added.ID); err != nil {
+		t.Fatalf("DisconnectFromSwitch: %v", err)
+	}
+	if err := service.Delete(ctx, "is1a", added.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	list, err = service.List(ctx, "is1a", srv.ID.String())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("len(List) after Delete = %d, want 1", len(list))
+	}
+}
+
+func TestValidateUserIPAddress(t *testing.T) {
+	for _, ip := range []string{"", "192.168.0.10"} {
+		if err := validateUserIPAddress(ip); err != nil {
+			t.Errorf("validateUserIPAddress(%q) = %v, want nil", ip, err)
+		}
+	}
+	for _, ip := range []string{"192.168.0", "2001:db8::1", "example.com"} {
+		if err := validateUserIPAddress(ip); err == nil {
+			t.Errorf("validateUserIPAddress(%q) = nil, want error", ip)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// ルーター(スイッチ+ルーター)で選べる帯域幅(Mbps)と、申請なしで割り当てられるネットワークマスク長。
+var (
+	internetBandWidths      = []int{100, 250, 500, 1000, 1500, 2000, 2500, 3000, 5000}
+	internetNetworkMaskLens = []int{28, 27, 26}
+)
+
+type InternetInfo struct {
+	ID             string               `json:"id"`
+	Name           string               `json:"name"`
+	Description    string               `json:"description"`
+	Tags           []string             `json:"tags"`
+	Zone           string               `json:"zone"`
+	BandWidthMbps  int                  `json:"bandWidthMbps"`
+	NetworkMaskLen int                  `json:"networkMaskLen"`
+	SwitchID       string               `json:"switchId"`
+	SwitchName     string               `json:"switchName"`
+	ServerCount    int                  `json:"serverCount"`
+	Subnets        []InternetSubnetInfo `json:"subnets"`
+	IPv6Nets       []IPv6NetInfo        `json:"ipv6Nets"`
+	CreatedAt      string               `json:"createdAt"`
+}
+
+// InternetSubnetInfo はルーターに割り当てられたグローバルIPアドレスのブロック。
+// NextHopが空のものはルーター作成時に割り当てられたサブネットで、それ以外は追加したサブネット。
+type InternetSubnetInfo struct {
+	ID             string   `json:"id"`
+	NetworkAddress string   `json:"networkAddress"`
+	NetworkMaskLen int      `json:"networkMaskLen"`
+	DefaultRoute   string   `json:"defaultRoute"`
+	NextHop        string   `json:"nextHop"`
+	StaticRoute    string   `json:"staticRoute"`
+	IPAddresses    []string `json:"ipAddresses"`
+}
+
+type IPv6NetInfo struct {
+	ID             string `json:"id"`
+	IPv6Prefix     string `json:"ipv6Prefix"`
+	IPv6PrefixLen  int    `json:"ipv6PrefixLen"`
+	IPv6PrefixTail string `json:"ipv6PrefixTail"`
+	SwitchID       string `json:"switchId"`
+}
+
+// GlobalSubnetInfo はゾーン内のグローバルIPアドレスのブロックと、割り当て先のルーター・スイッチ。
+type GlobalSubnetInfo struct {
+	InternetSubnetInfo
+	InternetID   string `json:"internetId"`
+	InternetName string `json:"internetName"`
+	SwitchID     string `json:"switchId"`
+	SwitchName   string `json:"switchName"`
+}
+
+type IPAddressInfo struct {
+	IPAddress   string `json:"ipAddress"`
+	HostName    string `json:"hostName"`
+	InterfaceID string `json:"interfaceId"`
+	SubnetID    string `json:"subnetId"`
+}
+
+type InternetCreateInput struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	Tags           []string `json:"tags"`
+	NetworkMaskLen int      `json:"networkMaskLen"`
+	BandWidthMbps  int      `json:"bandWidthMbps"`
+	EnableIPv6     bool     `json:"enableIpv6"`
+}
+
+type InternetService struct {
+	client *Client
+}
+
+func NewInternetService(client *Client) *InternetService {
+	return &InternetService{client: client}
+}
+
+func (s *InternetService) List(ctx context.Context, zone string) ([]InternetInfo, error) {
+	op := iaas.NewInternetOp(s.client.Caller())
+	result, err := op.Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]InternetInfo, 0, len(result.Internet))
+	for _, r := range result.Internet {
+		list = append(list, *internetFromSDK(zone, r))
+	}
+	return list, nil
+}
+
+// Get はルーターの詳細を返す。サブネットごとに割り当て可能なIPアドレスの一覧も含む。
+func (s *InternetService) Get(ctx context.Context, zone string, internetID string) (*InternetInfo, error) {
+	op := iaas.NewInternetOp(s.client.Caller())
+	r, err := op.Read(ctx, zone, types.StringID(internetID))
+	if err != nil {
+		return nil, err
+	}
+	info := internetFromSDK(zone, r)
+
+	subnetOp := iaas.NewSubnetOp(s.client.Caller())
+	for i := range info.Subnets {
+		subnet, err := subnetOp.Read(ctx, zone, types.StringID(info.Subnets[i].ID))
+		if err != nil {
+			return nil, err
+		}
+		info.Subnets[i].IPAddresses = subnetIPAddresses(subnet)
+	}
+	return info, nil
+}
+
+func (s *InternetService) Create(ctx context.Context, zone string, input InternetCreateInput) (*InternetInfo, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("名前を指定してください")
+	}
+	if !slices.Contains(internetNetworkMaskLens, input.NetworkMaskLen) {
+		return nil, fmt.Errorf("ネットワークマスク長は %v のいずれかを指定してください", internetNetworkMaskLens)
+	}
+	if !slices.Contains(internetBandWidths, input.BandWidthMbps) {
+		return nil, fmt.Errorf("帯域幅は %v Mbpsのいずれかを指定してください", internetBandWidths)
+	}
+
+	op := iaas.NewInternetOp(s.client.Caller())
+	r, err := op.Create(ctx, zone, &iaas.InternetCreateRequest{
+		Name:           input.Name,
+		Description:    input.Description,
+		Tags:           input.Tags,
+		NetworkMaskLen: input.NetworkMaskLen,
+		BandWidthMbps:  input.BandWidthMbps,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if input.EnableIPv6 {
+		if _, err := op.EnableIPv6(ctx, zone, r.ID); err != nil {
+			return nil, fmt.Errorf("ルーターを作成しましたが、IPv6の有効化に失敗しました: %w", err)
+		}
+	}
+	return s.Get(ctx, zone, r.ID.String())
+}
+
+func (s *InternetService) Update(ctx context.Context, zone string, internetID string, name string, description string, tags []string) (*InternetInfo, error) {
+	op := iaas.NewInternetOp(s.client.Caller())
+	id := types.StringID(internetID)
+	current, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := op.Update(ctx, zone, id, &iaas.InternetUpdateRequest{
+		Name:        name,
+		Description: description,
+		Tags:        tags,
+		IconID:      current.IconID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return internetFromSDK(zone, r), nil
+}
+
+// Delete はルーターを削除する。接続されているスイッチも一緒に削除されるため、サーバー等が接続されている場合は削除できない。
+func (s *InternetService) Delete(ctx context.Context, zone string, internetID string) error {
+	op := iaas.NewInternetOp(s.client.Caller())
+	id := types.StringID(internetID)
+	r, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return err
+	}
+	if r.ServerCount > 0 {
+		return fmt.Errorf("ルーター %s のスイッチに %d 台のサーバー等が接続されているため削除できません", r.Name, r.ServerCount)
+	}
+	return op.Delete(ctx, zone, id)
+}
+
+// UpdateBandWidth は帯域幅を変更する。変更中は一時的に通信が途切れる。
+func (s *InternetService) UpdateBandWidth(ctx context.Context, zone string, internetID string, bandWidthMbps int) (*InternetInfo, error) {
+	if !slices.Contains(internetBandWidths, bandWidthMbps) {
+		return nil, fmt.Errorf("帯域幅は %v Mbpsのいずれかを指定してください", internetBandWidths)
+	}
+	op := iaas.NewInternetOp(s.client.Caller())
+	r, err := op.UpdateBandWidth(ctx, zone, types.StringID(internetID), &iaas.InternetUpdateBandWidthRequest{
+		BandWidthMbps: bandWidthMbps,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return internetFromSDK(zone, r), nil
+}
+
+// AddSubnet はルーターにグローバルIPアドレスのブロックを追加し、nextHop(ルーター配下のアドレス)へルーティングする。
+func (s *InternetService) AddSubnet(ctx context.Context, zone string, internetID string, networkMaskLen int, nextHop string) (*InternetSubnetInfo, error) {
+	if !slices.Contains(internetNetworkMaskLens, networkMaskLen) {
+		return nil, fmt.Errorf("ネットワークマスク長は %v のいずれかを指定してください", internetNetworkMaskLens)
+	}
+	if nextHop == "" {
+		return nil, fmt.Errorf("ネクストホップを指定してください")
+	}
+	op := iaas.NewInternetOp(s.client.Caller())
+	r, err := op.AddSubnet(ctx, zone, types.StringID(internetID), &iaas.InternetAddSubnetRequest{
+		NetworkMaskLen: networkMaskLen,
+		NextHop:        nextHop,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &InternetSubnetInfo{
+		ID:             r.ID.String(),
+		NetworkAddress: r.NetworkAddress,
+		NetworkMaskLen: r.NetworkMaskLen,
+		DefaultRoute:   r.DefaultRoute,
+		NextHop:        r.NextHop,
+		StaticRoute:    r.StaticRoute,
+		IPAddresses:    r.IPAddresses,
+	}, nil
+}
+
+// DeleteSubnet は追加したサブネットを削除する。ルーター作成時に割り当てられたサブネットは削除できない。
+func (s *InternetService) DeleteSubnet(ctx context.Context, zone string, internetID string, subnetID string) error {
+	op := iaas.NewInternetOp(s.client.Caller())
+	id := types.StringID(internetID)
+	r, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return err
+	}
+	subnet := findInternetSubnet(r, subnetID)
+	if subnet == nil {
+		return fmt.Errorf("サブネット %s はルーター %s に割り当てられていません", subnetID, r.Name)
+	}
+	if subnet.NextHop == "" {
+		return fmt.Errorf("ルーター作成時に割り当てられたサブネットは削除できません")
+	}
+	return op.DeleteSubnet(ctx, zone, id, subnet.ID)
+}
+
+func findInternetSubnet(r *iaas.Internet, subnetID string) *iaas.InternetSubnet {
+	if r.Switch == nil {
+		return nil
+	}
+	for _, subnet := range r.Switch.Subnets {
+		if subnet.ID.String() == subnetID {
+			return subnet
+		}
+	}
+	return nil
+}
+
+// EnableIPv6 はルーターにIPv6ネットワーク(/64)を割り当てる。
+func (s *InternetService) EnableIPv6(ctx context.Context, zone string, internetID string) (*IPv6NetInfo, error) {
+	op := iaas.NewInternetOp(s.client.Caller())
+	r, err := op.EnableIPv6(ctx, zone, types.StringID(internetID))
+	if err != nil {
+		return nil, err
+	}
+	return &IPv6NetInfo{
+		ID:            r.ID.String(),
+		IPv6Prefix:    r.IPv6Prefix,
+		IPv6PrefixLen: r.IPv6PrefixLen,
+	}, nil
+}
+
+func (s *InternetService) DisableIPv6(ctx context.Context, zone string, internetID string, ipv6NetID string) error {
+	op := iaas.NewInternetOp(s.client.Caller())
+	return op.DisableIPv6(ctx, zone, types.StringID(internetID), types.StringID(ipv6NetID))
+}
+
+// ListSubnets はゾーン内のグローバルIPアドレスのブロックを、割り当て先のルーター・スイッチと合わせて返す。
+func (s *InternetService) ListSubnets(ctx context.Context, zone string) ([]GlobalSubnetInfo, error) {
+	routers, err := iaas.NewInternetOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	subnets, err := iaas.NewSubnetOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	return globalSubnetsFromSDK(routers.Internet, subnets.Subnets), nil
+}
+
+func globalSubnetsFromSDK(routers []*iaas.Internet, subnets []*iaas.Subnet) []GlobalSubnetInfo {
+	byID := make(map[types.ID]*iaas.Internet, len(routers))
+	for _, r := range routers {
+		byID[r.ID] = r
+	}
+
+	list := make([]GlobalSubnetInfo, 0, len(subnets))
+	for _, subnet := range subnets {
+		info := GlobalSubnetInfo{
+			InternetSubnetInfo: InternetSubnetInfo{
+				ID:             subnet.ID.String(),
+				NetworkAddress: subnet.NetworkAddress,
+				NetworkMaskLen: subnet.NetworkMaskLen,
+				DefaultRoute:   subnet.DefaultRoute,
+				NextHop:        subnet.NextHop,
+				StaticRoute:    subnet.StaticRoute,
+				IPAddresses:    subnetIPAddresses(subnet),
+			},
+			InternetID: subnet.InternetID.String(),
+			SwitchID:   subnet.SwitchID.String(),
+		}
+		if r, ok := byID[subnet.InternetID]; ok {
+			info.InternetName = r.Name
+			if r.Switch != nil {
+				info.SwitchName = r.Switch.Name
+			}
+		}
+		list = append(list, info)
+	}
+	return list
+}
+
+func subnetIPAddresses(subnet *iaas.Subnet) []string {
+	addresses := make([]string, 0, len(subnet.IPAddresses))
+	for _, ip := range subnet.IPAddresses {
+		addresses = append(addresses, ip.IPAddress)
+	}
+	return addresses
+}
+
+// ListIPAddresses はゾーン内で利用しているグローバルIPv4アドレスと、その逆引きホスト名を返す。
+func (s *InternetService) ListIPAddresses(ctx context.Context, zone string) ([]IPAddressInfo, error) {
+	op := iaas.NewIPAddressOp(s.client.Caller())
+	result, err := op.List(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]IPAddressInfo, 0, len(result.IPAddress))
+	for _, ip := range result.IPAddress {
+		list = append(list, IPAddressInfo{
+			IPAddress:   ip.IPAddress,
+			HostName:    ip.HostName,
+			InterfaceID: ip.InterfaceID.String(),
+			SubnetID:    ip.SubnetID.String(),
+		})
+	}
+	return list, nil
+}
+
+// ListIPv6Nets はゾーン内のルーターに割り当てられたIPv6ネットワークを返す。
+func (s *InternetService) ListIPv6Nets(ctx context.Context, zone string) ([]IPv6NetInfo, error) {
+	op := iaas.NewIPv6NetOp(s.client.Caller())
+	result, err := op.List(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]IPv6NetInfo, 0, len(result.IPv6Nets))
+	for _, n := range result.IPv6Nets {
+		list = append(list, IPv6NetInfo{
+			ID:             n.ID.String(),
+			IPv6Prefix:     n.IPv6Prefix,
+			IPv6PrefixLen:  n.IPv6PrefixLen,
+			IPv6PrefixTail: n.IPv6PrefixTail,
+			SwitchID:       n.SwitchID.String(),
+		})
+	}
+	return list, nil
+}
+
+func internetFromSDK(zone string, r *iaas.Internet) *InternetInfo {
+	info := &InternetInfo{
+		ID:             r.ID.String(),
+		Name:           r.Name,
+		Description:    r.Description,
+		Tags:           r.Tags,
+		Zone:           zone,
+		BandWidthMbps:  r.BandWidthMbps,
+		NetworkMaskLen: r.NetworkMaskLen,
+		ServerCount:    r.ServerCount,
+		Subnets:        []InternetSubnetInfo{},
+		IPv6Nets:       []IPv6NetInfo{},
+		CreatedAt:      r.CreatedAt.Format(time.RFC3339),
+	}
+	if r.Switch == nil {
+		return info
+	}
+
+	info.SwitchID = r.Switch.ID.String()
+	info.SwitchName = r.Switch.Name
+	for _, subnet := range r.Switch.Subnets {
+		info.Subnets = append(info.Subnets, InternetSubnetInfo{
+			ID:             subnet.ID.String(),
+			NetworkAddress: subnet.NetworkAddress,
+			NetworkMaskLen: subnet.NetworkMaskLen,
+			DefaultRoute:   subnet.DefaultRoute,
+			NextHop:        subnet.NextHop,
+			StaticRoute:    subnet.StaticRoute,
+		})
+	}
+	for _, n := range r.Switch.IPv6Nets {
+		info.IPv6Nets = append(info.IPv6Nets, IPv6NetInfo{
+			ID:            n.ID.String(),
+			IPv6Prefix:    n.IPv6Prefix,
+			IPv6PrefixLen: n.IPv6PrefixLen,
+			SwitchID:      info.SwitchID,
+		})
+	}
+	return info
+}
@@ -0,0 +1,70 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func TestInternetService_CreateAndSubnets(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	service := NewInternetService(&Client{})
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, "is1a", InternetCreateInput{Name: "router", NetworkMaskLen: 28, BandWidthMbps: 300}); err == nil {
+		t.Error("Create with an unsupported bandwidth should fail")
+	}
+	router, err := service.Create(ctx, "is1a", InternetCreateInput{Name: "router", NetworkMaskLen: 28, BandWidthMbps: 100})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if router.SwitchID == "" || len(router.Subnets) != 1 {
+		t.Fatalf("router = %+v, want a switch with the initial subnet", router)
+	}
+
+	if err := service.DeleteSubnet(ctx, "is1a", router.ID, router.Subnets[0].ID); err == nil {
+		t.Error("DeleteSubnet should refuse the initial subnet")
+	}
+	subnet, err := service.AddSubnet(ctx, "is1a", router.ID, 28, router.Subnets[0].DefaultRoute)
+	if err != nil {
+		t.Fatalf("AddSubnet: %v", err)
+	}
+	if err := service.DeleteSubnet(ctx, "is1a", router.ID, subnet.ID); err != nil {
+		t.Errorf("DeleteSubnet: %v", err)
+	}
+
+	updated, err := service.UpdateBandWidth(ctx, "is1a", router.ID, 250)
+	if err != nil {
+		t.Fatalf("UpdateBandWidth: %v", err)
+	}
+	if updated.BandWidthMbps != 250 {
+		t.Errorf("BandWidthMbps = %d, want 250", updated.BandWidthMbps)
+	}
+}
+
+func TestGlobalSubnetsFromSDK(t *testing.T) {
+	routers := []*iaas.Internet{
+		{ID: 1, Name: "router", Switch: &iaas.SwitchInfo{ID: 10, Name: "router-switch"}},
+	}
+	subnets := []*iaas.Subnet{
+		{
+			ID: 100, InternetID: 1, SwitchID: 10, NetworkAddress: "192.0.2.0", NetworkMaskLen: 28,
+			IPAddresses: []*iaas.SubnetIPAddress{{IPAddress: "192.0.2.4"}, {IPAddress: "192.0.2.5"}},
+		},
+		{ID: 200, InternetID: types.ID(2), NetworkAddress: "198.51.100.0", NetworkMaskLen: 28, NextHop: "192.0.2.4"},
+	}
+	got := globalSubnetsFromSDK(routers, subnets)
+	if len(got) != 2 {
+		t.Fatalf("got %+v", got)
+	}
+	if got[0].InternetName != "router" || got[0].SwitchName != "router-switch" || len(got[0].IPAddresses) != 2 {
+		t.Errorf("subnet = %+v", got[0])
+	}
+	if got[1].InternetName != "" || got[1].InternetID != "2" {
+		t.Errorf("subnet of an unknown router = %+v", got[1])
+	}
+}
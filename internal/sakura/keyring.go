@@ -8,8 +8,16 @@ import (
 
 const (
 	keyringService = "sakpilot"
+
+	// sshPrivateKeyKeyringLimit is the largest private key that fits in every platform keyring.
+	// Windows Credential Manager caps secrets at 2560 bytes and macOS Keychain at ~3000 bytes
+	// including the service and account names.
+	sshPrivateKeyKeyringLimit = 2500
 )
 
+// ErrKeyringDataTooBig is returned when a secret exceeds the platform keyring limit
+var ErrKeyringDataTooBig = keyring.ErrSetDataTooBig
+
 // SaveObjectStorageSecret saves the secret key for an Object Storage access key
 func SaveObjectStorageSecret(siteID, accessKeyID, secretKey string) error {
 	account := fmt.Sprintf("objectstorage/%s/%s", siteID, accessKeyID)
@@ -57,3 +65,42 @@ func HasContainerRegistrySecret(registryID, userName string) bool {
 	_, err := GetContainerRegistrySecret(registryID, userName)
 	return err == nil
 }
+
+// SaveSSHPrivateKey saves the private key generated for an SSH key
+func SaveSSHPrivateKey(sshKeyID, privateKey string) error {
+	if len(privateKey) > sshPrivateKeyKeyringLimit {
+		return ErrKeyringDataTooBig
+	}
+	account := fmt.Sprintf("sshkey/%s", sshKeyID)
+	return keyring.Set(keyringService, account, privateKey)
+}
+
+// GetSSHPrivateKey retrieves the private key for an SSH key
+func GetSSHPrivateKey(sshKeyID string) (string, error) {
+	account := fmt.Sprintf("sshkey/%s", sshKeyID)
+	return keyring.Get(keyringService, account)
+}
+
+// DeleteSSHPrivateKey removes the private key for an SSH key
+func DeleteSSHPrivateKey(sshKeyID string) error {
+	account := fmt.Sprintf("sshkey/%s", sshKeyID)
+	return keyring.Delete(keyringService, account)
+}
+
+// HasSSHPrivateKey checks if a private key exists for an SSH key
+func HasSSHPrivateKey(sshKeyID string) bool {
+	_, err := GetSSHPrivateKey(sshKeyID)
+	return err == nil
+}
+
+// SaveSharedArchiveKey saves the share key issued for an archive
+func SaveSharedArchiveKey(profile, zone, archiveID, sharedAt, sharedKey string) error {
+	account := fmt.Sprintf("sharedarchive/%s/%s/%s/%s", profile, zone, archiveID, sharedAt)
+	return keyring.Set(keyringService, account, sharedKey)
+}
+
+// GetSharedArchiveKey retrieves the share key issued for an archive
+func GetSharedArchiveKey(profile, zone, archiveID, sharedAt string) (string, error) {
+	account := fmt.Sprintf("sharedarchive/%s/%s/%s/%s", profile, zone, archiveID, sharedAt)
+	return keyring.Get(keyringService, account)
+}
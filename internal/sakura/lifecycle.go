@@ -0,0 +1,234 @@
+package sakura
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// サーバー・データベース・NFSの電源操作と削除の種類。
+const (
+	LifecycleActionPowerOn   = "powerOn"
+	LifecycleActionPowerOff  = "powerOff"
+	LifecycleActionForceStop = "forceStop"
+	LifecycleActionReset     = "reset"
+	LifecycleActionDelete    = "delete"
+)
+
+// 状態待ちのタイムアウトの既定値。ディスクやアーカイブのコピーはサイズによって数時間かかる。
+const (
+	defaultPowerWaitTimeout     = 20 * time.Minute
+	defaultProvisionWaitTimeout = 24 * time.Hour
+)
+
+// WaitStateProgress は状態待ちの途中経過。SDKのWaiterがポーリングするたびに通知する。
+type WaitStateProgress struct {
+	ID             string `json:"id"`
+	Availability   string `json:"availability"`
+	InstanceStatus string `json:"instanceStatus,omitempty"` // ディスク・アーカイブでは空
+	ElapsedSec     int    `json:"elapsedSec"`
+}
+
+// resourceLifecycle はサーバー・データベース・NFSに共通する電源操作と削除。
+type resourceLifecycle interface {
+	PowerOn(ctx context.Context, zone string, id string) error
+	PowerOff(ctx context.Context, zone string, id string) error
+	ForceStop(ctx context.Context, zone string, id string) error
+	Reset(ctx context.Context, zone string, id string) error
+	Delete(ctx context.Context, zone string, id string) error
+}
+
+func lifecycleActionFunc(lifecycle resourceLifecycle, action string) (func(ctx context.Context, zone string, id string) error, error) {
+	switch action {
+	case LifecycleActionPowerOn:
+		return lifecycle.PowerOn, nil
+	case LifecycleActionPowerOff:
+		return lifecycle.PowerOff, nil
+	case LifecycleActionForceStop:
+		return lifecycle.ForceStop, nil
+	case LifecycleActionReset:
+		return lifecycle.Reset, nil
+	case LifecycleActionDelete:
+		return lifecycle.Delete, nil
+	default:
+		return nil, fmt.Errorf("不明な操作です: %s", action)
+	}
+}
+
+// stateWaiter はiaas.StateWaiterのうち状態待ちに使うメソッド。
+type stateWaiter interface {
+	AsyncWaitForState(ctx context.Context) (compCh <-chan interface{}, progressCh <-chan interface{}, errCh <-chan error)
+	SetPollingTimeout(d time.Duration)
+}
+
+// powerAndWait は電源操作を行い、起動(powerOn/reset)または停止(powerOff/forceStop)するまで待つ。
+func powerAndWait(ctx context.Context, lifecycle resourceLifecycle, zone string, id string, action string, timeoutSec int, up stateWaiter, down stateWaiter, progress func(WaitStateProgress)) (interface{}, error) {
+	var waiter stateWaiter
+	switch action {
+	case LifecycleActionPowerOn, LifecycleActionReset:
+		waiter = up
+	case LifecycleActionPowerOff, LifecycleActionForceStop:
+		waiter = down
+	default:
+		return nil, fmt.Errorf("状態を待てない操作です: %s", action)
+	}
+	fn, err := lifecycleActionFunc(lifecycle, action)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(ctx, zone, id); err != nil {
+		return nil, err
+	}
+	return waitForState(ctx, waiter, id, waitTimeout(timeoutSec, defaultPowerWaitTimeout), progress)
+}
+
+func waitTimeout(timeoutSec int, defaultTimeout time.Duration) time.Duration {
+	if timeoutSec <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(timeoutSec) * time.Second
+}
+
+// waitForState はwaiterが目的の状態になるまで待ち、その時点のリソースを返す。
+// ctxがキャンセルされた場合はctxのエラーを、timeoutを過ぎた場合はタイムアウトのエラーを返す。
+func waitForState(ctx context.Context, waiter stateWaiter, id string, timeout time.Duration, progress func(WaitStateProgress)) (interface{}, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	waiter.SetPollingTimeout(timeout)
+
+	started := time.Now()
+	report := func(v interface{}) {
+		if progress != nil {
+			progress(waitStateProgressFrom(id, v, time.Since(started)))
+		}
+	}
+
+	compCh, progressCh, errCh := waiter.AsyncWaitForState(waitCtx)
+	for {
+		select {
+		case v := <-compCh:
+			report(v)
+			return v, nil
+		case v := <-progressCh:
+			report(v)
+		case err := <-errCh:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if errors.Is(err, context.DeadlineExceeded) || time.Since(started) >= timeout {
+				return nil, fmt.Errorf("%s の状態待ちが %s でタイムアウトしました: %w", id, timeout, err)
+			}
+			return nil, err
+		}
+	}
+}
+
+func waitStateProgressFrom(id string, v interface{}, elapsed time.Duration) WaitStateProgress {
+	p := WaitStateProgress{ID: id, ElapsedSec: int(elapsed.Seconds())}
+	if a, ok := v.(interface{ GetAvailability() types.EAvailability }); ok {
+		p.Availability = string(a.GetAvailability())
+	}
+	if s, ok := v.(interface {
+		GetInstanceStatus() types.EServerInstanceStatus
+	}); ok {
+		p.InstanceStatus = string(s.GetInstanceStatus())
+	}
+	return p
+}
+
+// PowerAndWait は電源操作を行い、サーバーが起動または停止するまで待つ。timeoutSecが0以下の場合は既定値(20分)。
+func (s *ServerService) PowerAndWait(ctx context.Context, zone string, serverID string, action string, timeoutSec int, progress func(WaitStateProgress)) (*ServerInfo, error) {
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	id := types.StringID(serverID)
+	read := func() (interface{}, error) { return serverOp.Read(ctx, zone, id) }
+
+	v, err := powerAndWait(ctx, s, zone, serverID, action, timeoutSec, iaas.WaiterForUp(read), iaas.WaiterForDown(read), progress)
+	if err != nil {
+		return nil, err
+	}
+	return serverFromSDK(zone, v.(*iaas.Server)), nil
+}
+
+// PowerAndWait は電源操作を行い、データベースが起動または停止するまで待つ。timeoutSecが0以下の場合は既定値(20分)。
+func (s *DatabaseService) PowerAndWait(ctx context.Context, zone string, databaseID string, action string, timeoutSec int, progress func(WaitStateProgress)) (*DatabaseInfo, error) {
+	dbOp := iaas.NewDatabaseOp(s.client.Caller())
+	id := types.StringID(databaseID)
+	read := func() (interface{}, error) { return dbOp.Read(ctx, zone, id) }
+
+	v, err := powerAndWait(ctx, s, zone, databaseID, action, timeoutSec, iaas.WaiterForUp(read), iaas.WaiterForDown(read), progress)
+	if err != nil {
+		return nil, err
+	}
+	info := databaseInfoFromSDK(zone, v.(*iaas.Database))
+	return &info, nil
+}
+
+// PowerAndWait は電源操作を行い、NFSが起動または停止するまで待つ。timeoutSecが0以下の場合は既定値(20分)。
+func (s *NFSService) PowerAndWait(ctx context.Context, zone string, nfsID string, action string, timeoutSec int, progress func(WaitStateProgress)) (*NFSInfo, error) {
+	nfsOp := iaas.NewNFSOp(s.client.Caller())
+	id := types.StringID(nfsID)
+	read := func() (interface{}, error) { return nfsOp.Read(ctx, zone, id) }
+
+	v, err := powerAndWait(ctx, s, zone, nfsID, action, timeoutSec, iaas.WaiterForUp(read), iaas.WaiterForDown(read), progress)
+	if err != nil {
+		return nil, err
+	}
+	return nfsFromSDK(zone, v.(*iaas.NFS)), nil
+}
+
+// PowerAndWait は電源操作を行い、VPCルーターが起動または停止するまで待つ。timeoutSecが0以下の場合は既定値(20分)。
+func (s *VPCRouterService) PowerAndWait(ctx context.Context, zone string, vpcRouterID string, action string, timeoutSec int, progress func(WaitStateProgress)) (*VPCRouterInfo, error) {
+	vpcRouterOp := iaas.NewVPCRouterOp(s.client.Caller())
+	id := types.StringID(vpcRouterID)
+	read := func() (interface{}, error) { return vpcRouterOp.Read(ctx, zone, id) }
+
+	v, err := powerAndWait(ctx, s, zone, vpcRouterID, action, timeoutSec, iaas.WaiterForUp(read), iaas.WaiterForDown(read), progress)
+	if err != nil {
+		return nil, err
+	}
+	return vpcRouterFromSDK(zone, v.(*iaas.VPCRouter)), nil
+}
+
+// PowerAndWait は電源操作を行い、ロードバランサが起動または停止するまで待つ。timeoutSecが0以下の場合は既定値(20分)。
+func (s *LoadBalancerService) PowerAndWait(ctx context.Context, zone string, loadBalancerID string, action string, timeoutSec int, progress func(WaitStateProgress)) (*LoadBalancerInfo, error) {
+	lbOp := iaas.NewLoadBalancerOp(s.client.Caller())
+	id := types.StringID(loadBalancerID)
+	read := func() (interface{}, error) { return lbOp.Read(ctx, zone, id) }
+
+	v, err := powerAndWait(ctx, s, zone, loadBalancerID, action, timeoutSec, iaas.WaiterForUp(read), iaas.WaiterForDown(read), progress)
+	if err != nil {
+		return nil, err
+	}
+	info := convertLoadBalancer(zone, v.(*iaas.LoadBalancer))
+	return &info, nil
+}
+
+// WaitReady はディスクが利用可能(コピー完了)になるまで待つ。timeoutSecが0以下の場合は既定値(24時間)。
+func (s *DiskService) WaitReady(ctx context.Context, zone string, diskID string, timeoutSec int, progress func(WaitStateProgress)) (*DiskInfo, error) {
+	diskOp := iaas.NewDiskOp(s.client.Caller())
+	id := types.StringID(diskID)
+	waiter := iaas.WaiterForReady(func() (interface{}, error) { return diskOp.Read(ctx, zone, id) })
+
+	v, err := waitForState(ctx, waiter, diskID, waitTimeout(timeoutSec, defaultProvisionWaitTimeout), progress)
+	if err != nil {
+		return nil, err
+	}
+	return diskFromSDK(zone, v.(*iaas.Disk)), nil
+}
+
+// WaitReady はアーカイブが利用可能(コピー完了)になるまで待つ。timeoutSecが0以下の場合は既定値(24時間)。
+func (s *ArchiveService) WaitReady(ctx context.Context, zone string, archiveID string, timeoutSec int, progress func(WaitStateProgress)) (*ArchiveInfo, error) {
+	archiveOp := iaas.NewArchiveOp(s.client.Caller())
+	id := types.StringID(archiveID)
+	waiter := iaas.WaiterForReady(func() (interface{}, error) { return archiveOp.Read(ctx, zone, id) })
+
+	v, err := waitForState(ctx, waiter, archiveID, waitTimeout(timeoutSec, defaultProvisionWaitTimeout), progress)
+	if err != nil {
+		return nil, err
+	}
+	return archiveInfoFromSDK(v.(*iaas.Archive)), nil
+}
@@ -0,0 +1,134 @@
+package sakura
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+type testResourceState struct {
+	availability   types.EAvailability
+	instanceStatus types.EServerInstanceStatus
+}
+
+func (s *testResourceState) GetAvailability() types.EAvailability { return s.availability }
+func (s *testResourceState) GetInstanceStatus() types.EServerInstanceStatus {
+	return s.instanceStatus
+}
+
+// testStateWaiter はstatesを順に途中経過として送り、最後の状態で完了する。blockの場合は完了せずctxの終了を待つ。
+type testStateWaiter struct {
+	states  []*testResourceState
+	block   bool
+	timeout time.Duration
+}
+
+func (w *testStateWaiter) SetPollingTimeout(d time.Duration) { w.timeout = d }
+
+func (w *testStateWaiter) AsyncWaitForState(ctx context.Context) (<-chan interface{}, <-chan interface{}, <-chan error) {
+	compCh := make(chan interface{})
+	progressCh := make(chan interface{})
+	errCh := make(chan error)
+	go func() {
+		for i, s := range w.states {
+			if i == len(w.states)-1 && !w.block {
+				compCh <- s
+				return
+			}
+			progressCh <- s
+		}
+		<-ctx.Done()
+		errCh <- ctx.Err()
+	}()
+	return compCh, progressCh, errCh
+}
+
+type testLifecycle struct {
+	called []string
+}
+
+func (l *testLifecycle) record(action string) error {
+	l.called = append(l.called, action)
+	return nil
+}
+func (l *testLifecycle) PowerOn(ctx context.Context, zone string, id string) error {
+	return l.record(LifecycleActionPowerOn)
+}
+func (l *testLifecycle) PowerOff(ctx context.Context, zone string, id string) error {
+	return l.record(LifecycleActionPowerOff)
+}
+func (l *testLifecycle) ForceStop(ctx context.Context, zone string, id string) error {
+	return l.record(LifecycleActionForceStop)
+}
+func (l *testLifecycle) Reset(ctx context.Context, zone string, id string) error {
+	return l.record(LifecycleActionReset)
+}
+func (l *testLifecycle) Delete(ctx context.Context, zone string, id string) error {
+	return l.record(LifecycleActionDelete)
+}
+
+func TestWaitForState_ReportsProgress(t *testing.T) {
+	waiter := &testStateWaiter{states: []*testResourceState{
+		{availability: types.Availabilities.Available, instanceStatus: types.ServerInstanceStatuses.Down},
+		{availability: types.Availabilities.Available, instanceStatus: types.ServerInstanceStatuses.Up},
+	}}
+
+	var got []WaitStateProgress
+	v, err := waitForState(context.Background(), waiter, "123", time.Minute, func(p WaitStateProgress) {
+		got = append(got, p)
+	})
+	if err != nil {
+		t.Fatalf("waitForState: %v", err)
+	}
+	if v != waiter.states[1] {
+		t.Errorf("result = %v, want the final state", v)
+	}
+	if waiter.timeout != time.Minute {
+		t.Errorf("polling timeout = %s, want 1m", waiter.timeout)
+	}
+	if len(got) != 2 || got[0].InstanceStatus != "down" || got[1].InstanceStatus != "up" || got[1].ID != "123" {
+		t.Errorf("progress = %+v", got)
+	}
+}
+
+func TestWaitForState_TimeoutAndCancel(t *testing.T) {
+	waiter := &testStateWaiter{states: []*testResourceState{{availability: types.Availabilities.Migrating}}, block: true}
+	_, err := waitForState(context.Background(), waiter, "123", 20*time.Millisecond, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timeout err = %v, want DeadlineExceeded", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiter = &testStateWaiter{states: []*testResourceState{{availability: types.Availabilities.Migrating}}, block: true}
+	_, err = waitForState(ctx, waiter, "123", time.Minute, func(WaitStateProgress) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancel err = %v, want Canceled", err)
+	}
+}
+
+func TestPowerAndWait_SelectsWaiter(t *testing.T) {
+	up := &testStateWaiter{states: []*testResourceState{{instanceStatus: types.ServerInstanceStatuses.Up}}}
+	down := &testStateWaiter{states: []*testResourceState{{instanceStatus: types.ServerInstanceStatuses.Down}}}
+	lifecycle := &testLifecycle{}
+
+	v, err := powerAndWait(context.Background(), lifecycle, "is1a", "1", LifecycleActionForceStop, 0, up, down, nil)
+	if err != nil {
+		t.Fatalf("powerAndWait: %v", err)
+	}
+	if v != down.states[0] {
+		t.Errorf("forceStop should wait for down, got %v", v)
+	}
+	if down.timeout != defaultPowerWaitTimeout {
+		t.Errorf("timeout = %s, want default", down.timeout)
+	}
+
+	if _, err := powerAndWait(context.Background(), lifecycle, "is1a", "1", LifecycleActionDelete, 0, up, down, nil); err == nil {
+		t.Error("delete should not be accepted")
+	}
+	if len(lifecycle.called) != 1 || lifecycle.called[0] != LifecycleActionForceStop {
+		t.Errorf("called = %v", lifecycle.called)
+	}
+}
@@ -0,0 +1,378 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+type LoadBalancerInfo struct {
+	ID                 string                `json:"id"`
+	Name               string                `json:"name"`
+	Description        string                `json:"description"`
+	Tags               []string              `json:"tags"`
+	Zone               string                `json:"zone"`
+	Plan               string                `json:"plan"`
+	Status             string                `json:"status"`
+	Availability       string                `json:"availability"`
+	SwitchID           string                `json:"switchId"`
+	VRID               int                   `json:"vrid"`
+	IPAddresses        []string              `json:"ipAddresses"`
+	NetworkMaskLen     int                   `json:"networkMaskLen"`
+	DefaultRoute       string                `json:"defaultRoute"`
+	VirtualIPAddresses []LoadBalancerVIPInfo `json:"virtualIpAddresses"`
+	CreatedAt          string                `json:"createdAt"`
+}
+
+type LoadBalancerVIPInfo struct {
+	VirtualIPAddress string                   `json:"virtualIpAddress"`
+	Port             int                      `json:"port"`
+	DelayLoop        int                      `json:"delayLoop"`
+	SorryServer      string                   `json:"sorryServer"`
+	Description      string                   `json:"description"`
+	Servers          []LoadBalancerServerInfo `json:"servers"`
+}
+
+type LoadBalancerServerInfo struct {
+	IPAddress   string                      `json:"ipAddress"`
+	Port        int                         `json:"port"`
+	Enabled     bool                        `json:"enabled"`
+	HealthCheck LoadBalancerHealthCheckInfo `json:"healthCheck"`
+}
+
+type LoadBalancerHealthCheckInfo struct {
+	Protocol     string `json:"protocol"` // "http"/"https"/"ping"/"tcp"
+	Path         string `json:"path"`
+	ResponseCode int    `json:"responseCode"`
+}
+
+type LoadBalancerHealthInfo struct {
+	VirtualIPAddresses []LoadBalancerVIPStatusInfo `json:"virtualIpAddresses"`
+}
+
+type LoadBalancerVIPStatusInfo struct {
+	VirtualIPAddress string                         `json:"virtualIpAddress"`
+	Port             int                            `json:"port"`
+	CPS              int                            `json:"cps"`
+	Servers          []LoadBalancerServerStatusInfo `json:"servers"`
+}
+
+type LoadBalancerServerStatusInfo struct {
+	IPAddress  string `json:"ipAddress"`
+	Port       int    `json:"port"`
+	Status     string `json:"status"`
+	ActiveConn int    `json:"activeConn"`
+	CPS        int    `json:"cps"`
+}
+
+// LoadBalancerCreateInput はロードバランサの作成内容。
+// Planが "standard" の場合はIPAddressesに1つ、"highspec"(冗長化)の場合は2つのアドレスを指定する。
+type LoadBalancerCreateInput struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	Tags           []string `json:"tags"`
+	Plan           string   `json:"plan"`
+	SwitchID       string   `json:"switchId"`
+	VRID           int      `json:"vrid"`
+	IPAddresses    []string `json:"ipAddresses"`
+	NetworkMaskLen int      `json:"networkMaskLen"`
+	DefaultRoute   string   `json:"defaultRoute"`
+}
+
+type LoadBalancerVIPInput struct {
+	VirtualIPAddress string                    `json:"virtualIpAddress"`
+	Port             int                       `json:"port"`
+	DelayLoop        int                       `json:"delayLoop"`
+	SorryServer      string                    `json:"sorryServer"`
+	Description      string                    `json:"description"`
+	Servers          []LoadBalancerServerInput `json:"servers"`
+}
+
+type LoadBalancerServerInput struct {
+	IPAddress   string                      `json:"ipAddress"`
+	Port        int                         `json:"port"`
+	Enabled     bool                        `json:"enabled"`
+	HealthCheck LoadBalancerHealthCheckInfo `json:"healthCheck"`
+}
+
+type LoadBalancerSettingsInput struct {
+	VirtualIPAddresses []LoadBalancerVIPInput `json:"virtualIpAddresses"`
+}
+
+var loadBalancerPlanIDs = map[string]types.ID{
+	"standard": types.LoadBalancerPlans.Standard,
+	"highspec": types.LoadBalancerPlans.HighSpec,
+}
+
+type LoadBalancerService struct {
+	client *Client
+}
+
+func NewLoadBalancerService(client *Client) *LoadBalancerService {
+	return &LoadBalancerService{client: client}
+}
+
+func (s *LoadBalancerService) List(ctx context.Context, zone string) ([]LoadBalancerInfo, error) {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	result, err := op.Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	loadBalancers := make([]LoadBalancerInfo, 0, len(result.LoadBalancers))
+	for _, lb := range result.LoadBalancers {
+		loadBalancers = append(loadBalancers, convertLoadBalancer(zone, lb))
+	}
+	return loadBalancers, nil
+}
+
+func (s *LoadBalancerService) Get(ctx context.Context, zone string, id string) (*LoadBalancerInfo, error) {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	result, err := op.Read(ctx, zone, types.StringID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	info := convertLoadBalancer(zone, result)
+	return &info, nil
+}
+
+func (s *LoadBalancerService) Delete(ctx context.Context, zone string, id string) error {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	return op.Delete(ctx, zone, types.StringID(id))
+}
+
+// Create はロードバランサを新規作成する。VIP・実サーバーは作成後、UpdateSettingsで設定する。
+func (s *LoadBalancerService) Create(ctx context.Context, zone string, input LoadBalancerCreateInput) (*LoadBalancerInfo, error) {
+	planID, ok := loadBalancerPlanIDs[input.Plan]
+	if !ok {
+		return nil, fmt.Errorf("不明なプランです: %s", input.Plan)
+	}
+	wantAddresses := 1
+	if input.Plan == "highspec" {
+		wantAddresses = 2
+	}
+	if len(input.IPAddresses) != wantAddresses {
+		return nil, fmt.Errorf("%sプランではIPアドレスを%d個指定してください", input.Plan, wantAddresses)
+	}
+	if input.SwitchID == "" || input.NetworkMaskLen == 0 {
+		return nil, fmt.Errorf("接続するスイッチとネットマスクを指定してください")
+	}
+	vrid := input.VRID
+	if vrid == 0 {
+		vrid = 1
+	}
+
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	result, err := op.Create(ctx, zone, &iaas.LoadBalancerCreateRequest{
+		SwitchID:       types.StringID(input.SwitchID),
+		PlanID:         planID,
+		VRID:           vrid,
+		IPAddresses:    input.IPAddresses,
+		NetworkMaskLen: input.NetworkMaskLen,
+		DefaultRoute:   input.DefaultRoute,
+		Name:           input.Name,
+		Description:    input.Description,
+		Tags:           input.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := convertLoadBalancer(zone, result)
+	return &info, nil
+}
+
+// Update はロードバランサの名前・説明・タグを更新する。VIPの設定は既存のものを維持したまま送信する。
+func (s *LoadBalancerService) Update(ctx context.Context, zone string, id, name, description string, tags []string) (*LoadBalancerInfo, error) {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	lbID := types.StringID(id)
+	current, err := op.Read(ctx, zone, lbID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := op.Update(ctx, zone, lbID, &iaas.LoadBalancerUpdateRequest{
+		Name:               name,
+		Description:        description,
+		Tags:               tags,
+		IconID:             current.IconID,
+		VirtualIPAddresses: current.VirtualIPAddresses,
+		SettingsHash:       current.SettingsHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := convertLoadBalancer(zone, result)
+	return &info, nil
+}
+
+// UpdateSettings はVIPと実サーバーの設定を置き換え、ロードバランサに反映する。
+func (s *LoadBalancerService) UpdateSettings(ctx context.Context, zone string, id string, input LoadBalancerSettingsInput) (*LoadBalancerInfo, error) {
+	vips, err := loadBalancerVIPsToSDK(input.VirtualIPAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	lbID := types.StringID(id)
+	current, err := op.Read(ctx, zone, lbID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := op.UpdateSettings(ctx, zone, lbID, &iaas.LoadBalancerUpdateSettingsRequest{
+		VirtualIPAddresses: vips,
+		SettingsHash:       current.SettingsHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := op.Config(ctx, zone, lbID); err != nil {
+		return nil, fmt.Errorf("設定を保存しましたが、ロードバランサへの反映に失敗しました: %w", err)
+	}
+	info := convertLoadBalancer(zone, result)
+	return &info, nil
+}
+
+func loadBalancerVIPsToSDK(input []LoadBalancerVIPInput) (iaas.LoadBalancerVirtualIPAddresses, error) {
+	vips := make(iaas.LoadBalancerVirtualIPAddresses, 0, len(input))
+	for _, vip := range input {
+		if vip.VirtualIPAddress == "" || !validPort(vip.Port) {
+			return nil, fmt.Errorf("VIPのアドレスとポート(1〜65535)を指定してください")
+		}
+		servers := make(iaas.LoadBalancerServers, 0, len(vip.Servers))
+		for _, srv := range vip.Servers {
+			if srv.IPAddress == "" || !validPort(srv.Port) {
+				return nil, fmt.Errorf("VIP %s:%d: 実サーバーのアドレスとポート(1〜65535)を指定してください", vip.VirtualIPAddress, vip.Port)
+			}
+			servers = append(servers, &iaas.LoadBalancerServer{
+				IPAddress: srv.IPAddress,
+				Port:      types.StringNumber(srv.Port),
+				Enabled:   types.StringFlag(srv.Enabled),
+				HealthCheck: &iaas.LoadBalancerServerHealthCheck{
+					Protocol:     types.ELoadBalancerHealthCheckProtocol(srv.HealthCheck.Protocol),
+					Path:         srv.HealthCheck.Path,
+					ResponseCode: types.StringNumber(srv.HealthCheck.ResponseCode),
+				},
+			})
+		}
+		delayLoop := vip.DelayLoop
+		if delayLoop == 0 {
+			delayLoop = 10
+		}
+		vips = append(vips, &iaas.LoadBalancerVirtualIPAddress{
+			VirtualIPAddress: vip.VirtualIPAddress,
+			Port:             types.StringNumber(vip.Port),
+			DelayLoop:        types.StringNumber(delayLoop),
+			SorryServer:      vip.SorryServer,
+			Description:      vip.Description,
+			Servers:          servers,
+		})
+	}
+	return vips, nil
+}
+
+func (s *LoadBalancerService) PowerOn(ctx context.Context, zone string, id string) error {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	return op.Boot(ctx, zone, types.StringID(id))
+}
+
+func (s *LoadBalancerService) PowerOff(ctx context.Context, zone string, id string) error {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	return op.Shutdown(ctx, zone, types.StringID(id), &iaas.ShutdownOption{Force: false})
+}
+
+func (s *LoadBalancerService) ForceStop(ctx context.Context, zone string, id string) error {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	return op.Shutdown(ctx, zone, types.StringID(id), &iaas.ShutdownOption{Force: true})
+}
+
+func (s *LoadBalancerService) Reset(ctx context.Context, zone string, id string) error {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	return op.Reset(ctx, zone, types.StringID(id))
+}
+
+// GetHealth はVIPごとの実サーバーのヘルスチェック結果と接続数を返す。
+func (s *LoadBalancerService) GetHealth(ctx context.Context, zone string, id string) (*LoadBalancerHealthInfo, error) {
+	op := iaas.NewLoadBalancerOp(s.client.Caller())
+	result, err := op.Status(ctx, zone, types.StringID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	vips := make([]LoadBalancerVIPStatusInfo, 0, len(result.Status))
+	for _, st := range result.Status {
+		servers := make([]LoadBalancerServerStatusInfo, 0, len(st.Servers))
+		for _, srv := range st.Servers {
+			servers = append(servers, LoadBalancerServerStatusInfo{
+				IPAddress:  srv.IPAddress,
+				Port:       srv.Port.Int(),
+				Status:     string(srv.Status),
+				ActiveConn: srv.ActiveConn.Int(),
+				CPS:        srv.CPS.Int(),
+			})
+		}
+		vips = append(vips, LoadBalancerVIPStatusInfo{
+			VirtualIPAddress: st.VirtualIPAddress,
+			Port:             st.Port.Int(),
+			CPS:              st.CPS.Int(),
+			Servers:          servers,
+		})
+	}
+	return &LoadBalancerHealthInfo{VirtualIPAddresses: vips}, nil
+}
+
+func convertLoadBalancer(zone string, lb *iaas.LoadBalancer) LoadBalancerInfo {
+	vips := make([]LoadBalancerVIPInfo, 0, len(lb.VirtualIPAddresses))
+	for _, vip := range lb.VirtualIPAddresses {
+		servers := make([]LoadBalancerServerInfo, 0, len(vip.Servers))
+		for _, srv := range vip.Servers {
+			info := LoadBalancerServerInfo{
+				IPAddress: srv.IPAddress,
+				Port:      srv.Port.Int(),
+				Enabled:   srv.Enabled.Bool(),
+			}
+			if srv.HealthCheck != nil {
+				info.HealthCheck = LoadBalancerHealthCheckInfo{
+					Protocol:     string(srv.HealthCheck.Protocol),
+					Path:         srv.HealthCheck.Path,
+					ResponseCode: srv.HealthCheck.ResponseCode.Int(),
+				}
+			}
+			servers = append(servers, info)
+		}
+		vips = append(vips, LoadBalancerVIPInfo{
+			VirtualIPAddress: vip.VirtualIPAddress,
+			Port:             vip.Port.Int(),
+			DelayLoop:        vip.DelayLoop.Int(),
+			SorryServer:      vip.SorryServer,
+			Description:      vip.Description,
+			Servers:          servers,
+		})
+	}
+
+	plan := lb.PlanID.String()
+	for name, id := range loadBalancerPlanIDs {
+		if id == lb.PlanID {
+			plan = name
+		}
+	}
+
+	return LoadBalancerInfo{
+		ID:                 lb.ID.String(),
+		Name:               lb.Name,
+		Description:        lb.Description,
+		Tags:               lb.Tags,
+		Zone:               zone,
+		Plan:               plan,
+		Status:             string(lb.InstanceStatus),
+		Availability:       string(lb.Availability),
+		SwitchID:           lb.SwitchID.String(),
+		VRID:               lb.VRID,
+		IPAddresses:        lb.IPAddresses,
+		NetworkMaskLen:     lb.NetworkMaskLen,
+		DefaultRoute:       lb.DefaultRoute,
+		VirtualIPAddresses: vips,
+		CreatedAt:          lb.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
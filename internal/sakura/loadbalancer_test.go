@@ -0,0 +1,84 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+)
+
+func newTestLoadBalancerService(t *testing.T) *LoadBalancerService {
+	t.Helper()
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	return NewLoadBalancerService(&Client{})
+}
+
+func createTestLoadBalancer(ctx context.Context, service *LoadBalancerService) (*LoadBalancerInfo, error) {
+	sw, err := iaas.NewSwitchOp(nil).Create(ctx, "is1a", &iaas.SwitchCreateRequest{Name: "lb-switch"})
+	if err != nil {
+		return nil, err
+	}
+	return service.Create(ctx, "is1a", LoadBalancerCreateInput{
+		Name:           "test-lb",
+		Plan:           "standard",
+		SwitchID:       sw.ID.String(),
+		IPAddresses:    []string{"192.168.0.11"},
+		NetworkMaskLen: 24,
+		DefaultRoute:   "192.168.0.1",
+	})
+}
+
+func TestLoadBalancerService_Create(t *testing.T) {
+	service := newTestLoadBalancerService(t)
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, "is1a", LoadBalancerCreateInput{Name: "lb", Plan: "highspec", SwitchID: "1", IPAddresses: []string{"192.168.0.11"}, NetworkMaskLen: 24}); err == nil {
+		t.Error("Create with highspec plan and a single address should fail")
+	}
+	created, err := createTestLoadBalancer(ctx, service)
+	if err != nil {
+		t.Fatalf("createTestLoadBalancer: %v", err)
+	}
+	if created.Plan != "standard" || created.VRID != 1 {
+		t.Errorf("created = %+v", created)
+	}
+}
+
+func TestLoadBalancerService_UpdateSettings(t *testing.T) {
+	service := newTestLoadBalancerService(t)
+	ctx := context.Background()
+
+	created, err := createTestLoadBalancer(ctx, service)
+	if err != nil {
+		t.Fatalf("createTestLoadBalancer: %v", err)
+	}
+
+	input := LoadBalancerSettingsInput{VirtualIPAddresses: []LoadBalancerVIPInput{{
+		VirtualIPAddress: "192.168.0.101",
+		Port:             80,
+		Servers: []LoadBalancerServerInput{{
+			IPAddress:   "192.168.0.21",
+			Port:        80,
+			Enabled:     true,
+			HealthCheck: LoadBalancerHealthCheckInfo{Protocol: "http", Path: "/", ResponseCode: 200},
+		}},
+	}}}
+	updated, err := service.UpdateSettings(ctx, "is1a", created.ID, input)
+	if err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+	if len(updated.VirtualIPAddresses) != 1 {
+		t.Fatalf("VirtualIPAddresses = %+v", updated.VirtualIPAddresses)
+	}
+	vip := updated.VirtualIPAddresses[0]
+	if vip.DelayLoop != 10 || len(vip.Servers) != 1 || vip.Servers[0].HealthCheck.Path != "/" {
+		t.Errorf("vip = %+v", vip)
+	}
+
+	input.VirtualIPAddresses[0].Servers[0].Port = 0
+	if _, err := service.UpdateSettings(ctx, "is1a", created.ID, input); err == nil {
+		t.Error("UpdateSettings with an invalid real server port should fail")
+	}
+}
@@ -0,0 +1,332 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+type LocalRouterInfo struct {
+	ID           string                    `json:"id"`
+	Name         string                    `json:"name"`
+	Description  string                    `json:"description"`
+	Tags         []string                  `json:"tags"`
+	Availability string                    `json:"availability"`
+	Switch       *LocalRouterSwitchInfo    `json:"switch"`
+	Interface    *LocalRouterInterfaceInfo `json:"interface"`
+	Peers        []LocalRouterPeerInfo     `json:"peers"`
+	StaticRoutes []LocalRouterStaticRoute  `json:"staticRoutes"`
+	SecretKeys   []string                  `json:"secretKeys"`
+	CreatedAt    string                    `json:"createdAt"`
+	ModifiedAt   string                    `json:"modifiedAt"`
+}
+
+// LocalRouterSwitchInfo はローカルルーターを接続するスイッチ。Zoneはスイッチのゾーン名。
+type LocalRouterSwitchInfo struct {
+	SwitchID string `json:"switchId"`
+	Zone     string `json:"zone"`
+}
+
+type LocalRouterInterfaceInfo struct {
+	VirtualIPAddress string   `json:"virtualIpAddress"`
+	IPAddresses      []string `json:"ipAddresses"`
+	NetworkMaskLen   int      `json:"networkMaskLen"`
+	VRID             int      `json:"vrid"`
+}
+
+// LocalRouterPeerInfo は接続先のローカルルーター。SecretKeyは接続先が発行したシークレットキー。
+type LocalRouterPeerInfo struct {
+	ID          string `json:"id"`
+	SecretKey   string `json:"secretKey"`
+	Enabled     bool   `json:"enabled"`
+	Description string `json:"description"`
+}
+
+type LocalRouterStaticRoute struct {
+	Prefix  string `json:"prefix"`
+	NextHop string `json:"nextHop"`
+}
+
+// LocalRouterSettingsInput はローカルルーターのスイッチ・インターフェース・ピア・スタティックルートの設定一式。
+type LocalRouterSettingsInput struct {
+	Switch       LocalRouterSwitchInfo    `json:"switch"`
+	Interface    LocalRouterInterfaceInfo `json:"interface"`
+	Peers        []LocalRouterPeerInfo    `json:"peers"`
+	StaticRoutes []LocalRouterStaticRoute `json:"staticRoutes"`
+}
+
+type LocalRouterHealthInfo struct {
+	Peers []LocalRouterPeerHealthInfo `json:"peers"`
+}
+
+type LocalRouterPeerHealthInfo struct {
+	ID     string   `json:"id"`
+	Status string   `json:"status"`
+	Routes []string `json:"routes"`
+}
+
+// LocalRouterTrafficValueInfo はローカルルーターのトラフィックグラフの1点。
+type LocalRouterTrafficValueInfo struct {
+	Time               string  `json:"time"`
+	ReceiveBytesPerSec float64 `json:"receiveBytesPerSec"`
+	SendBytesPerSec    float64 `json:"sendBytesPerSec"`
+}
+
+type LocalRouterService struct {
+	client *Client
+}
+
+func NewLocalRouterService(client *Client) *LocalRouterService {
+	return &LocalRouterService{client: client}
+}
+
+func (s *LocalRouterService) List(ctx context.Context) ([]LocalRouterInfo, error) {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	result, err := op.Find(ctx, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]LocalRouterInfo, 0, len(result.LocalRouters))
+	for _, lr := range result.LocalRouters {
+		list = append(list, convertLocalRouter(lr))
+	}
+	return list, nil
+}
+
+func (s *LocalRouterService) Get(ctx context.Context, id string) (*LocalRouterInfo, error) {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	result, err := op.Read(ctx, types.StringID(id))
+	if err != nil {
+		return nil, err
+	}
+	info := convertLocalRouter(result)
+	return &info, nil
+}
+
+// Create はローカルルーターを新規作成する。スイッチ・ピア等の設定は作成後、UpdateSettingsで行う。
+func (s *LocalRouterService) Create(ctx context.Context, name, description string, tags []string) (*LocalRouterInfo, error) {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	result, err := op.Create(ctx, &iaas.LocalRouterCreateRequest{
+		Name:        name,
+		Description: description,
+		Tags:        tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := convertLocalRouter(result)
+	return &info, nil
+}
+
+// Update はローカルルーターの名前・説明・タグを更新する。設定は既存のものを維持したまま送信する。
+func (s *LocalRouterService) Update(ctx context.Context, id, name, description string, tags []string) (*LocalRouterInfo, error) {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	lrID := types.StringID(id)
+	current, err := op.Read(ctx, lrID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := op.Update(ctx, lrID, &iaas.LocalRouterUpdateRequest{
+		Name:         name,
+		Description:  description,
+		Tags:         tags,
+		IconID:       current.IconID,
+		Switch:       current.Switch,
+		Interface:    current.Interface,
+		Peers:        current.Peers,
+		StaticRoutes: current.StaticRoutes,
+		SettingsHash: current.SettingsHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := convertLocalRouter(result)
+	return &info, nil
+}
+
+// UpdateSettings は接続するスイッチ・インターフェース・ピア・スタティックルートを置き換える。
+func (s *LocalRouterService) UpdateSettings(ctx context.Context, id string, input LocalRouterSettingsInput) (*LocalRouterInfo, error) {
+	if err := input.validate(id); err != nil {
+		return nil, err
+	}
+
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	lrID := types.StringID(id)
+	current, err := op.Read(ctx, lrID)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]*iaas.LocalRouterPeer, 0, len(input.Peers))
+	for _, p := range input.Peers {
+		peers = append(peers, &iaas.LocalRouterPeer{
+			ID:          types.StringID(p.ID),
+			SecretKey:   p.SecretKey,
+			Enabled:     p.Enabled,
+			Description: p.Description,
+		})
+	}
+	staticRoutes := make([]*iaas.LocalRouterStaticRoute, 0, len(input.StaticRoutes))
+	for _, r := range input.StaticRoutes {
+		staticRoutes = append(staticRoutes, &iaas.LocalRouterStaticRoute{Prefix: r.Prefix, NextHop: r.NextHop})
+	}
+
+	result, err := op.UpdateSettings(ctx, lrID, &iaas.LocalRouterUpdateSettingsRequest{
+		Switch: &iaas.LocalRouterSwitch{
+			Code:     input.Switch.SwitchID,
+			Category: "cloud",
+			ZoneID:   input.Switch.Zone,
+		},
+		Interface: &iaas.LocalRouterInterface{
+			VirtualIPAddress: input.Interface.VirtualIPAddress,
+			IPAddress:        input.Interface.IPAddresses,
+			NetworkMaskLen:   input.Interface.NetworkMaskLen,
+			VRID:             input.Interface.VRID,
+		},
+		Peers:        peers,
+		StaticRoutes: staticRoutes,
+		SettingsHash: current.SettingsHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := convertLocalRouter(result)
+	return &info, nil
+}
+
+func (in *LocalRouterSettingsInput) validate(id string) error {
+	if in.Switch.SwitchID == "" || in.Switch.Zone == "" {
+		return fmt.Errorf("接続するスイッチとゾーンを指定してください")
+	}
+	if in.Interface.VirtualIPAddress == "" || len(in.Interface.IPAddresses) != 2 || in.Interface.NetworkMaskLen == 0 {
+		return fmt.Errorf("仮想IPアドレス・2つの実IPアドレス・ネットマスクを指定してください")
+	}
+	for _, p := range in.Peers {
+		if p.ID == id {
+			return fmt.Errorf("自身をピアに指定することはできません")
+		}
+		if p.SecretKey == "" {
+			return fmt.Errorf("ピア %s のシークレットキーを指定してください", p.ID)
+		}
+	}
+	for _, r := range in.StaticRoutes {
+		if r.Prefix == "" || r.NextHop == "" {
+			return fmt.Errorf("スタティックルートのプレフィックスとネクストホップを指定してください")
+		}
+	}
+	return nil
+}
+
+// PeerSecretKey はピアとして接続するローカルルーターのシークレットキーを返す。
+// ピアの追加では、接続先のIDとこのキーを UpdateSettings の Peers に指定する。
+func (s *LocalRouterService) PeerSecretKey(ctx context.Context, peerID string) (string, error) {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	peer, err := op.Read(ctx, types.StringID(peerID))
+	if err != nil {
+		return "", err
+	}
+	if len(peer.SecretKeys) == 0 {
+		return "", fmt.Errorf("ローカルルーター %s のシークレットキーがありません。スイッチを接続してから再度お試しください", peer.Name)
+	}
+	return peer.SecretKeys[0], nil
+}
+
+func (s *LocalRouterService) Delete(ctx context.Context, id string) error {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	return op.Delete(ctx, types.StringID(id))
+}
+
+// GetHealth はピアごとの接続状態と、ピアから受け取っている経路を返す。
+func (s *LocalRouterService) GetHealth(ctx context.Context, id string) (*LocalRouterHealthInfo, error) {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	health, err := op.HealthStatus(ctx, types.StringID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]LocalRouterPeerHealthInfo, 0, len(health.Peers))
+	for _, p := range health.Peers {
+		peers = append(peers, LocalRouterPeerHealthInfo{
+			ID:     p.ID.String(),
+			Status: string(p.Status),
+			Routes: p.Routes,
+		})
+	}
+	return &LocalRouterHealthInfo{Peers: peers}, nil
+}
+
+// MonitorTraffic はローカルルーターの送受信トラフィックのグラフ用データを取得する。
+func (s *LocalRouterService) MonitorTraffic(ctx context.Context, id string, start, end int64) ([]LocalRouterTrafficValueInfo, error) {
+	op := iaas.NewLocalRouterOp(s.client.Caller())
+	activity, err := op.MonitorLocalRouter(ctx, types.StringID(id), &iaas.MonitorCondition{
+		Start: time.Unix(start, 0),
+		End:   time.Unix(end, 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil {
+		return nil, nil
+	}
+
+	values := make([]LocalRouterTrafficValueInfo, 0, len(activity.Values))
+	for _, v := range activity.Values {
+		values = append(values, LocalRouterTrafficValueInfo{
+			Time:               v.Time.Format("2006-01-02T15:04:05Z07:00"),
+			ReceiveBytesPerSec: v.ReceiveBytesPerSec,
+			SendBytesPerSec:    v.SendBytesPerSec,
+		})
+	}
+	return values, nil
+}
+
+func convertLocalRouter(lr *iaas.LocalRouter) LocalRouterInfo {
+	peers := make([]LocalRouterPeerInfo, 0, len(lr.Peers))
+	for _, p := range lr.Peers {
+		peers = append(peers, LocalRouterPeerInfo{
+			ID:          p.ID.String(),
+			SecretKey:   p.SecretKey,
+			Enabled:     p.Enabled,
+			Description: p.Description,
+		})
+	}
+
+	staticRoutes := make([]LocalRouterStaticRoute, 0, len(lr.StaticRoutes))
+	for _, r := range lr.StaticRoutes {
+		staticRoutes = append(staticRoutes, LocalRouterStaticRoute{Prefix: r.Prefix, NextHop: r.NextHop})
+	}
+
+	var sw *LocalRouterSwitchInfo
+	if lr.Switch != nil {
+		sw = &LocalRouterSwitchInfo{SwitchID: lr.Switch.Code, Zone: lr.Switch.ZoneID}
+	}
+
+	var iface *LocalRouterInterfaceInfo
+	if lr.Interface != nil {
+		iface = &LocalRouterInterfaceInfo{
+			VirtualIPAddress: lr.Interface.VirtualIPAddress,
+			IPAddresses:      lr.Interface.IPAddress,
+			NetworkMaskLen:   lr.Interface.NetworkMaskLen,
+			VRID:             lr.Interface.VRID,
+		}
+	}
+
+	return LocalRouterInfo{
+		ID:           lr.ID.String(),
+		Name:         lr.Name,
+		Description:  lr.Description,
+		Tags:         lr.Tags,
+		Availability: string(lr.Availability),
+		Switch:       sw,
+		Interface:    iface,
+		Peers:        peers,
+		StaticRoutes: staticRoutes,
+		SecretKeys:   lr.SecretKeys,
+		CreatedAt:    lr.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ModifiedAt:   lr.ModifiedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,75 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+)
+
+func TestLocalRouterService_UpdateSettings(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	service := NewLocalRouterService(&Client{})
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "local-router", "", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	input := LocalRouterSettingsInput{
+		Switch: LocalRouterSwitchInfo{SwitchID: "113100000001", Zone: "is1a"},
+		Interface: LocalRouterInterfaceInfo{
+			VirtualIPAddress: "192.168.0.1",
+			IPAddresses:      []string{"192.168.0.2", "192.168.0.3"},
+			NetworkMaskLen:   24,
+			VRID:             100,
+		},
+		StaticRoutes: []LocalRouterStaticRoute{{Prefix: "10.0.0.0/24", NextHop: "192.168.0.10"}},
+	}
+	updated, err := service.UpdateSettings(ctx, created.ID, input)
+	if err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+	if updated.Switch == nil || updated.Switch.SwitchID != "113100000001" || updated.Switch.Zone != "is1a" {
+		t.Errorf("Switch = %+v", updated.Switch)
+	}
+	if len(updated.StaticRoutes) != 1 || updated.StaticRoutes[0].NextHop != "192.168.0.10" {
+		t.Errorf("StaticRoutes = %+v", updated.StaticRoutes)
+	}
+}
+
+func TestLocalRouterSettingsInput_Validate(t *testing.T) {
+	valid := LocalRouterSettingsInput{
+		Switch: LocalRouterSwitchInfo{SwitchID: "113100000001", Zone: "is1a"},
+		Interface: LocalRouterInterfaceInfo{
+			VirtualIPAddress: "192.168.0.1",
+			IPAddresses:      []string{"192.168.0.2", "192.168.0.3"},
+			NetworkMaskLen:   24,
+		},
+	}
+	tests := []struct {
+		name    string
+		modify  func(in *LocalRouterSettingsInput)
+		wantErr bool
+	}{
+		{"valid", func(in *LocalRouterSettingsInput) {}, false},
+		{"no switch", func(in *LocalRouterSettingsInput) { in.Switch = LocalRouterSwitchInfo{} }, true},
+		{"single address", func(in *LocalRouterSettingsInput) { in.Interface.IPAddresses = []string{"192.168.0.2"} }, true},
+		{"peer without key", func(in *LocalRouterSettingsInput) { in.Peers = []LocalRouterPeerInfo{{ID: "2"}} }, true},
+		{"self peer", func(in *LocalRouterSettingsInput) { in.Peers = []LocalRouterPeerInfo{{ID: "1", SecretKey: "key"}} }, true},
+		{"incomplete route", func(in *LocalRouterSettingsInput) {
+			in.StaticRoutes = []LocalRouterStaticRoute{{Prefix: "10.0.0.0/24"}}
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid
+			in.Interface.IPAddresses = append([]string(nil), valid.Interface.IPAddresses...)
+			tt.modify(&in)
+			if err := in.validate("1"); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
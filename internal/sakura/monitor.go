@@ -0,0 +1,177 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// アクティビティモニタの集約方法。APIは5分間隔の値を返すため、長い期間を表示する際はinterval秒ごとに集約する。
+const (
+	MonitorAggregationAverage = "avg"
+	MonitorAggregationMax     = "max"
+)
+
+// ServerCPUTimeValueInfo はサーバーのCPU時間の時系列の1点。
+type ServerCPUTimeValueInfo struct {
+	Time    string  `json:"time"`
+	CPUTime float64 `json:"cpuTime"`
+}
+
+// InterfaceActivityValueInfo はNICの送受信量(bps)の時系列の1点。
+type InterfaceActivityValueInfo struct {
+	Time    string  `json:"time"`
+	Send    float64 `json:"send"`
+	Receive float64 `json:"receive"`
+}
+
+// DiskActivityValueInfo はディスクの読み書き量(Bps)の時系列の1点。
+type DiskActivityValueInfo struct {
+	Time  string  `json:"time"`
+	Read  float64 `json:"read"`
+	Write float64 `json:"write"`
+}
+
+// monitorPoint は種類の異なるアクティビティを同じ方法で集約するための共通表現。
+type monitorPoint struct {
+	time   time.Time
+	values []float64
+}
+
+func monitorCondition(start, end int64) *iaas.MonitorCondition {
+	return &iaas.MonitorCondition{
+		Start: time.Unix(start, 0),
+		End:   time.Unix(end, 0),
+	}
+}
+
+// aggregateMonitorPoints はintervalSec秒ごとの区間に値をまとめる。intervalSecが0以下なら元の値をそのまま返す。
+// 区間の時刻は区間の開始時刻(UNIX時間で割り切れる時刻)になる。
+func aggregateMonitorPoints(points []monitorPoint, intervalSec int64, aggregation string) ([]monitorPoint, error) {
+	if intervalSec <= 0 {
+		return points, nil
+	}
+	if aggregation == "" {
+		aggregation = MonitorAggregationAverage
+	}
+	if aggregation != MonitorAggregationAverage && aggregation != MonitorAggregationMax {
+		return nil, fmt.Errorf("不明な集約方法です: %s", aggregation)
+	}
+
+	result := make([]monitorPoint, 0, len(points))
+	var counts []int
+	for _, p := range points {
+		// time.TruncateはGoのゼロ時刻(西暦1年)基準で丸めるため、UNIX時間で区間を揃える
+		bucket := time.Unix(p.time.Unix()/intervalSec*intervalSec, 0).In(p.time.Location())
+		last := len(result) - 1
+		if last < 0 || !result[last].time.Equal(bucket) {
+			result = append(result, monitorPoint{time: bucket, values: append([]float64(nil), p.values...)})
+			counts = append(counts, 1)
+			continue
+		}
+		for i, v := range p.values {
+			if aggregation == MonitorAggregationMax {
+				result[last].values[i] = max(result[last].values[i], v)
+			} else {
+				result[last].values[i] += v
+			}
+		}
+		counts[last]++
+	}
+	if aggregation == MonitorAggregationAverage {
+		for i := range result {
+			for j := range result[i].values {
+				result[i].values[j] /= float64(counts[i])
+			}
+		}
+	}
+	return result, nil
+}
+
+func formatMonitorTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05Z07:00")
+}
+
+// MonitorCPU はサーバーのCPU時間のグラフ用データを取得する。
+func (s *ServerService) MonitorCPU(ctx context.Context, zone string, serverID string, start, end int64, intervalSec int64, aggregation string) ([]ServerCPUTimeValueInfo, error) {
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	activity, err := serverOp.Monitor(ctx, zone, types.StringID(serverID), monitorCondition(start, end))
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil {
+		return nil, nil
+	}
+
+	points := make([]monitorPoint, 0, len(activity.Values))
+	for _, v := range activity.Values {
+		points = append(points, monitorPoint{time: v.Time, values: []float64{v.CPUTime}})
+	}
+	points, err = aggregateMonitorPoints(points, intervalSec, aggregation)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]ServerCPUTimeValueInfo, 0, len(points))
+	for _, p := range points {
+		values = append(values, ServerCPUTimeValueInfo{Time: formatMonitorTime(p.time), CPUTime: p.values[0]})
+	}
+	return values, nil
+}
+
+// Monitor はNICの送受信量のグラフ用データを取得する。
+func (s *InterfaceService) Monitor(ctx context.Context, zone string, interfaceID string, start, end int64, intervalSec int64, aggregation string) ([]InterfaceActivityValueInfo, error) {
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	activity, err := interfaceOp.Monitor(ctx, zone, types.StringID(interfaceID), monitorCondition(start, end))
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil {
+		return nil, nil
+	}
+
+	points := make([]monitorPoint, 0, len(activity.Values))
+	for _, v := range activity.Values {
+		points = append(points, monitorPoint{time: v.Time, values: []float64{v.Send, v.Receive}})
+	}
+	points, err = aggregateMonitorPoints(points, intervalSec, aggregation)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]InterfaceActivityValueInfo, 0, len(points))
+	for _, p := range points {
+		values = append(values, InterfaceActivityValueInfo{Time: formatMonitorTime(p.time), Send: p.values[0], Receive: p.values[1]})
+	}
+	return values, nil
+}
+
+// Monitor はディスクの読み書き量のグラフ用データを取得する。
+func (s *DiskService) Monitor(ctx context.Context, zone string, diskID string, start, end int64, intervalSec int64, aggregation string) ([]DiskActivityValueInfo, error) {
+	diskOp := iaas.NewDiskOp(s.client.Caller())
+	activity, err := diskOp.Monitor(ctx, zone, types.StringID(diskID), monitorCondition(start, end))
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil {
+		return nil, nil
+	}
+
+	points := make([]monitorPoint, 0, len(activity.Values))
+	for _, v := range activity.Values {
+		points = append(points, monitorPoint{time: v.Time, values: []float64{v.Read, v.Write}})
+	}
+	points, err = aggregateMonitorPoints(points, intervalSec, aggregation)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]DiskActivityValueInfo, 0, len(points))
+	for _, p := range points {
+		values = append(values, DiskActivityValueInfo{Time: formatMonitorTime(p.time), Read: p.values[0], Write: p.values[1]})
+	}
+	return values, nil
+}
@@ -0,0 +1,91 @@
+package sakura
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestServerService_MonitorCPU(t *testing.T) {
+	service := newTestServerService(t)
+	ctx := context.Background()
+
+	created, err := createTestServer(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+
+	values, err := service.MonitorCPU(ctx, "is1a", created.ID.String(), 0, 0, 0, "")
+	if err != nil {
+		t.Fatalf("MonitorCPU: %v", err)
+	}
+	if values == nil {
+		t.Error("MonitorCPU: got nil values, want non-nil (possibly empty) slice")
+	}
+}
+
+func TestAggregateMonitorPoints(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	points := []monitorPoint{
+		{time: base, values: []float64{1, 10}},
+		{time: base.Add(5 * time.Minute), values: []float64{3, 20}},
+		{time: base.Add(10 * time.Minute), values: []float64{2, 60}},
+		{time: base.Add(15 * time.Minute), values: []float64{4, 0}},
+	}
+
+	t.Run("raw", func(t *testing.T) {
+		got, err := aggregateMonitorPoints(points, 0, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(points) {
+			t.Errorf("len = %d, want %d", len(got), len(points))
+		}
+	})
+
+	t.Run("avg", func(t *testing.T) {
+		got, err := aggregateMonitorPoints(points, 600, MonitorAggregationAverage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []monitorPoint{
+			{time: base, values: []float64{2, 15}},
+			{time: base.Add(10 * time.Minute), values: []float64{3, 30}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("max", func(t *testing.T) {
+		got, err := aggregateMonitorPoints(points, 600, MonitorAggregationMax)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []monitorPoint{
+			{time: base, values: []float64{3, 20}},
+			{time: base.Add(10 * time.Minute), values: []float64{4, 60}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("aligned to unix time", func(t *testing.T) {
+		// 1週間の区間は、Goのゼロ時刻基準なら月曜始まり(9/28)、UNIX時間基準なら木曜始まり(10/1)になる
+		got, err := aggregateMonitorPoints(points, 7*24*60*60, MonitorAggregationMax)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || !got[0].time.Equal(base) {
+			t.Errorf("got %+v, want a single bucket starting at %v", got, base)
+		}
+	})
+
+	t.Run("unknown aggregation", func(t *testing.T) {
+		if _, err := aggregateMonitorPoints(points, 600, "sum"); err == nil {
+			t.Error("want error for unknown aggregation")
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package sakura
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// スタートアップスクリプトのクラス。
+const (
+	NoteClassShell           = "shell"
+	NoteClassYAMLCloudConfig = "yaml_cloud_config"
+)
+
+// noteExportVersion はエクスポートファイルの形式のバージョン。
+const noteExportVersion = 1
+
+// noteTemplateVariablePattern はSakPilot独自のテンプレート変数 {{ name }} にマッチする。
+// さくらのクラウドの @sacloud-* 変数とは別物で、サーバー作成時にローカルで置換してから登録する。
+var noteTemplateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+type NoteInfo struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Class      string   `json:"class"`
+	Scope      string   `json:"scope"` // "shared"はさくらのクラウドが提供する公開スクリプト
+	Content    string   `json:"content"`
+	Tags       []string `json:"tags"`
+	CreatedAt  string   `json:"createdAt"`
+	ModifiedAt string   `json:"modifiedAt"`
+	// TemplateVariables はContentに含まれるテンプレート変数名の一覧。
+	TemplateVariables []string `json:"templateVariables"`
+}
+
+type NoteInput struct {
+	Name    string   `json:"name"`
+	Class   string   `json:"class"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+}
+
+// NoteExportFile はスタートアップスクリプトを共有するためのファイル形式。
+type NoteExportFile struct {
+	Version int         `json:"version"`
+	Notes   []NoteInput `json:"notes"`
+}
+
+type NoteService struct {
+	client *Client
+}
+
+func NewNoteService(client *Client) *NoteService {
+	return &NoteService{client: client}
+}
+
+// List はスタートアップスクリプトの一覧を返す。classを指定した場合はそのクラスのみ返す。
+func (s *NoteService) List(ctx context.Context, class string) ([]NoteInfo, error) {
+	op := iaas.NewNoteOp(s.client.Caller())
+	result, err := op.Find(ctx, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]NoteInfo, 0, len(result.Notes))
+	for _, n := range result.Notes {
+		if class != "" && n.Class != class {
+			continue
+		}
+		list = append(list, *noteFromSDK(n))
+	}
+	return list, nil
+}
+
+func (s *NoteService) Get(ctx context.Context, id string) (*NoteInfo, error) {
+	op := iaas.NewNoteOp(s.client.Caller())
+	n, err := op.Read(ctx, types.StringID(id))
+	if err != nil {
+		return nil, err
+	}
+	return noteFromSDK(n), nil
+}
+
+func (s *NoteService) Create(ctx context.Context, input NoteInput) (*NoteInfo, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	op := iaas.NewNoteOp(s.client.Caller())
+	n, err := op.Create(ctx, &iaas.NoteCreateRequest{
+		Name:    input.Name,
+		Class:   input.Class,
+		Content: input.Content,
+		Tags:    input.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return noteFromSDK(n), nil
+}
+
+func (s *NoteService) Update(ctx context.Context, id string, input NoteInput) (*NoteInfo, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	op := iaas.NewNoteOp(s.client.Caller())
+	n, err := op.Update(ctx, types.StringID(id), &iaas.NoteUpdateRequest{
+		Name:    input.Name,
+		Class:   input.Class,
+		Content: input.Content,
+		Tags:    input.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return noteFromSDK(n), nil
+}
+
+func (s *NoteService) Delete(ctx context.Context, id string) error {
+	op := iaas.NewNoteOp(s.client.Caller())
+	return op.Delete(ctx, types.StringID(id))
+}
+
+// Export は指定したスタートアップスクリプトをファイルに保存できる形式に変換する。
+func (s *NoteService) Export(ctx context.Context, ids []string) ([]byte, error) {
+	file := NoteExportFile{Version: noteExportVersion, Notes: make([]NoteInput, 0, len(ids))}
+	for _, id := range ids {
+		n, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		file.Notes = append(file.Notes, NoteInput{
+			Name:    n.Name,
+			Class:   n.Class,
+			Content: n.Content,
+			Tags:    n.Tags,
+		})
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Import はExportしたファイルのスタートアップスクリプトを新規に登録する。
+// 途中で失敗した場合は、それまでに登録したスクリプトとエラーを返す。Wailsはエラー時に戻り値を捨てるため、
+// 登録済みのスクリプト名もエラーに含め、再インポートで重複して登録しないよう判断できるようにする。
+func (s *NoteService) Import(ctx context.Context, data []byte) ([]NoteInfo, error) {
+	inputs, err := parseNoteExportFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]NoteInfo, 0, len(inputs))
+	for _, input := range inputs {
+		n, err := s.Create(ctx, input)
+		if err != nil {
+			if len(created) == 0 {
+				return created, fmt.Errorf("%s の登録に失敗しました: %w", input.Name, err)
+			}
+			names := make([]string, 0, len(created))
+			for _, c := range created {
+				names = append(names, c.Name)
+			}
+			return created, fmt.Errorf("%s の登録に失敗しました(登録済み: %s): %w", input.Name, strings.Join(names, ", "), err)
+		}
+		created = append(created, *n)
+	}
+	return created, nil
+}
+
+func parseNoteExportFile(data []byte) ([]NoteInput, error) {
+	var file NoteExportFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("スタートアップスクリプトのファイルを読み込めません: %w", err)
+	}
+	if file.Version != noteExportVersion {
+		return nil, fmt.Errorf("未対応のファイル形式のバージョンです: %d", file.Version)
+	}
+	for i, n := range file.Notes {
+		if err := n.validate(); err != nil {
+			return nil, fmt.Errorf("%d件目: %w", i+1, err)
+		}
+	}
+	return file.Notes, nil
+}
+
+func (in *NoteInput) validate() error {
+	if in.Name == "" {
+		return fmt.Errorf("名前を指定してください")
+	}
+	if in.Class != NoteClassShell && in.Class != NoteClassYAMLCloudConfig {
+		return fmt.Errorf("不明なクラスです: %s", in.Class)
+	}
+	return nil
+}
+
+// NoteTemplateVariables はスクリプト中のテンプレート変数名を重複なく名前順に返す。
+func NoteTemplateVariables(content string) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, m := range noteTemplateVariablePattern.FindAllStringSubmatch(content, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderNoteTemplate はテンプレート変数を値で置換する。値が指定されていない変数があればエラーとする。
+func RenderNoteTemplate(content string, variables map[string]string) (string, error) {
+	var missing []string
+	for _, name := range NoteTemplateVariables(content) {
+		if _, ok := variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("テンプレート変数の値が指定されていません: %s", strings.Join(missing, ", "))
+	}
+	return noteTemplateVariablePattern.ReplaceAllStringFunc(content, func(m string) string {
+		return variables[noteTemplateVariablePattern.FindStringSubmatch(m)[1]]
+	}), nil
+}
+
+// renderTemplateNotes はテンプレート変数を含むスクリプトを置換済みの一時スクリプトとして登録し、
+// ディスクの修正で参照するIDを差し替える。テンプレート変数を含まないスクリプトはそのまま参照する。
+// cleanupは一時スクリプトを削除する。ディスクの修正は非同期に行われ、修正中もスクリプトを参照するため、
+// cleanupはディスクが利用可能な状態に戻ってから呼ぶこと。
+func renderTemplateNotes(ctx context.Context, noteOp iaas.NoteAPI, notes []ServerDiskEditNote) ([]ServerDiskEditNote, func(), error) {
+	var tempIDs []types.ID
+	cleanup := func() {
+		for _, id := range tempIDs {
+			_ = noteOp.Delete(context.WithoutCancel(ctx), id)
+		}
+	}
+
+	rendered := make([]ServerDiskEditNote, 0, len(notes))
+	for _, note := range notes {
+		src, err := noteOp.Read(ctx, types.StringID(note.ID))
+		if err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+		if len(NoteTemplateVariables(src.Content)) == 0 {
+			rendered = append(rendered, note)
+			continue
+		}
+		content, err := RenderNoteTemplate(src.Content, note.TemplateVariables)
+		if err != nil {
+			cleanup()
+			return nil, func() {}, fmt.Errorf("%s: %w", src.Name, err)
+		}
+		tmp, err := noteOp.Create(ctx, &iaas.NoteCreateRequest{
+			Name:    src.Name + " (rendered)",
+			Class:   src.Class,
+			Content: content,
+		})
+		if err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+		tempIDs = append(tempIDs, tmp.ID)
+		rendered = append(rendered, ServerDiskEditNote{ID: tmp.ID.String(), Variables: note.Variables})
+	}
+	return rendered, cleanup, nil
+}
+
+func noteFromSDK(n *iaas.Note) *NoteInfo {
+	return &NoteInfo{
+		ID:                n.ID.String(),
+		Name:              n.Name,
+		Class:             n.Class,
+		Scope:             string(n.Scope),
+		Content:           n.Content,
+		Tags:              n.Tags,
+		CreatedAt:         n.CreatedAt.Format(time.RFC3339),
+		ModifiedAt:        n.ModifiedAt.Format(time.RFC3339),
+		TemplateVariables: NoteTemplateVariables(n.Content),
+	}
+}
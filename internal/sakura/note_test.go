@@ -0,0 +1,164 @@
+package sakura
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func newTestNoteService(t *testing.T) *NoteService {
+	t.Helper()
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	return NewNoteService(&Client{})
+}
+
+func TestNoteService_CRUDAndFilter(t *testing.T) {
+	service := newTestNoteService(t)
+	ctx := context.Background()
+
+	shell, err := service.Create(ctx, NoteInput{Name: "setup-shell", Class: NoteClassShell, Content: "#!/bin/sh\necho {{ greeting }}\n"})
+	if err != nil {
+		t.Fatalf("Create(shell): %v", err)
+	}
+	if !reflect.DeepEqual(shell.TemplateVariables, []string{"greeting"}) {
+		t.Errorf("TemplateVariables = %v, want [greeting]", shell.TemplateVariables)
+	}
+	cloudConfig, err := service.Create(ctx, NoteInput{Name: "setup-cloud-config", Class: NoteClassYAMLCloudConfig, Content: "#cloud-config\n"})
+	if err != nil {
+		t.Fatalf("Create(yaml_cloud_config): %v", err)
+	}
+
+	list, err := service.List(ctx, NoteClassYAMLCloudConfig)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	foundCloudConfig := false
+	for _, n := range list {
+		if n.Class != NoteClassYAMLCloudConfig {
+			t.Errorf("List(yaml_cloud_config) returned class %q", n.Class)
+		}
+		if n.ID == cloudConfig.ID {
+			foundCloudConfig = true
+		}
+	}
+	if !foundCloudConfig {
+		t.Errorf("List(yaml_cloud_config) does not contain %s", cloudConfig.ID)
+	}
+
+	updated, err := service.Update(ctx, shell.ID, NoteInput{Name: "setup-shell-v2", Class: NoteClassShell, Content: "#!/bin/sh\n"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "setup-shell-v2" || len(updated.TemplateVariables) != 0 {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	if err := service.Delete(ctx, shell.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := service.Get(ctx, shell.ID); err == nil {
+		t.Error("Get after Delete should fail")
+	}
+
+	if _, err := service.Create(ctx, NoteInput{Name: "bad", Class: "powershell"}); err == nil {
+		t.Error("Create with unknown class should fail")
+	}
+}
+
+func TestNoteService_ExportImport(t *testing.T) {
+	service := newTestNoteService(t)
+	ctx := context.Background()
+
+	src, err := service.Create(ctx, NoteInput{Name: "shared-lib", Class: NoteClassShell, Content: "#!/bin/sh\necho {{ name }}\n", Tags: []string{"team"}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	data, err := service.Export(ctx, []string{src.ID})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	imported, err := service.Import(ctx, data)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("len(imported) = %d, want 1", len(imported))
+	}
+	if imported[0].ID == src.ID || imported[0].Name != src.Name || imported[0].Content != src.Content {
+		t.Errorf("imported = %+v, want a copy of %+v", imported[0], src)
+	}
+
+	if _, err := service.Import(ctx, []byte(`{"version": 99, "notes": []}`)); err == nil {
+		t.Error("Import with unknown version should fail")
+	}
+}
+
+func TestRenderNoteTemplate(t *testing.T) {
+	content := "hostname {{host}}\necho {{ host }} {{ env }}\necho ${HOME}\n"
+	if got := NoteTemplateVariables(content); !reflect.DeepEqual(got, []string{"env", "host"}) {
+		t.Errorf("NoteTemplateVariables = %v, want [env host]", got)
+	}
+
+	got, err := RenderNoteTemplate(content, map[string]string{"host": "web1", "env": "prod"})
+	if err != nil {
+		t.Fatalf("RenderNoteTemplate: %v", err)
+	}
+	if want := "hostname web1\necho web1 prod\necho ${HOME}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := RenderNoteTemplate(content, map[string]string{"host": "web1"}); err == nil {
+		t.Error("missing variable should be an error")
+	}
+}
+
+func TestRenderTemplateNotes(t *testing.T) {
+	newTestNoteService(t)
+	ctx := context.Background()
+	noteOp := iaas.NewNoteOp(nil)
+
+	plain, err := noteOp.Create(ctx, &iaas.NoteCreateRequest{Name: "plain", Class: NoteClassShell, Content: "#!/bin/sh\n"})
+	if err != nil {
+		t.Fatalf("create plain note: %v", err)
+	}
+	tmpl, err := noteOp.Create(ctx, &iaas.NoteCreateRequest{Name: "tmpl", Class: NoteClassShell, Content: "echo {{ who }}\n"})
+	if err != nil {
+		t.Fatalf("create template note: %v", err)
+	}
+
+	notes, cleanup, err := renderTemplateNotes(ctx, noteOp, []ServerDiskEditNote{
+		{ID: plain.ID.String()},
+		{ID: tmpl.ID.String(), TemplateVariables: map[string]string{"who": "world"}},
+	})
+	if err != nil {
+		t.Fatalf("renderTemplateNotes: %v", err)
+	}
+	if notes[0].ID != plain.ID.String() {
+		t.Errorf("plain note should be referenced as is: %+v", notes[0])
+	}
+	if notes[1].ID == tmpl.ID.String() {
+		t.Fatalf("template note should be replaced by a rendered copy")
+	}
+	rendered, err := noteOp.Read(ctx, types.StringID(notes[1].ID))
+	if err != nil {
+		t.Fatalf("read rendered note: %v", err)
+	}
+	if rendered.Content != "echo world\n" {
+		t.Errorf("rendered Content = %q", rendered.Content)
+	}
+
+	cleanup()
+	if _, err := noteOp.Read(ctx, types.StringID(notes[1].ID)); err == nil {
+		t.Error("rendered note should be deleted by cleanup")
+	}
+
+	if _, _, err := renderTemplateNotes(ctx, noteOp, []ServerDiskEditNote{{ID: tmpl.ID.String()}}); err == nil {
+		t.Error("missing template variables should be an error")
+	}
+}
@@ -0,0 +1,289 @@
+package sakura
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// パケットフィルタ1つあたりに登録できるルール数の上限と、上限が近いと警告する残り数。
+const (
+	packetFilterMaxRules     = 30
+	packetFilterRulesWarnGap = 3
+)
+
+// PacketFilterTestPacket はパケットフィルタの評価に使う受信パケット。
+// Protocolは tcp/udp/icmp/fragment のいずれか。SourcePort・DestinationPortはtcp/udpの場合のみ使う。
+type PacketFilterTestPacket struct {
+	Protocol        string `json:"protocol"`
+	SourceIP        string `json:"sourceIp"`
+	SourcePort      int    `json:"sourcePort"`
+	DestinationPort int    `json:"destinationPort"`
+}
+
+// PacketFilterEvaluation は評価の結果。どのルールにも一致しないパケットは許可されるため、
+// その場合はRuleIndexが-1、Actionが "allow" になる。
+type PacketFilterEvaluation struct {
+	RuleIndex int                   `json:"ruleIndex"`
+	Rule      *PacketFilterRuleInfo `json:"rule"`
+	Action    string                `json:"action"`
+}
+
+// PacketFilterLintIssue はルールの並びの問題点。RuleIndexはルール全体に関する指摘の場合-1。
+type PacketFilterLintIssue struct {
+	RuleIndex int    `json:"ruleIndex"`
+	Code      string `json:"code"` // "invalid"/"shadowed"/"no-trailing-deny"/"no-fragment"/"no-icmp"/"rule-limit"
+	Message   string `json:"message"`
+}
+
+// packetFilterRule は評価用に解析したルール。network・portsがnilの場合は全てに一致する。
+type packetFilterRule struct {
+	protocol string
+	network  *net.IPNet
+	srcPorts *portRange
+	dstPorts *portRange
+	action   string
+}
+
+type portRange struct {
+	from, to int
+}
+
+func (r *portRange) contains(port int) bool {
+	return r == nil || (r.from <= port && port <= r.to)
+}
+
+func (r *portRange) covers(other *portRange) bool {
+	if r == nil {
+		return true
+	}
+	return other != nil && r.from <= other.from && other.to <= r.to
+}
+
+func parsePacketFilterRule(rule PacketFilterRuleInfo) (*packetFilterRule, error) {
+	parsed := &packetFilterRule{protocol: rule.Protocol, action: rule.Action}
+	switch rule.Protocol {
+	case "tcp", "udp", "icmp", "fragment", "ip":
+	default:
+		return nil, fmt.Errorf("不明なプロトコルです: %s", rule.Protocol)
+	}
+	if rule.Action != "allow" && rule.Action != "deny" {
+		return nil, fmt.Errorf("動作は allow/deny を指定してください: %s", rule.Action)
+	}
+
+	var err error
+	if parsed.network, err = parsePacketFilterNetwork(rule.SourceNetwork); err != nil {
+		return nil, err
+	}
+	// ポートはtcp/udpのルールでのみ有効
+	if rule.Protocol == "tcp" || rule.Protocol == "udp" {
+		if parsed.srcPorts, err = parsePacketFilterPort(rule.SourcePort); err != nil {
+			return nil, err
+		}
+		if parsed.dstPorts, err = parsePacketFilterPort(rule.DestinationPort); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// parsePacketFilterNetwork は送信元ネットワーク("192.0.2.1"/"192.0.2.0/24"/"192.0.2.0/255.255.255.0")を解析する。
+func parsePacketFilterNetwork(s string) (*net.IPNet, error) {
+	if s == "" {
+		return nil, nil
+	}
+	addr, mask, hasMask := strings.Cut(s, "/")
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, fmt.Errorf("送信元ネットワークの形式が正しくありません: %s", s)
+	}
+	bits := 32
+	if ip.To4() == nil {
+		bits = 128
+	} else {
+		ip = ip.To4()
+	}
+	ones := bits
+	if hasMask {
+		ones = -1
+		if n, err := strconv.Atoi(mask); err == nil {
+			ones = n
+		} else if m := net.ParseIP(mask).To4(); m != nil && bits == 32 {
+			// 連続していないネットマスクはSize()が(0, 0)を返す
+			if n, maskBits := net.IPMask(m).Size(); maskBits == 32 {
+				ones = n
+			}
+		}
+		if ones < 0 || ones > bits {
+			return nil, fmt.Errorf("送信元ネットワークのネットマスクが正しくありません: %s", s)
+		}
+	}
+	m := net.CIDRMask(ones, bits)
+	return &net.IPNet{IP: ip.Mask(m), Mask: m}, nil
+}
+
+// parsePacketFilterPort はポート("80"/"1024-65535")を解析する。
+func parsePacketFilterPort(s string) (*portRange, error) {
+	if s == "" {
+		return nil, nil
+	}
+	fromStr, toStr, isRange := strings.Cut(s, "-")
+	if !isRange {
+		toStr = fromStr
+	}
+	from, err1 := strconv.Atoi(fromStr)
+	to, err2 := strconv.Atoi(toStr)
+	if err1 != nil || err2 != nil || !validPort(from) || !validPort(to) || from > to {
+		return nil, fmt.Errorf("ポートの形式が正しくありません: %s", s)
+	}
+	return &portRange{from: from, to: to}, nil
+}
+
+func networkCovers(a, b *net.IPNet) bool {
+	if a == nil {
+		return true
+	}
+	if b == nil {
+		return false
+	}
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	return aBits == bBits && aOnes <= bOnes && a.Contains(b.IP)
+}
+
+// coveredBy はrに一致するパケットが全てotherにも一致する(otherがr以上に広い)かどうかを返す。
+func (r *packetFilterRule) coveredBy(other *packetFilterRule) bool {
+	if other.protocol != "ip" && other.protocol != r.protocol {
+		return false
+	}
+	if !networkCovers(other.network, r.network) {
+		return false
+	}
+	if other.protocol == "ip" {
+		return true
+	}
+	return other.srcPorts.covers(r.srcPorts) && other.dstPorts.covers(r.dstPorts)
+}
+
+func (r *packetFilterRule) matches(protocol string, ip net.IP, srcPort, dstPort int) bool {
+	if r.protocol != "ip" && r.protocol != protocol {
+		return false
+	}
+	if r.network != nil && !r.network.Contains(ip) {
+		return false
+	}
+	return r.srcPorts.contains(srcPort) && r.dstPorts.contains(dstPort)
+}
+
+// EvaluatePacketFilter はルールを上から順に評価し、パケットに最初に一致したルールとその動作を返す。
+func EvaluatePacketFilter(rules []PacketFilterRuleInfo, packet PacketFilterTestPacket) (*PacketFilterEvaluation, error) {
+	switch packet.Protocol {
+	case "tcp", "udp":
+		if !validPort(packet.SourcePort) || !validPort(packet.DestinationPort) {
+			return nil, fmt.Errorf("ポートは1〜65535の範囲で指定してください")
+		}
+	case "icmp", "fragment":
+	default:
+		return nil, fmt.Errorf("プロトコルは tcp/udp/icmp/fragment のいずれかを指定してください")
+	}
+	ip := net.ParseIP(packet.SourceIP)
+	if ip == nil {
+		return nil, fmt.Errorf("送信元IPアドレスの形式が正しくありません: %s", packet.SourceIP)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	for i, rule := range rules {
+		parsed, err := parsePacketFilterRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("ルール #%d: %w", i+1, err)
+		}
+		if parsed.matches(packet.Protocol, ip, packet.SourcePort, packet.DestinationPort) {
+			return &PacketFilterEvaluation{RuleIndex: i, Rule: &rules[i], Action: rule.Action}, nil
+		}
+	}
+	return &PacketFilterEvaluation{RuleIndex: -1, Action: "allow"}, nil
+}
+
+// LintPacketFilter はルールの並びを確認し、前のルールに隠れて評価されないルール・末尾の全拒否の欠落・
+// フラグメントやICMPの許可漏れ・ルール数の上限を指摘する。
+func LintPacketFilter(rules []PacketFilterRuleInfo) []PacketFilterLintIssue {
+	issues := make([]PacketFilterLintIssue, 0)
+	parsed := make([]*packetFilterRule, len(rules))
+	for i, rule := range rules {
+		p, err := parsePacketFilterRule(rule)
+		if err != nil {
+			issues = append(issues, PacketFilterLintIssue{RuleIndex: i, Code: "invalid", Message: err.Error()})
+			continue
+		}
+		parsed[i] = p
+	}
+
+	for i, rule := range parsed {
+		if rule == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if parsed[j] == nil || !rule.coveredBy(parsed[j]) {
+				continue
+			}
+			msg := fmt.Sprintf("ルール #%d に一致するパケットは全てルール #%d に一致するため、このルールは評価されません", i+1, j+1)
+			if parsed[j].action != rule.action {
+				msg += fmt.Sprintf("(#%d の %s が適用されます)", j+1, parsed[j].action)
+			}
+			issues = append(issues, PacketFilterLintIssue{RuleIndex: i, Code: "shadowed", Message: msg})
+			break
+		}
+	}
+
+	denyAll := -1
+	for i, rule := range parsed {
+		if rule != nil && rule.protocol == "ip" && rule.network == nil && rule.action == "deny" {
+			denyAll = i
+			break
+		}
+	}
+	if denyAll < 0 {
+		issues = append(issues, PacketFilterLintIssue{
+			RuleIndex: -1,
+			Code:      "no-trailing-deny",
+			Message:   "末尾に全てのパケットを拒否するルール(ip/deny)がありません。どのルールにも一致しないパケットは許可されます",
+		})
+	} else {
+		if !allowedBefore(parsed[:denyAll], "fragment") {
+			issues = append(issues, PacketFilterLintIssue{
+				RuleIndex: -1,
+				Code:      "no-fragment",
+				Message:   "フラグメント化されたパケットを許可するルール(fragment/allow)がありません。大きなUDPパケット(DNS等)が届かなくなります",
+			})
+		}
+		if !allowedBefore(parsed[:denyAll], "icmp") {
+			issues = append(issues, PacketFilterLintIssue{
+				RuleIndex: -1,
+				Code:      "no-icmp",
+				Message:   "ICMPを許可するルール(icmp/allow)がありません。pingやPath MTU Discoveryが機能しなくなります",
+			})
+		}
+	}
+
+	if len(rules) > packetFilterMaxRules-packetFilterRulesWarnGap {
+		issues = append(issues, PacketFilterLintIssue{
+			RuleIndex: -1,
+			Code:      "rule-limit",
+			Message:   fmt.Sprintf("ルール数が %d 件です。1つのパケットフィルタに登録できるのは %d 件までです", len(rules), packetFilterMaxRules),
+		})
+	}
+	return issues
+}
+
+// allowedBefore はprotocolのパケットを許可するルールがあるかを返す。送信元を限定したルールも許可とみなす。
+func allowedBefore(rules []*packetFilterRule, protocol string) bool {
+	for _, r := range rules {
+		if r != nil && r.action == "allow" && r.protocol == protocol {
+			return true
+		}
+	}
+	return false
+}
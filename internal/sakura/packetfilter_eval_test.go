@@ -0,0 +1,88 @@
+package sakura
+
+import "testing"
+
+func TestEvaluatePacketFilter(t *testing.T) {
+	rules := []PacketFilterRuleInfo{
+		{Protocol: "tcp", SourceNetwork: "192.0.2.0/24", DestinationPort: "22", Action: "allow"},
+		{Protocol: "tcp", DestinationPort: "80-443", Action: "allow"},
+		{Protocol: "udp", SourceNetwork: "198.51.100.0/255.255.255.0", SourcePort: "53", Action: "allow"},
+		{Protocol: "fragment", Action: "allow"},
+		{Protocol: "ip", Action: "deny"},
+	}
+	tests := []struct {
+		name       string
+		packet     PacketFilterTestPacket
+		wantIndex  int
+		wantAction string
+	}{
+		{"ssh from allowed network", PacketFilterTestPacket{Protocol: "tcp", SourceIP: "192.0.2.10", SourcePort: 50000, DestinationPort: 22}, 0, "allow"},
+		{"ssh from elsewhere", PacketFilterTestPacket{Protocol: "tcp", SourceIP: "203.0.113.10", SourcePort: 50000, DestinationPort: 22}, 4, "deny"},
+		{"https", PacketFilterTestPacket{Protocol: "tcp", SourceIP: "203.0.113.10", SourcePort: 50000, DestinationPort: 443}, 1, "allow"},
+		{"dns response with mask notation", PacketFilterTestPacket{Protocol: "udp", SourceIP: "198.51.100.53", SourcePort: 53, DestinationPort: 40000}, 2, "allow"},
+		{"fragment", PacketFilterTestPacket{Protocol: "fragment", SourceIP: "203.0.113.10"}, 3, "allow"},
+		{"icmp", PacketFilterTestPacket{Protocol: "icmp", SourceIP: "203.0.113.10"}, 4, "deny"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvaluatePacketFilter(rules, tt.packet)
+			if err != nil {
+				t.Fatalf("EvaluatePacketFilter: %v", err)
+			}
+			if got.RuleIndex != tt.wantIndex || got.Action != tt.wantAction {
+				t.Errorf("got rule %d %s, want rule %d %s", got.RuleIndex, got.Action, tt.wantIndex, tt.wantAction)
+			}
+		})
+	}
+
+	got, err := EvaluatePacketFilter(rules[:1], PacketFilterTestPacket{Protocol: "icmp", SourceIP: "203.0.113.10"})
+	if err != nil {
+		t.Fatalf("EvaluatePacketFilter: %v", err)
+	}
+	if got.RuleIndex != -1 || got.Action != "allow" {
+		t.Errorf("unmatched packet = %+v, want implicit allow", got)
+	}
+	if _, err := EvaluatePacketFilter(rules, PacketFilterTestPacket{Protocol: "tcp", SourceIP: "192.0.2.10", DestinationPort: 22}); err == nil {
+		t.Error("EvaluatePacketFilter should reject a tcp packet without a source port")
+	}
+}
+
+func TestLintPacketFilter(t *testing.T) {
+	codes := func(issues []PacketFilterLintIssue) map[string][]int {
+		m := make(map[string][]int)
+		for _, issue := range issues {
+			m[issue.Code] = append(m[issue.Code], issue.RuleIndex)
+		}
+		return m
+	}
+
+	got := codes(LintPacketFilter([]PacketFilterRuleInfo{
+		{Protocol: "tcp", SourceNetwork: "192.0.2.0/24", Action: "allow"},
+		{Protocol: "tcp", SourceNetwork: "192.0.2.128/25", DestinationPort: "22", Action: "deny"},
+		{Protocol: "tcp", DestinationPort: "22", Action: "allow"},
+		{Protocol: "udp", DestinationPort: "70000", Action: "allow"},
+		{Protocol: "ip", Action: "deny"},
+		{Protocol: "icmp", Action: "allow"},
+	}))
+	if idx := got["shadowed"]; len(idx) != 2 || idx[0] != 1 || idx[1] != 5 {
+		t.Errorf("shadowed = %v, want [1 5]", idx)
+	}
+	if idx := got["invalid"]; len(idx) != 1 || idx[0] != 3 {
+		t.Errorf("invalid = %v, want [3]", idx)
+	}
+	if len(got["no-fragment"]) != 1 || len(got["no-icmp"]) != 1 {
+		t.Errorf("issues = %v, want missing fragment and icmp allowances", got)
+	}
+	if len(got["no-trailing-deny"]) != 0 {
+		t.Errorf("issues = %v, want no missing deny", got)
+	}
+
+	var many []PacketFilterRuleInfo
+	for i := 0; i < 28; i++ {
+		many = append(many, PacketFilterRuleInfo{Protocol: "tcp", SourceNetwork: "192.0.2.1", DestinationPort: "1", Action: "allow"})
+	}
+	got = codes(LintPacketFilter(many))
+	if len(got["rule-limit"]) != 1 || len(got["no-trailing-deny"]) != 1 {
+		t.Errorf("issues = %v, want rule-limit and no-trailing-deny", got)
+	}
+}
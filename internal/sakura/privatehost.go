@@ -0,0 +1,224 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// PrivateHostInfo は専有ホストと、割り当て済みのサーバー・空きリソース。
+type PrivateHostInfo struct {
+	ID               string                  `json:"id"`
+	Name             string                  `json:"name"`
+	Description      string                  `json:"description"`
+	Tags             []string                `json:"tags"`
+	PlanID           string                  `json:"planId"`
+	PlanName         string                  `json:"planName"`
+	PlanClass        string                  `json:"planClass"`
+	HostName         string                  `json:"hostName"`
+	CPU              int                     `json:"cpu"`
+	MemoryGB         int                     `json:"memoryGb"`
+	AssignedCPU      int                     `json:"assignedCpu"`
+	AssignedMemoryGB int                     `json:"assignedMemoryGb"`
+	Servers          []PrivateHostServerInfo `json:"servers"`
+	CreatedAt        string                  `json:"createdAt"`
+}
+
+type PrivateHostServerInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	CPU      int    `json:"cpu"`
+	MemoryGB int    `json:"memoryGb"`
+	Status   string `json:"status"`
+}
+
+type PrivateHostPlanInfo struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Class        string `json:"class"`
+	CPU          int    `json:"cpu"`
+	MemoryGB     int    `json:"memoryGb"`
+	Availability string `json:"availability"`
+}
+
+type PrivateHostCreateInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	PlanID      string   `json:"planId"`
+}
+
+type PrivateHostService struct {
+	client *Client
+}
+
+func NewPrivateHostService(client *Client) *PrivateHostService {
+	return &PrivateHostService{client: client}
+}
+
+// List はゾーン内の専有ホストを、割り当て済みのサーバーと合わせて返す。
+func (s *PrivateHostService) List(ctx context.Context, zone string) ([]PrivateHostInfo, error) {
+	result, err := iaas.NewPrivateHostOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	servers, err := iaas.NewServerOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]PrivateHostInfo, 0, len(result.PrivateHosts))
+	for _, h := range result.PrivateHosts {
+		list = append(list, *privateHostFromSDK(h, servers.Servers))
+	}
+	return list, nil
+}
+
+func (s *PrivateHostService) ListPlans(ctx context.Context, zone string) ([]PrivateHostPlanInfo, error) {
+	result, err := iaas.NewPrivateHostPlanOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]PrivateHostPlanInfo, 0, len(result.PrivateHostPlans))
+	for _, p := range result.PrivateHostPlans {
+		plans = append(plans, PrivateHostPlanInfo{
+			ID:           p.ID.String(),
+			Name:         p.Name,
+			Class:        p.Class,
+			CPU:          p.CPU,
+			MemoryGB:     p.MemoryMB / 1024,
+			Availability: string(p.Availability),
+		})
+	}
+	return plans, nil
+}
+
+func (s *PrivateHostService) Create(ctx context.Context, zone string, input PrivateHostCreateInput) (*PrivateHostInfo, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("名前を指定してください")
+	}
+	if input.PlanID == "" {
+		return nil, fmt.Errorf("プランを指定してください")
+	}
+
+	h, err := iaas.NewPrivateHostOp(s.client.Caller()).Create(ctx, zone, &iaas.PrivateHostCreateRequest{
+		Name:        input.Name,
+		Description: input.Description,
+		Tags:        input.Tags,
+		PlanID:      types.StringID(input.PlanID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return privateHostFromSDK(h, nil), nil
+}
+
+// Delete は専有ホストを削除する。サーバーが割り当てられている場合は削除しない。
+func (s *PrivateHostService) Delete(ctx context.Context, zone string, privateHostID string) error {
+	id := types.StringID(privateHostID)
+	servers, err := iaas.NewServerOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return err
+	}
+	for _, srv := range servers.Servers {
+		if srv.PrivateHostID == id {
+			return fmt.Errorf("サーバー %s が割り当てられているため削除できません", srv.Name)
+		}
+	}
+	return iaas.NewPrivateHostOp(s.client.Caller()).Delete(ctx, zone, id)
+}
+
+// ChangePrivateHost はサーバーを専有ホストへ移動する。privateHostIDが空文字の場合は共有ホストへ戻す。
+// サーバーは停止している必要があり、移動先の専有ホストにサーバーのCPU・メモリ分の空きがなければエラーを返す。
+func (s *ServerService) ChangePrivateHost(ctx context.Context, zone string, serverID string, privateHostID string) (*ServerInfo, error) {
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	srv, err := serverOp.Read(ctx, zone, types.StringID(serverID))
+	if err != nil {
+		return nil, err
+	}
+	var host *iaas.PrivateHost
+	if privateHostID != "" {
+		host, err = iaas.NewPrivateHostOp(s.client.Caller()).Read(ctx, zone, types.StringID(privateHostID))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := checkPrivateHostPlacement(srv, host); err != nil {
+		return nil, err
+	}
+
+	updated, err := serverOp.Update(ctx, zone, srv.ID, &iaas.ServerUpdateRequest{
+		Name:            srv.Name,
+		Description:     srv.Description,
+		Tags:            srv.Tags,
+		IconID:          srv.IconID,
+		PrivateHostID:   types.StringID(privateHostID),
+		InterfaceDriver: srv.InterfaceDriver,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 共有ホストへ戻す場合、更新後も専有ホストの指定が残っていれば移動できていないため、成功として扱わない
+	if host == nil && !updated.PrivateHostID.IsEmpty() {
+		return nil, fmt.Errorf("サーバー %s は専有ホスト %s に配置されたままです", updated.Name, updated.PrivateHostID)
+	}
+	return serverFromSDK(zone, updated), nil
+}
+
+// checkPrivateHostPlacement はサーバーを専有ホスト(nilは共有ホスト)へ移動できるか確認する。
+func checkPrivateHostPlacement(srv *iaas.Server, host *iaas.PrivateHost) error {
+	if host == nil {
+		if srv.PrivateHostID.IsEmpty() {
+			return fmt.Errorf("サーバー %s は専有ホストに配置されていません", srv.Name)
+		}
+	} else {
+		if srv.PrivateHostID == host.ID {
+			return fmt.Errorf("サーバー %s は既に %s に配置されています", srv.Name, host.Name)
+		}
+		freeCPU := host.CPU - host.AssignedCPU
+		freeMemoryMB := host.MemoryMB - host.AssignedMemoryMB
+		if srv.CPU > freeCPU || srv.MemoryMB > freeMemoryMB {
+			return fmt.Errorf("専有ホスト %s の空きリソース(%dコア/%dGB)が足りません", host.Name, freeCPU, freeMemoryMB/1024)
+		}
+	}
+	if srv.InstanceStatus != types.ServerInstanceStatuses.Down {
+		return fmt.Errorf("サーバー %s を停止してから実行してください", srv.Name)
+	}
+	return nil
+}
+
+func privateHostFromSDK(h *iaas.PrivateHost, servers []*iaas.Server) *PrivateHostInfo {
+	info := &PrivateHostInfo{
+		ID:               h.ID.String(),
+		Name:             h.Name,
+		Description:      h.Description,
+		Tags:             h.Tags,
+		PlanID:           h.PlanID.String(),
+		PlanName:         h.PlanName,
+		PlanClass:        h.PlanClass,
+		HostName:         h.HostName,
+		CPU:              h.CPU,
+		MemoryGB:         h.MemoryMB / 1024,
+		AssignedCPU:      h.AssignedCPU,
+		AssignedMemoryGB: h.AssignedMemoryMB / 1024,
+		Servers:          []PrivateHostServerInfo{},
+		CreatedAt:        h.CreatedAt.Format(time.RFC3339),
+	}
+	for _, srv := range servers {
+		if srv.PrivateHostID != h.ID {
+			continue
+		}
+		info.Servers = append(info.Servers, PrivateHostServerInfo{
+			ID:       srv.ID.String(),
+			Name:     srv.Name,
+			CPU:      srv.CPU,
+			MemoryGB: srv.GetMemoryGB(),
+			Status:   string(srv.InstanceStatus),
+		})
+	}
+	return info
+}
@@ -0,0 +1,110 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func TestPrivateHostService_ListAndDelete(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	service := NewPrivateHostService(&Client{})
+	ctx := context.Background()
+
+	host, err := service.Create(ctx, "tk1a", PrivateHostCreateInput{Name: "license-host", PlanID: "112900526366"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	srv, err := iaas.NewServerOp(nil).Create(ctx, "tk1a", &iaas.ServerCreateRequest{
+		Name:          "on-host",
+		CPU:           2,
+		MemoryMB:      4096,
+		PrivateHostID: types.StringID(host.ID),
+	})
+	if err != nil {
+		t.Fatalf("serverOp.Create: %v", err)
+	}
+	if _, err := createTestServer(ctx, "tk1a"); err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+
+	list, err := service.List(ctx, "tk1a")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 1 || len(list[0].Servers) != 1 || list[0].Servers[0].ID != srv.ID.String() {
+		t.Fatalf("list = %+v, want the host with only the assigned server", list)
+	}
+
+	if err := service.Delete(ctx, "tk1a", host.ID); err == nil {
+		t.Error("Delete should fail while a server is assigned")
+	}
+	if err := iaas.NewServerOp(nil).Delete(ctx, "tk1a", srv.ID); err != nil {
+		t.Fatalf("serverOp.Delete: %v", err)
+	}
+	if err := service.Delete(ctx, "tk1a", host.ID); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+}
+
+func TestServerService_ChangePrivateHost(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	ctx := context.Background()
+
+	host, err := NewPrivateHostService(&Client{}).Create(ctx, "tk1a", PrivateHostCreateInput{Name: "license-host", PlanID: "112900526366"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	srv, err := createTestServer(ctx, "tk1a")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+
+	service := NewServerService(&Client{})
+	moved, err := service.ChangePrivateHost(ctx, "tk1a", srv.ID.String(), host.ID)
+	if err != nil {
+		t.Fatalf("ChangePrivateHost(host): %v", err)
+	}
+	if read, _ := iaas.NewServerOp(nil).Read(ctx, "tk1a", types.StringID(moved.ID)); read.PrivateHostID.String() != host.ID {
+		t.Fatalf("PrivateHostID = %s, want %s", read.PrivateHostID, host.ID)
+	}
+
+	// 共有ホストへ戻す場合は専有ホストの指定が外れていること
+	back, err := service.ChangePrivateHost(ctx, "tk1a", moved.ID, "")
+	if err != nil {
+		t.Fatalf("ChangePrivateHost(shared): %v", err)
+	}
+	if read, _ := iaas.NewServerOp(nil).Read(ctx, "tk1a", types.StringID(back.ID)); !read.PrivateHostID.IsEmpty() {
+		t.Errorf("PrivateHostID = %s, want empty after moving back to the shared host", read.PrivateHostID)
+	}
+}
+
+func TestCheckPrivateHostPlacement(t *testing.T) {
+	host := &iaas.PrivateHost{ID: types.ID(10), Name: "host", CPU: 224, MemoryMB: 1024 * 1024, AssignedCPU: 220, AssignedMemoryMB: 1000 * 1024}
+	tests := []struct {
+		name    string
+		srv     *iaas.Server
+		host    *iaas.PrivateHost
+		wantErr bool
+	}{
+		{"fits", &iaas.Server{CPU: 4, MemoryMB: 16 * 1024, InstanceStatus: types.ServerInstanceStatuses.Down}, host, false},
+		{"not enough cpu", &iaas.Server{CPU: 8, MemoryMB: 16 * 1024, InstanceStatus: types.ServerInstanceStatuses.Down}, host, true},
+		{"not enough memory", &iaas.Server{CPU: 4, MemoryMB: 32 * 1024, InstanceStatus: types.ServerInstanceStatuses.Down}, host, true},
+		{"running", &iaas.Server{CPU: 4, MemoryMB: 16 * 1024, InstanceStatus: types.ServerInstanceStatuses.Up}, host, true},
+		{"already on the host", &iaas.Server{CPU: 4, MemoryMB: 16 * 1024, PrivateHostID: types.ID(10), InstanceStatus: types.ServerInstanceStatuses.Down}, host, true},
+		{"back to shared host", &iaas.Server{PrivateHostID: types.ID(10), InstanceStatus: types.ServerInstanceStatuses.Down}, nil, false},
+		{"already on shared host", &iaas.Server{InstanceStatus: types.ServerInstanceStatuses.Down}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkPrivateHostPlacement(tt.srv, tt.host); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+)
+
+// PTRRecordInfo はグローバルIPアドレスと、設定されている逆引きホスト名。
+type PTRRecordInfo struct {
+	Zone         string `json:"zone"`
+	IPAddress    string `json:"ipAddress"`
+	IPVersion    int    `json:"ipVersion"` // 4 or 6
+	HostName     string `json:"hostName"`
+	Source       string `json:"source"` // "shared"(共有セグメント)/"router"(ルーターのサブネット)
+	InternetID   string `json:"internetId"`
+	InternetName string `json:"internetName"`
+	InterfaceID  string `json:"interfaceId"`
+}
+
+type PTRService struct {
+	client *Client
+	// lookupHost は正引きの確認に使う。テストでは差し替える。
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+func NewPTRService(client *Client) *PTRService {
+	return &PTRService{client: client, lookupHost: net.DefaultResolver.LookupHost}
+}
+
+// List はゾーン内のグローバルIPv4アドレス(共有セグメント・ルーターのサブネット)と、
+// 逆引きを登録したIPv6アドレスを、現在の逆引きホスト名と合わせて返す。
+func (s *PTRService) List(ctx context.Context, zone string) ([]PTRRecordInfo, error) {
+	routers, err := iaas.NewInternetOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	subnets, err := iaas.NewSubnetOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := iaas.NewIPAddressOp(s.client.Caller()).List(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	ipv6Addrs, err := iaas.NewIPv6AddrOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	return ptrRecordsFromSDK(zone, routers.Internet, subnets.Subnets, addresses.IPAddress, ipv6Addrs.IPv6Addrs), nil
+}
+
+func ptrRecordsFromSDK(zone string, routers []*iaas.Internet, subnets []*iaas.Subnet, addresses []*iaas.IPAddress, ipv6Addrs []*iaas.IPv6Addr) []PTRRecordInfo {
+	routerNames := make(map[string]string, len(routers))
+	for _, r := range routers {
+		routerNames[r.ID.String()] = r.Name
+	}
+
+	records := make(map[string]*PTRRecordInfo)
+	routerSubnets := make(map[string]*iaas.Subnet, len(subnets))
+	for _, subnet := range subnets {
+		routerSubnets[subnet.ID.String()] = subnet
+		for _, ip := range subnet.IPAddresses {
+			records[ip.IPAddress] = &PTRRecordInfo{
+				Zone:         zone,
+				IPAddress:    ip.IPAddress,
+				IPVersion:    4,
+				HostName:     ip.HostName,
+				Source:       "router",
+				InternetID:   subnet.InternetID.String(),
+				InternetName: routerNames[subnet.InternetID.String()],
+			}
+		}
+	}
+
+	for _, ip := range addresses {
+		r, ok := records[ip.IPAddress]
+		if !ok {
+			r = &PTRRecordInfo{Zone: zone, IPAddress: ip.IPAddress, IPVersion: 4, Source: "shared"}
+			if subnet, ok := routerSubnets[ip.SubnetID.String()]; ok {
+				r.Source = "router"
+				r.InternetID = subnet.InternetID.String()
+				r.InternetName = routerNames[r.InternetID]
+			}
+			records[ip.IPAddress] = r
+		}
+		r.HostName = ip.HostName
+		if !ip.InterfaceID.IsEmpty() {
+			r.InterfaceID = ip.InterfaceID.String()
+		}
+	}
+
+	list := make([]PTRRecordInfo, 0, len(records)+len(ipv6Addrs))
+	for _, r := range records {
+		list = append(list, *r)
+	}
+	sort.Slice(list, func(i, j int) bool { return compareIP(list[i].IPAddress, list[j].IPAddress) < 0 })
+
+	for _, a := range ipv6Addrs {
+		info := PTRRecordInfo{
+			Zone:      zone,
+			IPAddress: a.IPv6Addr,
+			IPVersion: 6,
+			HostName:  a.HostName,
+			Source:    "router",
+		}
+		if !a.InterfaceID.IsEmpty() {
+			info.InterfaceID = a.InterfaceID.String()
+		}
+		list = append(list, info)
+	}
+	return list
+}
+
+func compareIP(a, b string) int {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return strings.Compare(a, b)
+	}
+	return strings.Compare(string(ipA.To16()), string(ipB.To16()))
+}
+
+// SetHostName はIPアドレスの逆引きホスト名を設定する。hostNameが空文字の場合は逆引きを削除する。
+// 設定する前に、hostNameの正引きにipAddressが含まれていることを確認する(さくらのクラウドでも同じ確認が行われる)。
+func (s *PTRService) SetHostName(ctx context.Context, zone string, ipAddress string, hostName string) (*PTRRecordInfo, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("IPアドレスの形式が正しくありません: %s", ipAddress)
+	}
+	hostName = strings.TrimSuffix(strings.TrimSpace(hostName), ".")
+	if hostName != "" {
+		if err := s.checkForwardDNS(ctx, ip, hostName); err != nil {
+			return nil, err
+		}
+	}
+
+	if ip.To4() != nil {
+		op := iaas.NewIPAddressOp(s.client.Caller())
+		r, err := op.UpdateHostName(ctx, zone, ipAddress, hostName)
+		if err != nil {
+			return nil, err
+		}
+		return &PTRRecordInfo{Zone: zone, IPAddress: r.IPAddress, IPVersion: 4, HostName: r.HostName}, nil
+	}
+	return s.setIPv6HostName(ctx, zone, ipAddress, hostName)
+}
+
+// setIPv6HostName はIPv6アドレスの逆引きを登録・変更・削除する。IPv6は逆引きを登録したアドレスだけがAPIのリソースになる。
+func (s *PTRService) setIPv6HostName(ctx context.Context, zone string, ipAddress string, hostName string) (*PTRRecordInfo, error) {
+	op := iaas.NewIPv6AddrOp(s.client.Caller())
+	_, err := op.Read(ctx, zone, ipAddress)
+	exists := err == nil
+	if err != nil && !iaas.IsNotFoundError(err) {
+		return nil, err
+	}
+
+	info := &PTRRecordInfo{Zone: zone, IPAddress: ipAddress, IPVersion: 6, HostName: hostName, Source: "router"}
+	switch {
+	case hostName == "" && exists:
+		return info, op.Delete(ctx, zone, ipAddress)
+	case hostName == "":
+		return info, nil
+	case exists:
+		_, err = op.Update(ctx, zone, ipAddress, &iaas.IPv6AddrUpdateRequest{HostName: hostName})
+	default:
+		_, err = op.Create(ctx, zone, &iaas.IPv6AddrCreateRequest{IPv6Addr: ipAddress, HostName: hostName})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// checkForwardDNS はhostNameの正引き(A/AAAA)にipが含まれていることを確認する。
+func (s *PTRService) checkForwardDNS(ctx context.Context, ip net.IP, hostName string) error {
+	addrs, err := s.lookupHost(ctx, hostName)
+	if err != nil {
+		return fmt.Errorf("%s の正引きに失敗しました。先にDNSにAまたはAAAAレコードを登録してください: %w", hostName, err)
+	}
+	for _, addr := range addrs {
+		if ip.Equal(net.ParseIP(addr)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s の正引き(%s)に %s が含まれていません", hostName, strings.Join(addrs, ", "), ip)
+}
@@ -0,0 +1,76 @@
+package sakura
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+)
+
+func TestPtrRecordsFromSDK(t *testing.T) {
+	routers := []*iaas.Internet{{ID: 1, Name: "router"}}
+	subnets := []*iaas.Subnet{{
+		ID:         100,
+		InternetID: 1,
+		IPAddresses: []*iaas.SubnetIPAddress{
+			{IPAddress: "192.0.2.5"},
+			{IPAddress: "192.0.2.4", HostName: "mail.example.com"},
+		},
+	}}
+	addresses := []*iaas.IPAddress{
+		{IPAddress: "203.0.113.10", HostName: "www.example.com", InterfaceID: 500, SubnetID: 900},
+		{IPAddress: "192.0.2.5", HostName: "app.example.com", SubnetID: 100},
+	}
+	ipv6Addrs := []*iaas.IPv6Addr{{IPv6Addr: "2001:db8::10", HostName: "mail.example.com"}}
+
+	got := ptrRecordsFromSDK("is1a", routers, subnets, addresses, ipv6Addrs)
+	if len(got) != 4 {
+		t.Fatalf("got %+v, want 4 records", got)
+	}
+	want := []struct {
+		ip, hostName, source string
+	}{
+		{"192.0.2.4", "mail.example.com", "router"},
+		{"192.0.2.5", "app.example.com", "router"},
+		{"203.0.113.10", "www.example.com", "shared"},
+		{"2001:db8::10", "mail.example.com", "router"},
+	}
+	for i, w := range want {
+		if got[i].IPAddress != w.ip || got[i].HostName != w.hostName || got[i].Source != w.source {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+	if got[0].InternetName != "router" || got[2].InterfaceID != "500" {
+		t.Errorf("records = %+v", got)
+	}
+}
+
+func TestPTRService_CheckForwardDNS(t *testing.T) {
+	service := NewPTRService(&Client{})
+	service.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		switch host {
+		case "mail.example.com":
+			return []string{"192.0.2.4", "2001:db8:0:0::10"}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}
+	ctx := context.Background()
+
+	if _, err := service.SetHostName(ctx, "is1a", "192.0.2.5", "mail.example.com"); err == nil {
+		t.Error("SetHostName should fail when forward DNS does not match")
+	}
+	if _, err := service.SetHostName(ctx, "is1a", "192.0.2.4", "unknown.example.com."); err == nil {
+		t.Error("SetHostName should fail when forward DNS lookup fails")
+	}
+	if _, err := service.SetHostName(ctx, "is1a", "not-an-ip", "mail.example.com"); err == nil {
+		t.Error("SetHostName should reject an invalid address")
+	}
+	for _, ip := range []string{"192.0.2.4", "2001:db8::10"} {
+		if err := service.checkForwardDNS(ctx, net.ParseIP(ip), "mail.example.com"); err != nil {
+			t.Errorf("checkForwardDNS(%s): %v", ip, err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// maxPasteLength は1回の貼り付けで送る文字数の上限。1文字ごとにAPIを呼ぶため、長文の誤貼り付けを防ぐ。
+const maxPasteLength = 4096
+
+// 英語(US)配列で、シフトなしで入力できる記号のキー名。
+var unshiftedSymbolKeys = map[rune]string{
+	' ':  "spc",
+	'\n': "ret",
+	'\t': "tab",
+	'-':  "minus",
+	'=':  "equal",
+	'[':  "bracket_left",
+	']':  "bracket_right",
+	';':  "semicolon",
+	'\'': "apostrophe",
+	'`':  "grave_accent",
+	'\\': "backslash",
+	',':  "comma",
+	'.':  "dot",
+	'/':  "slash",
+}
+
+// 英語(US)配列で、シフトを押しながら入力する記号のキー名。
+var shiftedSymbolKeys = map[rune]string{
+	'!': "1",
+	'@': "2",
+	'#': "3",
+	'$': "4",
+	'%': "5",
+	'^': "6",
+	'&': "7",
+	'*': "8",
+	'(': "9",
+	')': "0",
+	'_': "minus",
+	'+': "equal",
+	'{': "bracket_left",
+	'}': "bracket_right",
+	':': "semicolon",
+	'"': "apostrophe",
+	'~': "grave_accent",
+	'|': "backslash",
+	'<': "comma",
+	'>': "dot",
+	'?': "slash",
+}
+
+// textToKeySequences はテキストを1文字ずつ、同時押しするキー名の組に変換する。
+// サーバーのキーボード配列は英語(US)を前提とし、改行コードのCRは無視する。ASCII以外の文字はエラーとする。
+func textToKeySequences(text string) ([][]string, error) {
+	length := utf8.RuneCountInString(text)
+	if length > maxPasteLength {
+		return nil, fmt.Errorf("貼り付けできるのは%d文字までです", maxPasteLength)
+	}
+
+	sequences := make([][]string, 0, length)
+	pos := 0
+	for _, r := range text {
+		pos++
+		switch {
+		case r == '\r':
+			continue
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sequences = append(sequences, []string{string(r)})
+		case r >= 'A' && r <= 'Z':
+			sequences = append(sequences, []string{"shift", string(r - 'A' + 'a')})
+		default:
+			if key, ok := unshiftedSymbolKeys[r]; ok {
+				sequences = append(sequences, []string{key})
+			} else if key, ok := shiftedSymbolKeys[r]; ok {
+				sequences = append(sequences, []string{"shift", key})
+			} else {
+				return nil, fmt.Errorf("%d文字目の %q はキー入力で送信できません", pos, r)
+			}
+		}
+	}
+	return sequences, nil
+}
+
+// PasteText はテキストをキー入力としてサーバーのコンソールに送信する。
+// 送信できない文字が含まれる場合は、何も送信せずにエラーを返す。
+func (s *ServerService) PasteText(ctx context.Context, zone string, serverID string, text string) error {
+	sequences, err := textToKeySequences(text)
+	if err != nil {
+		return err
+	}
+
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	id := types.StringID(serverID)
+	for _, keys := range sequences {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := serverOp.SendKey(ctx, zone, id, &iaas.SendKeyRequest{Keys: keys}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package sakura
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextToKeySequences(t *testing.T) {
+	got, err := textToKeySequences("ls -A\r\n")
+	if err != nil {
+		t.Fatalf("textToKeySequences: %v", err)
+	}
+	want := [][]string{
+		{"l"}, {"s"}, {"spc"}, {"minus"}, {"shift", "a"}, {"ret"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got, err = textToKeySequences(`P@ss|"x"`)
+	if err != nil {
+		t.Fatalf("textToKeySequences: %v", err)
+	}
+	want = [][]string{
+		{"shift", "p"}, {"shift", "2"}, {"s"}, {"s"}, {"shift", "backslash"},
+		{"shift", "apostrophe"}, {"x"}, {"shift", "apostrophe"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTextToKeySequences_Errors(t *testing.T) {
+	if _, err := textToKeySequences("こんにちは"); err == nil {
+		t.Error("non-ASCII text should be rejected")
+	}
+	if _, err := textToKeySequences(strings.Repeat("a", maxPasteLength+1)); err == nil {
+		t.Error("too long text should be rejected")
+	}
+	if _, err := textToKeySequences("aあ!"); err == nil || !strings.Contains(err.Error(), "2文字目") {
+		t.Errorf("err = %v, want the position counted in characters", err)
+	}
+}
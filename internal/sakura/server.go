@@ -19,6 +19,9 @@ type ServerInfo struct {
 	Tags        []string `json:"tags"`
 	CDROMID     string   `json:"cdromId"`
 	CreatedAt   string   `json:"createdAt"`
+
+	PrivateHostID   string `json:"privateHostId"`
+	PrivateHostName string `json:"privateHostName"`
 }
 
 type VNCProxyInfo struct {
@@ -116,6 +119,23 @@ func (s *ServerService) ChangePlan(ctx context.Context, zone string, serverID st
 	return serverFromSDK(zone, srv), nil
 }
 
+// ChangeToPlan はカタログのプランへ変更する。CPU・メモリに加えてGPU数・専有CPUかどうか・世代も指定するため、
+// CheckPlanChangeで確認したプランとは別の世代のプランが適用されることはない。
+func (s *ServerService) ChangeToPlan(ctx context.Context, zone string, serverID string, plan *ServerPlanInfo) (*ServerInfo, error) {
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	srv, err := serverOp.ChangePlan(ctx, zone, types.StringID(serverID), &iaas.ServerChangePlanRequest{
+		CPU:                  plan.CPU,
+		MemoryMB:             plan.MemoryGB * 1024,
+		GPU:                  plan.GPU,
+		ServerPlanCommitment: types.ECommitment(plan.Commitment),
+		ServerPlanGeneration: types.EPlanGeneration(plan.Generation),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return serverFromSDK(zone, srv), nil
+}
+
 // InsertCDROM はサーバーにCD-ROM(ISOイメージ)を挿入する。
 func (s *ServerService) InsertCDROM(ctx context.Context, zone string, serverID string, cdromID string) error {
 	serverOp := iaas.NewServerOp(s.client.Caller())
@@ -187,5 +207,8 @@ func serverFromSDK(zone string, srv *iaas.Server) *ServerInfo {
 		Tags:        srv.Tags,
 		CDROMID:     srv.CDROMID.String(),
 		CreatedAt:   srv.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		PrivateHostID:   srv.PrivateHostID.String(),
+		PrivateHostName: srv.PrivateHostName,
 	}
 }
@@ -0,0 +1,261 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// ServerCloneOptions はサーバー複製のオプション。
+// スイッチ・パケットフィルタはゾーン内のリソースのため、別ゾーンへ複製する場合は
+// SwitchMapping/PacketFilterMapping で複製先のIDを指定する。指定がないNICは未接続、パケットフィルタは未適用になる。
+type ServerCloneOptions struct {
+	DestZone            string            `json:"destZone"`            // 空文字は複製元と同じゾーン
+	SwitchMapping       map[string]string `json:"switchMapping"`       // 複製元スイッチID → 複製先スイッチID
+	PacketFilterMapping map[string]string `json:"packetFilterMapping"` // 複製元パケットフィルタID → 複製先パケットフィルタID
+	Boot                bool              `json:"boot"`
+}
+
+// ServerCloneProgress はサーバー複製の各ステップの進捗。
+type ServerCloneProgress struct {
+	Step       int    `json:"step"`
+	TotalSteps int    `json:"totalSteps"`
+	Message    string `json:"message"`
+}
+
+// ServerCloneResult は複製したサーバーと、複製できなかった設定などの警告。
+type ServerCloneResult struct {
+	Server   *ServerInfo `json:"server"`
+	Warnings []string    `json:"warnings"`
+}
+
+type serverCloneReporter struct {
+	step     int
+	total    int
+	progress func(ServerCloneProgress)
+}
+
+func (r *serverCloneReporter) report(format string, args ...interface{}) {
+	r.step++
+	if r.progress != nil {
+		r.progress(ServerCloneProgress{Step: r.step, TotalSteps: r.total, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// serverCloneStepCount は進捗表示用に、複製に必要なステップ数を返す。
+// 別ゾーンへの複製ではディスクごとにアーカイブ作成・転送・ディスク作成の3ステップと、最後に一時アーカイブの削除が必要になる。
+func serverCloneStepCount(diskCount int, crossZone bool, boot bool) int {
+	steps := 2 // サーバー作成、パケットフィルタ適用
+	if crossZone {
+		steps += diskCount*3 + 1
+	} else {
+		steps += diskCount
+	}
+	if boot {
+		steps++
+	}
+	return steps
+}
+
+// cloneNICs は複製元のNIC構成から、複製先サーバーの接続先スイッチと適用するパケットフィルタを組み立てる。
+// 共有セグメントはどのゾーンでも接続できるため、そのまま共有セグメントに接続する。
+func cloneNICs(ifaces []*iaas.InterfaceView, crossZone bool, opts ServerCloneOptions) ([]*iaas.ConnectedSwitch, []types.ID, []string) {
+	switches := make([]*iaas.ConnectedSwitch, 0, len(ifaces))
+	packetFilterIDs := make([]types.ID, 0, len(ifaces))
+	var warnings []string
+
+	for i, iface := range ifaces {
+		switch {
+		case iface.SwitchScope == types.Scopes.Shared:
+			switches = append(switches, &iaas.ConnectedSwitch{Scope: types.Scopes.Shared})
+		case iface.SwitchID.IsEmpty():
+			switches = append(switches, nil)
+		default:
+			srcID := iface.SwitchID.String()
+			if dest, ok := opts.SwitchMapping[srcID]; ok && dest != "" {
+				switches = append(switches, &iaas.ConnectedSwitch{ID: types.StringID(dest)})
+			} else if !crossZone {
+				switches = append(switches, &iaas.ConnectedSwitch{ID: iface.SwitchID})
+			} else {
+				switches = append(switches, nil)
+				warnings = append(warnings, fmt.Sprintf("NIC #%d: 複製先ゾーンのスイッチが指定されていないため未接続にしました (複製元スイッチ %s)", i, srcID))
+			}
+		}
+
+		if iface.PacketFilterID.IsEmpty() {
+			packetFilterIDs = append(packetFilterIDs, types.ID(0))
+			continue
+		}
+		srcID := iface.PacketFilterID.String()
+		if dest, ok := opts.PacketFilterMapping[srcID]; ok && dest != "" {
+			packetFilterIDs = append(packetFilterIDs, types.StringID(dest))
+		} else if !crossZone {
+			packetFilterIDs = append(packetFilterIDs, iface.PacketFilterID)
+		} else {
+			packetFilterIDs = append(packetFilterIDs, types.ID(0))
+			warnings = append(warnings, fmt.Sprintf("NIC #%d: 複製先ゾーンのパケットフィルタが指定されていないため適用しませんでした (複製元パケットフィルタ %s)", i, srcID))
+		}
+	}
+	return switches, packetFilterIDs, warnings
+}
+
+// Clone は既存サーバーと同じプラン・NIC構成・ディスクを持つサーバーを作成する。
+// 同一ゾーンではディスクを直接コピーし、別ゾーンではディスクをアーカイブ化して転送してから複製する。
+// 稼働中のサーバーのディスクも複製できるが、内容の整合性のため停止してから実行することを推奨する。
+// 途中で失敗した場合は作成済みのサーバー・ディスクを削除し、一時アーカイブは成否にかかわらず削除する。
+func (s *ServerService) Clone(ctx context.Context, zone string, serverID string, newName string, opts ServerCloneOptions, progress func(ServerCloneProgress)) (*ServerCloneResult, error) {
+	if newName == "" {
+		return nil, fmt.Errorf("サーバー名を指定してください")
+	}
+	destZone := opts.DestZone
+	if destZone == "" {
+		destZone = zone
+	}
+	crossZone := destZone != zone
+	destZoneID, ok := types.ZoneIDs[destZone]
+	if crossZone && !ok {
+		return nil, fmt.Errorf("不明なゾーンです: %s", destZone)
+	}
+
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	diskOp := iaas.NewDiskOp(s.client.Caller())
+	archiveOp := iaas.NewArchiveOp(s.client.Caller())
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+
+	src, err := serverOp.Read(ctx, zone, types.StringID(serverID))
+	if err != nil {
+		return nil, err
+	}
+	disks := append([]*iaas.ServerConnectedDisk(nil), src.Disks...)
+	sort.SliceStable(disks, func(i, j int) bool { return disks[i].ConnectionOrder < disks[j].ConnectionOrder })
+
+	reporter := &serverCloneReporter{total: serverCloneStepCount(len(disks), crossZone, opts.Boot), progress: progress}
+	switches, packetFilterIDs, warnings := cloneNICs(src.Interfaces, crossZone, opts)
+	rollback := &rollbackStack{}
+
+	reporter.report("サーバー %s を作成しています", newName)
+	srv, err := serverOp.Create(ctx, destZone, &iaas.ServerCreateRequest{
+		Name:                 newName,
+		Description:          src.Description,
+		Tags:                 src.Tags,
+		CPU:                  src.CPU,
+		MemoryMB:             src.MemoryMB,
+		GPU:                  src.GPU,
+		ServerPlanCommitment: src.ServerPlanCommitment,
+		ServerPlanGeneration: src.ServerPlanGeneration,
+		InterfaceDriver:      src.InterfaceDriver,
+		ConnectedSwitches:    switches,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var createdDiskIDs []types.ID
+	rollback.push(func(ctx context.Context) error {
+		return serverOp.DeleteWithDisks(ctx, destZone, srv.ID, &iaas.ServerDeleteWithDisksRequest{IDs: createdDiskIDs})
+	})
+
+	reporter.report("パケットフィルタを適用しています")
+	for i, pfID := range packetFilterIDs {
+		if pfID.IsEmpty() || i >= len(srv.Interfaces) {
+			continue
+		}
+		if err := interfaceOp.ConnectToPacketFilter(ctx, destZone, srv.Interfaces[i].ID, pfID); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+	}
+
+	// 別ゾーンへの転送に使った一時アーカイブは、成否にかかわらず最後に削除する
+	type tempArchive struct {
+		zone string
+		id   types.ID
+	}
+	var tempArchives []tempArchive
+	cleanupTempArchives := func() {
+		for _, a := range tempArchives {
+			_ = archiveOp.Delete(context.WithoutCancel(ctx), a.zone, a.id)
+		}
+		tempArchives = nil
+	}
+	defer cleanupTempArchives()
+
+	for _, d := range disks {
+		diskReq := &iaas.DiskCreateRequest{
+			Name:       fmt.Sprintf("%s-%s", newName, d.Name),
+			Tags:       src.Tags,
+			SizeMB:     d.SizeMB,
+			DiskPlanID: d.DiskPlanID,
+			Connection: d.Connection,
+			ServerID:   srv.ID,
+		}
+
+		if crossZone {
+			reporter.report("ディスク %s からアーカイブを作成しています", d.Name)
+			archive, err := archiveOp.Create(ctx, zone, &iaas.ArchiveCreateRequest{
+				Name:         fmt.Sprintf("%s-clone-tmp", d.Name),
+				SourceDiskID: d.ID,
+			})
+			if err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+			tempArchives = append(tempArchives, tempArchive{zone: zone, id: archive.ID})
+			if err := waitArchiveReady(ctx, archiveOp, zone, archive.ID); err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+
+			reporter.report("アーカイブを %s ゾーンへ転送しています", destZone)
+			transferred, err := archiveOp.Transfer(ctx, zone, archive.ID, destZoneID, &iaas.ArchiveTransferRequest{
+				Name:   archive.Name,
+				SizeMB: archive.SizeMB,
+			})
+			if err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+			tempArchives = append(tempArchives, tempArchive{zone: destZone, id: transferred.ID})
+			if err := waitArchiveReady(ctx, archiveOp, destZone, transferred.ID); err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+			diskReq.SourceArchiveID = transferred.ID
+		} else {
+			diskReq.SourceDiskID = d.ID
+		}
+
+		reporter.report("ディスク %s を複製しています", d.Name)
+		disk, err := diskOp.Create(ctx, destZone, diskReq, nil, types.ID(0))
+		if err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+		createdDiskIDs = append(createdDiskIDs, disk.ID)
+		if _, err := iaas.WaiterForReady(func() (interface{}, error) {
+			return diskOp.Read(ctx, destZone, disk.ID)
+		}).WaitForState(ctx); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+	}
+	if crossZone {
+		reporter.report("一時アーカイブを削除しています")
+		cleanupTempArchives()
+	}
+
+	if opts.Boot {
+		reporter.report("サーバーを起動しています")
+		if err := serverOp.Boot(ctx, destZone, srv.ID); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+	}
+
+	created, err := serverOp.Read(ctx, destZone, srv.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ServerCloneResult{Server: serverFromSDK(destZone, created), Warnings: warnings}, nil
+}
+
+func waitArchiveReady(ctx context.Context, archiveOp iaas.ArchiveAPI, zone string, id types.ID) error {
+	_, err := iaas.WaiterForReady(func() (interface{}, error) {
+		return archiveOp.Read(ctx, zone, id)
+	}).WaitForState(ctx)
+	return err
+}
@@ -0,0 +1,103 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func TestServerService_Clone(t *testing.T) {
+	service := newTestServerService(t)
+	ctx := context.Background()
+
+	src, err := createTestServer(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+
+	var steps []ServerCloneProgress
+	result, err := service.Clone(ctx, "is1a", src.ID.String(), "test-server-clone", ServerCloneOptions{}, func(p ServerCloneProgress) {
+		steps = append(steps, p)
+	})
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if result.Server.Name != "test-server-clone" {
+		t.Errorf("Name = %q, want %q", result.Server.Name, "test-server-clone")
+	}
+	if result.Server.ID == src.ID.String() {
+		t.Error("cloned server should have a new ID")
+	}
+	if result.Server.CPU != src.CPU {
+		t.Errorf("CPU = %d, want %d", result.Server.CPU, src.CPU)
+	}
+	if len(steps) == 0 || steps[len(steps)-1].Step != steps[len(steps)-1].TotalSteps {
+		t.Errorf("progress should end at the last step: %+v", steps)
+	}
+}
+
+func TestCloneNICs(t *testing.T) {
+	ifaces := []*iaas.InterfaceView{
+		{SwitchScope: types.Scopes.Shared, PacketFilterID: types.ID(600)},
+		{SwitchID: types.ID(500)},
+		{SwitchID: types.ID(501), PacketFilterID: types.ID(601)},
+		{},
+	}
+
+	t.Run("same zone", func(t *testing.T) {
+		switches, pfs, warnings := cloneNICs(ifaces, false, ServerCloneOptions{})
+		if switches[0].Scope != types.Scopes.Shared || switches[1].ID != types.ID(500) || switches[2].ID != types.ID(501) || switches[3] != nil {
+			t.Errorf("switches = %+v", switches)
+		}
+		if pfs[0] != types.ID(600) || !pfs[1].IsEmpty() || pfs[2] != types.ID(601) {
+			t.Errorf("packet filters = %v", pfs)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("warnings = %v, want none", warnings)
+		}
+	})
+
+	t.Run("cross zone", func(t *testing.T) {
+		switches, pfs, warnings := cloneNICs(ifaces, true, ServerCloneOptions{
+			SwitchMapping:       map[string]string{"500": "900"},
+			PacketFilterMapping: map[string]string{"600": "950"},
+		})
+		if switches[0].Scope != types.Scopes.Shared {
+			t.Errorf("shared NIC should stay on the shared segment: %+v", switches[0])
+		}
+		if switches[1].ID != types.ID(900) {
+			t.Errorf("mapped switch = %v, want 900", switches[1].ID)
+		}
+		if switches[2] != nil {
+			t.Errorf("unmapped switch should be disconnected: %+v", switches[2])
+		}
+		if pfs[0] != types.ID(950) || !pfs[2].IsEmpty() {
+			t.Errorf("packet filters = %v", pfs)
+		}
+		// 未対応のスイッチとパケットフィルタがそれぞれ1件ずつ
+		if len(warnings) != 2 {
+			t.Errorf("warnings = %v, want 2", warnings)
+		}
+	})
+}
+
+func TestServerCloneStepCount(t *testing.T) {
+	tests := []struct {
+		diskCount int
+		crossZone bool
+		boot      bool
+		want      int
+	}{
+		{diskCount: 0, want: 2},
+		{diskCount: 2, want: 4},
+		{diskCount: 2, boot: true, want: 5},
+		{diskCount: 2, crossZone: true, want: 2 + 6 + 1},
+	}
+	for _, tt := range tests {
+		if got := serverCloneStepCount(tt.diskCount, tt.crossZone, tt.boot); got != tt.want {
+			t.Errorf("serverCloneStepCount(%d, %v, %v) = %d, want %d", tt.diskCount, tt.crossZone, tt.boot, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package sakura
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// ServerNICUpstreamShared はNICを共有セグメント(インターネット)に接続することを表す。
+// ServerNICInput.Upstream に空文字を指定した場合はどこにも接続しない。
+const ServerNICUpstreamShared = "shared"
+
+// ServerCreateInput はサーバー作成ウィザードの入力一式。
+type ServerCreateInput struct {
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	Tags            []string         `json:"tags"`
+	CPU             int              `json:"cpu"`
+	MemoryGB        int              `json:"memoryGb"`
+	GPU             int              `json:"gpu"`
+	Commitment      string           `json:"commitment"` // "standard"/"dedicatedcpu"
+	Generation      int              `json:"generation"` // 0はゾーンのデフォルト世代
+	InterfaceDriver string           `json:"interfaceDriver"`
+	Disk            *ServerDiskInput `json:"disk"`
+	NICs            []ServerNICInput `json:"nics"`
+	Boot            bool             `json:"boot"`
+	PrivateHostID   string           `json:"privateHostId"` // 空文字は共有ホストに配置する
+}
+
+// ServerDiskInput は作成時に接続するブートディスク。SourceArchiveIDが空の場合はブランクディスクを作成する。
+type ServerDiskInput struct {
+	Name            string               `json:"name"`
+	SizeGB          int                  `json:"sizeGb"`
+	DiskPlan        string               `json:"diskPlan"`   // "ssd"/"hdd"
+	Connection      string               `json:"connection"` // "virtio"/"ide"
+	SourceArchiveID string               `json:"sourceArchiveId"`
+	Edit            *ServerDiskEditInput `json:"edit"`
+}
+
+// ServerDiskEditInput はディスクの修正(ホスト名・パスワード・SSH鍵・IPアドレス・スタートアップスクリプト)のパラメータ。
+type ServerDiskEditInput struct {
+	HostName       string               `json:"hostName"`
+	Password       string               `json:"password"`
+	SSHKeyIDs      []string             `json:"sshKeyIds"`
+	SSHPublicKeys  []string             `json:"sshPublicKeys"`
+	DisablePWAuth  bool                 `json:"disablePwAuth"`
+	UserIPAddress  string               `json:"userIpAddress"`
+	DefaultRoute   string               `json:"defaultRoute"`
+	NetworkMaskLen int                  `json:"networkMaskLen"`
+	Notes          []ServerDiskEditNote `json:"notes"`
+}
+
+// ServerDiskEditNote はディスクの修正で実行するスタートアップスクリプトと、その変数。
+// Variablesはさくらのクラウドの @sacloud-* 変数、TemplateVariablesはSakPilotのテンプレート変数 {{ name }} の値。
+type ServerDiskEditNote struct {
+	ID                string            `json:"id"`
+	Variables         map[string]string `json:"variables"`
+	TemplateVariables map[string]string `json:"templateVariables"`
+}
+
+// ServerNICInput は作成時のNIC。Upstreamは "shared"、スイッチID、または空文字(未接続)。
+type ServerNICInput struct {
+	Upstream       string `json:"upstream"`
+	PacketFilterID string `json:"packetFilterId"`
+}
+
+// rollbackStack は複数ステップからなる作成処理の途中で失敗した場合に、作成済みのリソースを逆順に削除する。
+type rollbackStack struct {
+	steps []func(ctx context.Context) error
+}
+
+func (r *rollbackStack) push(step func(ctx context.Context) error) {
+	r.steps = append(r.steps, step)
+}
+
+// run は登録された後始末を逆順に全て実行し、元のエラーと後始末のエラーをまとめて返す。
+// 操作のキャンセルで失敗した場合も後始末は行う必要があるため、ctxのキャンセルは引き継がない。
+func (r *rollbackStack) run(ctx context.Context, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	errs := []error{cause}
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		if err := r.steps[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("ロールバックに失敗しました: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (in *ServerCreateInput) validate() error {
+	if in.Name == "" {
+		return fmt.Errorf("サーバー名を指定してください")
+	}
+	if in.CPU <= 0 || in.MemoryGB <= 0 {
+		return fmt.Errorf("CPU/メモリを指定してください")
+	}
+	if in.Disk != nil {
+		if in.Disk.SizeGB <= 0 {
+			return fmt.Errorf("ディスクサイズを指定してください")
+		}
+		if in.Disk.Edit != nil && in.Disk.SourceArchiveID == "" {
+			return fmt.Errorf("ディスクの修正にはコピー元アーカイブの指定が必要です")
+		}
+	}
+	return nil
+}
+
+func toConnectedSwitches(nics []ServerNICInput) []*iaas.ConnectedSwitch {
+	switches := make([]*iaas.ConnectedSwitch, 0, len(nics))
+	for _, nic := range nics {
+		switch nic.Upstream {
+		case ServerNICUpstreamShared:
+			switches = append(switches, &iaas.ConnectedSwitch{Scope: types.Scopes.Shared})
+		case "":
+			switches = append(switches, nil)
+		default:
+			switches = append(switches, &iaas.ConnectedSwitch{ID: types.StringID(nic.Upstream)})
+		}
+	}
+	return switches
+}
+
+func toDiskEditRequest(edit *ServerDiskEditInput) *iaas.DiskEditRequest {
+	req := &iaas.DiskEditRequest{
+		HostName:      edit.HostName,
+		Password:      edit.Password,
+		DisablePWAuth: edit.DisablePWAuth,
+		UserIPAddress: edit.UserIPAddress,
+	}
+	for _, id := range edit.SSHKeyIDs {
+		req.SSHKeys = append(req.SSHKeys, &iaas.DiskEditSSHKey{ID: types.StringID(id)})
+	}
+	for _, key := range edit.SSHPublicKeys {
+		req.SSHKeys = append(req.SSHKeys, &iaas.DiskEditSSHKey{PublicKey: key})
+	}
+	if edit.DefaultRoute != "" || edit.NetworkMaskLen > 0 {
+		req.UserSubnet = &iaas.DiskEditUserSubnet{
+			DefaultRoute:   edit.DefaultRoute,
+			NetworkMaskLen: edit.NetworkMaskLen,
+		}
+	}
+	for _, note := range edit.Notes {
+		vars := make(map[string]interface{}, len(note.Variables))
+		for k, v := range note.Variables {
+			vars[k] = v
+		}
+		req.Notes = append(req.Notes, &iaas.DiskEditNote{ID: types.StringID(note.ID), Variables: vars})
+	}
+	return req
+}
+
+// Create はサーバー本体・ブートディスク・NIC・パケットフィルタをまとめて作成し、必要に応じて起動する。
+// 途中のステップで失敗した場合は、それまでに作成したディスク・サーバーを削除してからエラーを返す。
+func (s *ServerService) Create(ctx context.Context, zone string, input ServerCreateInput) (*ServerInfo, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	diskOp := iaas.NewDiskOp(s.client.Caller())
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	rollback := &rollbackStack{}
+
+	req := &iaas.ServerCreateRequest{
+		Name:                 input.Name,
+		Description:          input.Description,
+		Tags:                 input.Tags,
+		CPU:                  input.CPU,
+		MemoryMB:             input.MemoryGB * 1024,
+		GPU:                  input.GPU,
+		ServerPlanCommitment: types.ECommitment(input.Commitment),
+		ServerPlanGeneration: types.EPlanGeneration(input.Generation),
+		ConnectedSwitches:    toConnectedSwitches(input.NICs),
+	}
+	if req.ServerPlanCommitment == "" {
+		req.ServerPlanCommitment = types.Commitments.Standard
+	}
+	if input.InterfaceDriver != "" {
+		req.InterfaceDriver = types.EInterfaceDriver(input.InterfaceDriver)
+	}
+	if input.PrivateHostID != "" {
+		req.PrivateHostID = types.StringID(input.PrivateHostID)
+	}
+
+	srv, err := serverOp.Create(ctx, zone, req)
+	if err != nil {
+		return nil, err
+	}
+	// 作成済みのディスクはサーバーと一緒に削除する
+	var createdDiskIDs []types.ID
+	rollback.push(func(ctx context.Context) error {
+		return serverOp.DeleteWithDisks(ctx, zone, srv.ID, &iaas.ServerDeleteWithDisksRequest{IDs: createdDiskIDs})
+	})
+
+	for i, nic := range input.NICs {
+		if nic.PacketFilterID == "" {
+			continue
+		}
+		if i >= len(srv.Interfaces) {
+			return nil, rollback.run(ctx, fmt.Errorf("NIC #%d が見つかりません", i))
+		}
+		if err := interfaceOp.ConnectToPacketFilter(ctx, zone, srv.Interfaces[i].ID, types.StringID(nic.PacketFilterID)); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+	}
+
+	if input.Disk != nil {
+		diskName := input.Disk.Name
+		if diskName == "" {
+			diskName = input.Name
+		}
+		diskReq := &iaas.DiskCreateRequest{
+			Name:       diskName,
+			Tags:       input.Tags,
+			SizeMB:     input.Disk.SizeGB * 1024,
+			DiskPlanID: types.DiskPlanIDMap[input.Disk.DiskPlan],
+			Connection: types.DiskConnectionMap[input.Disk.Connection],
+			ServerID:   srv.ID,
+		}
+		if diskReq.DiskPlanID.IsEmpty() {
+			diskReq.DiskPlanID = types.DiskPlans.SSD
+		}
+		if diskReq.Connection == "" {
+			diskReq.Connection = types.DiskConnections.VirtIO
+		}
+		if input.Disk.SourceArchiveID != "" {
+			diskReq.SourceArchiveID = types.StringID(input.Disk.SourceArchiveID)
+		}
+
+		disk, err := diskOp.Create(ctx, zone, diskReq, nil, types.ID(0))
+		if err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+		createdDiskIDs = append(createdDiskIDs, disk.ID)
+
+		waitDiskReady := func() error {
+			_, err := iaas.WaiterForReady(func() (interface{}, error) {
+				return diskOp.Read(ctx, zone, disk.ID)
+			}).WaitForState(ctx)
+			return err
+		}
+		// アーカイブからのコピー完了を待ってからディスクの修正を行う
+		if err := waitDiskReady(); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+		if input.Disk.Edit != nil {
+			edit := *input.Disk.Edit
+			notes, cleanupNotes, err := renderTemplateNotes(ctx, iaas.NewNoteOp(s.client.Caller()), edit.Notes)
+			if err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+			edit.Notes = notes
+			// ディスクの修正は非同期に行われるため、修正が終わる前に起動しないよう完了を待つ。
+			// 一時スクリプトも修正中に参照されるため、完了を待ってから削除する
+			err = diskOp.Config(ctx, zone, disk.ID, toDiskEditRequest(&edit))
+			if err == nil {
+				err = waitDiskReady()
+			}
+			cleanupNotes()
+			if err != nil {
+				return nil, rollback.run(ctx, err)
+			}
+		}
+	}
+
+	if input.Boot {
+		if err := serverOp.Boot(ctx, zone, srv.ID); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+	}
+
+	created, err := serverOp.Read(ctx, zone, srv.ID)
+	if err != nil {
+		return nil, err
+	}
+	return serverFromSDK(zone, created), nil
+}
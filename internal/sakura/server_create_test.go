@@ -0,0 +1,163 @@
+package sakura
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func TestServerService_Create(t *testing.T) {
+	service := newTestServerService(t)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "is1a", ServerCreateInput{
+		Name:     "wizard-server",
+		CPU:      2,
+		MemoryGB: 4,
+		Tags:     []string{"wizard"},
+		Disk: &ServerDiskInput{
+			SizeGB:     20,
+			DiskPlan:   "ssd",
+			Connection: "virtio",
+		},
+		NICs: []ServerNICInput{{Upstream: ServerNICUpstreamShared}},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.Name != "wizard-server" {
+		t.Errorf("Name = %q, want %q", created.Name, "wizard-server")
+	}
+	if created.CPU != 2 || created.Memory != 4 {
+		t.Errorf("CPU/Memory = %d/%d, want 2/4", created.CPU, created.Memory)
+	}
+
+	disks, err := NewDiskService(&Client{}).List(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("DiskService.List: %v", err)
+	}
+	found := false
+	for _, d := range disks {
+		if d.ServerID == created.ID {
+			found = true
+			if d.Name != "wizard-server" {
+				t.Errorf("disk Name = %q, want server name %q", d.Name, "wizard-server")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no disk connected to created server %q: %+v", created.ID, disks)
+	}
+}
+
+func TestServerCreateInput_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   ServerCreateInput
+		wantErr bool
+	}{
+		{name: "ok", input: ServerCreateInput{Name: "a", CPU: 1, MemoryGB: 1}},
+		{name: "no name", input: ServerCreateInput{CPU: 1, MemoryGB: 1}, wantErr: true},
+		{name: "no plan", input: ServerCreateInput{Name: "a"}, wantErr: true},
+		{name: "no disk size", input: ServerCreateInput{Name: "a", CPU: 1, MemoryGB: 1, Disk: &ServerDiskInput{}}, wantErr: true},
+		{
+			name: "edit without archive",
+			input: ServerCreateInput{Name: "a", CPU: 1, MemoryGB: 1, Disk: &ServerDiskInput{
+				SizeGB: 20,
+				Edit:   &ServerDiskEditInput{HostName: "a"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToConnectedSwitches(t *testing.T) {
+	switches := toConnectedSwitches([]ServerNICInput{
+		{Upstream: ServerNICUpstreamShared},
+		{Upstream: "123456789012"},
+		{Upstream: ""},
+	})
+	if len(switches) != 3 {
+		t.Fatalf("len = %d, want 3", len(switches))
+	}
+	if switches[0].Scope != types.Scopes.Shared {
+		t.Errorf("switches[0].Scope = %q, want shared", switches[0].Scope)
+	}
+	if switches[1].ID != types.StringID("123456789012") {
+		t.Errorf("switches[1].ID = %v, want 123456789012", switches[1].ID)
+	}
+	if switches[2] != nil {
+		t.Errorf("switches[2] = %+v, want nil (disconnected NIC)", switches[2])
+	}
+}
+
+func TestToDiskEditRequest(t *testing.T) {
+	req := toDiskEditRequest(&ServerDiskEditInput{
+		HostName:       "web1",
+		Password:       "secret",
+		SSHKeyIDs:      []string{"111"},
+		SSHPublicKeys:  []string{"ssh-ed25519 AAAA test"},
+		UserIPAddress:  "192.0.2.10",
+		DefaultRoute:   "192.0.2.1",
+		NetworkMaskLen: 24,
+		Notes:          []ServerDiskEditNote{{ID: "222", Variables: map[string]string{"user": "admin"}}},
+	})
+	if req.HostName != "web1" || req.Password != "secret" {
+		t.Errorf("HostName/Password = %q/%q", req.HostName, req.Password)
+	}
+	if len(req.SSHKeys) != 2 || req.SSHKeys[0].ID != types.StringID("111") || req.SSHKeys[1].PublicKey != "ssh-ed25519 AAAA test" {
+		t.Errorf("SSHKeys = %+v", req.SSHKeys)
+	}
+	if req.UserSubnet == nil || req.UserSubnet.DefaultRoute != "192.0.2.1" || req.UserSubnet.NetworkMaskLen != 24 {
+		t.Errorf("UserSubnet = %+v", req.UserSubnet)
+	}
+	if len(req.Notes) != 1 || !reflect.DeepEqual(req.Notes[0].Variables, map[string]interface{}{"user": "admin"}) {
+		t.Errorf("Notes = %+v", req.Notes)
+	}
+}
+
+func TestRollbackStack_RunsInReverseOrder(t *testing.T) {
+	var order []string
+	r := &rollbackStack{}
+	r.push(func(ctx context.Context) error { order = append(order, "server"); return nil })
+	r.push(func(ctx context.Context) error { order = append(order, "disk"); return errors.New("disk busy") })
+
+	cause := errors.New("boot failed")
+	err := r.run(context.Background(), cause)
+
+	if !reflect.DeepEqual(order, []string{"disk", "server"}) {
+		t.Errorf("order = %v, want [disk server]", order)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("err = %v, want to wrap cause", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "disk busy") {
+		t.Errorf("err = %v, want to include rollback failure", err)
+	}
+}
+
+func TestRollbackStack_RunsAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var stepErr error
+	r := &rollbackStack{}
+	r.push(func(ctx context.Context) error { stepErr = ctx.Err(); return nil })
+	_ = r.run(ctx, context.Canceled)
+
+	if stepErr != nil {
+		t.Errorf("rollback step got cancelled context: %v", stepErr)
+	}
+}
@@ -0,0 +1,445 @@
+package sakura
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// サーバー移行の状態。
+const (
+	ServerMigrationRunning   = "running"
+	ServerMigrationFailed    = "failed"
+	ServerMigrationCompleted = "completed"
+)
+
+// ServerMigrationOptions はサーバーを別ゾーンへ移行するときのオプション。
+// スイッチ・パケットフィルタの対応付けはServerCloneOptionsと同じ扱いで、指定がないNICは未接続、パケットフィルタは未適用になる。
+type ServerMigrationOptions struct {
+	DestZone            string            `json:"destZone"`
+	NewName             string            `json:"newName"` // 空文字は移行元と同じ名前
+	SwitchMapping       map[string]string `json:"switchMapping"`
+	PacketFilterMapping map[string]string `json:"packetFilterMapping"`
+	Boot                bool              `json:"boot"`
+	DeleteSource        bool              `json:"deleteSource"` // 移行後に移行元サーバーと接続されていたディスクを削除する
+}
+
+// ServerMigrationDisk は移行するディスクごとの進捗。作成したリソースのIDは作成直後に記録する。
+type ServerMigrationDisk struct {
+	SourceDiskID    string `json:"sourceDiskId"`
+	Name            string `json:"name"`
+	SizeMB          int    `json:"sizeMb"`
+	DiskPlanID      string `json:"diskPlanId"`
+	Connection      string `json:"connection"`
+	SourceArchiveID string `json:"sourceArchiveId"` // 移行元ゾーンに作成した一時アーカイブ
+	DestArchiveID   string `json:"destArchiveId"`   // 移行先ゾーンへ転送した一時アーカイブ
+	DestDiskID      string `json:"destDiskId"`
+}
+
+// ServerMigrationJournal はサーバー移行のチェックポイント。
+// ステップが完了するたびに保存し、失敗した場合は記録済みのステップを飛ばして再開する。
+type ServerMigrationJournal struct {
+	ID                   string                 `json:"id"`
+	SourceZone           string                 `json:"sourceZone"`
+	SourceServerID       string                 `json:"sourceServerId"`
+	Options              ServerMigrationOptions `json:"options"`
+	Status               string                 `json:"status"`
+	Error                string                 `json:"error"`
+	Warnings             []string               `json:"warnings"`
+	SourceStopped        bool                   `json:"sourceStopped"`
+	Disks                []ServerMigrationDisk  `json:"disks"`
+	DestServerID         string                 `json:"destServerId"`
+	PacketFiltersApplied bool                   `json:"packetFiltersApplied"`
+	TempArchivesDeleted  bool                   `json:"tempArchivesDeleted"`
+	Booted               bool                   `json:"booted"`
+	SourceDeleted        bool                   `json:"sourceDeleted"`
+	CreatedAt            string                 `json:"createdAt"`
+	UpdatedAt            string                 `json:"updatedAt"`
+}
+
+// ServerMigrationProgress はサーバー移行の進捗。
+type ServerMigrationProgress struct {
+	MigrationID string `json:"migrationId"`
+	Message     string `json:"message"`
+}
+
+// ServerMigrationStore はサーバー移行のジャーナルをディレクトリ内に1件1ファイルのJSONとして保存する。
+type ServerMigrationStore struct {
+	dir string
+}
+
+func NewServerMigrationStore(dir string) *ServerMigrationStore {
+	return &ServerMigrationStore{dir: dir}
+}
+
+// path はジャーナルのファイルパスを返す。IDはフロントエンドから渡されるため、ディレクトリの外を指せないよう検証する。
+func (s *ServerMigrationStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("移行IDが正しくありません: %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// Save はジャーナルを保存する。書き込み途中で中断されても既存の内容が壊れないよう、一時ファイルに書いてから置き換える。
+func (s *ServerMigrationStore) Save(j *ServerMigrationJournal) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	path, err := s.path(j.ID)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *ServerMigrationStore) Load(id string) (*ServerMigrationJournal, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var j ServerMigrationJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("移行ジャーナル %s を読み込めません: %w", id, err)
+	}
+	return &j, nil
+}
+
+// List は保存されているジャーナルを新しい順に返す。
+func (s *ServerMigrationStore) List() ([]ServerMigrationJournal, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	journals := make([]ServerMigrationJournal, 0, len(matches))
+	for _, m := range matches {
+		j, err := s.Load(strings.TrimSuffix(filepath.Base(m), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		journals = append(journals, *j)
+	}
+	sort.SliceStable(journals, func(i, k int) bool { return journals[i].CreatedAt > journals[k].CreatedAt })
+	return journals, nil
+}
+
+func (s *ServerMigrationStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Migrate はサーバーを別ゾーンへ移行する。
+// 移行元サーバーを停止してディスクをアーカイブ化・転送し、移行先ゾーンに同じプラン・NIC構成のサーバーとディスクを作成する。
+// 進捗はstoreのジャーナルに記録し、途中で失敗した場合はResumeMigrationで続きから再開できる。
+func (s *ServerService) Migrate(ctx context.Context, zone string, serverID string, opts ServerMigrationOptions, store *ServerMigrationStore, progress func(ServerMigrationProgress)) (*ServerMigrationJournal, error) {
+	if opts.DestZone == "" || opts.DestZone == zone {
+		return nil, fmt.Errorf("移行元と異なるゾーンを指定してください")
+	}
+	if _, ok := types.ZoneIDs[opts.DestZone]; !ok {
+		return nil, fmt.Errorf("不明なゾーンです: %s", opts.DestZone)
+	}
+
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	src, err := serverOp.Read(ctx, zone, types.StringID(serverID))
+	if err != nil {
+		return nil, err
+	}
+	if opts.NewName == "" {
+		opts.NewName = src.Name
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	j := &ServerMigrationJournal{
+		ID:             fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), serverID),
+		SourceZone:     zone,
+		SourceServerID: serverID,
+		Options:        opts,
+		Status:         ServerMigrationRunning,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	disks := append([]*iaas.ServerConnectedDisk(nil), src.Disks...)
+	sort.SliceStable(disks, func(i, k int) bool { return disks[i].ConnectionOrder < disks[k].ConnectionOrder })
+	for _, d := range disks {
+		j.Disks = append(j.Disks, ServerMigrationDisk{
+			SourceDiskID: d.ID.String(),
+			Name:         d.Name,
+			SizeMB:       d.SizeMB,
+			DiskPlanID:   d.DiskPlanID.String(),
+			Connection:   string(d.Connection),
+		})
+	}
+	if err := store.Save(j); err != nil {
+		return nil, err
+	}
+	return s.runMigration(ctx, j, store, progress)
+}
+
+// ResumeMigration は失敗・中断したサーバー移行を、ジャーナルに記録されたステップの続きから再開する。
+func (s *ServerService) ResumeMigration(ctx context.Context, migrationID string, store *ServerMigrationStore, progress func(ServerMigrationProgress)) (*ServerMigrationJournal, error) {
+	j, err := store.Load(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	if j.Status == ServerMigrationCompleted {
+		return j, nil
+	}
+	j.Status = ServerMigrationRunning
+	j.Error = ""
+	return s.runMigration(ctx, j, store, progress)
+}
+
+func (s *ServerService) runMigration(ctx context.Context, j *ServerMigrationJournal, store *ServerMigrationStore, progress func(ServerMigrationProgress)) (*ServerMigrationJournal, error) {
+	serverOp := iaas.NewServerOp(s.client.Caller())
+	diskOp := iaas.NewDiskOp(s.client.Caller())
+	archiveOp := iaas.NewArchiveOp(s.client.Caller())
+	interfaceOp := iaas.NewInterfaceOp(s.client.Caller())
+	srcZone, destZone := j.SourceZone, j.Options.DestZone
+	srcID := types.StringID(j.SourceServerID)
+
+	report := func(format string, args ...interface{}) {
+		if progress != nil {
+			progress(ServerMigrationProgress{MigrationID: j.ID, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+	checkpoint := func() error {
+		j.UpdatedAt = time.Now().Format(time.RFC3339)
+		return store.Save(j)
+	}
+	fail := func(err error) (*ServerMigrationJournal, error) {
+		j.Status = ServerMigrationFailed
+		j.Error = err.Error()
+		// ジャーナルの保存に失敗しても元のエラーを返す。Wailsはエラー時に戻り値を捨てるため、再開に使う移行IDをエラーに含める
+		_ = checkpoint()
+		return j, fmt.Errorf("移行 %s: %w", j.ID, err)
+	}
+
+	if !j.SourceStopped {
+		src, err := serverOp.Read(ctx, srcZone, srcID)
+		if err != nil {
+			return fail(err)
+		}
+		if src.InstanceStatus.IsUp() {
+			report("移行元サーバー %s を停止しています", src.Name)
+			if err := serverOp.Shutdown(ctx, srcZone, srcID, &iaas.ShutdownOption{Force: false}); err != nil {
+				return fail(err)
+			}
+			if _, err := iaas.WaiterForDown(func() (interface{}, error) {
+				return serverOp.Read(ctx, srcZone, srcID)
+			}).WaitForState(ctx); err != nil {
+				return fail(err)
+			}
+		}
+		j.SourceStopped = true
+		if err := checkpoint(); err != nil {
+			return fail(err)
+		}
+	}
+
+	for i := range j.Disks {
+		d := &j.Disks[i]
+		if d.DestArchiveID != "" {
+			continue
+		}
+		if d.SourceArchiveID == "" {
+			report("ディスク %s からアーカイブを作成しています", d.Name)
+			archive, err := archiveOp.Create(ctx, srcZone, &iaas.ArchiveCreateRequest{
+				Name:         fmt.Sprintf("%s-migration-tmp", d.Name),
+				SourceDiskID: types.StringID(d.SourceDiskID),
+			})
+			if err != nil {
+				return fail(err)
+			}
+			d.SourceArchiveID = archive.ID.String()
+			if err := checkpoint(); err != nil {
+				return fail(err)
+			}
+		}
+		if err := waitArchiveReady(ctx, archiveOp, srcZone, types.StringID(d.SourceArchiveID)); err != nil {
+			return fail(err)
+		}
+
+		report("ディスク %s のアーカイブを %s ゾーンへ転送しています", d.Name, destZone)
+		transferred, err := archiveOp.Transfer(ctx, srcZone, types.StringID(d.SourceArchiveID), types.ZoneIDs[destZone], &iaas.ArchiveTransferRequest{
+			Name:   fmt.Sprintf("%s-migration-tmp", d.Name),
+			SizeMB: d.SizeMB,
+		})
+		if err != nil {
+			return fail(err)
+		}
+		d.DestArchiveID = transferred.ID.String()
+		if err := checkpoint(); err != nil {
+			return fail(err)
+		}
+	}
+
+	if j.DestServerID == "" || !j.PacketFiltersApplied {
+		src, err := serverOp.Read(ctx, srcZone, srcID)
+		if err != nil {
+			return fail(err)
+		}
+		switches, packetFilterIDs, warnings := cloneNICs(src.Interfaces, true, ServerCloneOptions{
+			SwitchMapping:       j.Options.SwitchMapping,
+			PacketFilterMapping: j.Options.PacketFilterMapping,
+		})
+
+		if j.DestServerID == "" {
+			report("移行先ゾーンにサーバー %s を作成しています", j.Options.NewName)
+			srv, err := serverOp.Create(ctx, destZone, &iaas.ServerCreateRequest{
+				Name:                 j.Options.NewName,
+				Description:          src.Description,
+				Tags:                 src.Tags,
+				CPU:                  src.CPU,
+				MemoryMB:             src.MemoryMB,
+				GPU:                  src.GPU,
+				ServerPlanCommitment: src.ServerPlanCommitment,
+				ServerPlanGeneration: src.ServerPlanGeneration,
+				InterfaceDriver:      src.InterfaceDriver,
+				ConnectedSwitches:    switches,
+			})
+			if err != nil {
+				return fail(err)
+			}
+			j.DestServerID = srv.ID.String()
+			j.Warnings = warnings
+			if err := checkpoint(); err != nil {
+				return fail(err)
+			}
+		}
+
+		report("パケットフィルタを適用しています")
+		dest, err := serverOp.Read(ctx, destZone, types.StringID(j.DestServerID))
+		if err != nil {
+			return fail(err)
+		}
+		for i, pfID := range packetFilterIDs {
+			if pfID.IsEmpty() || i >= len(dest.Interfaces) || dest.Interfaces[i].PacketFilterID == pfID {
+				continue
+			}
+			if err := interfaceOp.ConnectToPacketFilter(ctx, destZone, dest.Interfaces[i].ID, pfID); err != nil {
+				return fail(err)
+			}
+		}
+		j.PacketFiltersApplied = true
+		if err := checkpoint(); err != nil {
+			return fail(err)
+		}
+	}
+
+	for i := range j.Disks {
+		d := &j.Disks[i]
+		if d.DestDiskID == "" {
+			if err := waitArchiveReady(ctx, archiveOp, destZone, types.StringID(d.DestArchiveID)); err != nil {
+				return fail(err)
+			}
+			report("移行先ゾーンにディスク %s を作成しています", d.Name)
+			disk, err := diskOp.Create(ctx, destZone, &iaas.DiskCreateRequest{
+				Name:            d.Name,
+				SizeMB:          d.SizeMB,
+				DiskPlanID:      types.StringID(d.DiskPlanID),
+				Connection:      types.EDiskConnection(d.Connection),
+				SourceArchiveID: types.StringID(d.DestArchiveID),
+				ServerID:        types.StringID(j.DestServerID),
+			}, nil, types.ID(0))
+			if err != nil {
+				return fail(err)
+			}
+			d.DestDiskID = disk.ID.String()
+			if err := checkpoint(); err != nil {
+				return fail(err)
+			}
+		}
+		if _, err := iaas.WaiterForReady(func() (interface{}, error) {
+			return diskOp.Read(ctx, destZone, types.StringID(d.DestDiskID))
+		}).WaitForState(ctx); err != nil {
+			return fail(err)
+		}
+	}
+
+	if !j.TempArchivesDeleted {
+		report("一時アーカイブを削除しています")
+		for _, d := range j.Disks {
+			if err := deleteArchiveIfExists(ctx, archiveOp, srcZone, d.SourceArchiveID); err != nil {
+				return fail(err)
+			}
+			if err := deleteArchiveIfExists(ctx, archiveOp, destZone, d.DestArchiveID); err != nil {
+				return fail(err)
+			}
+		}
+		j.TempArchivesDeleted = true
+		if err := checkpoint(); err != nil {
+			return fail(err)
+		}
+	}
+
+	if j.Options.Boot && !j.Booted {
+		report("移行先サーバーを起動しています")
+		if err := serverOp.Boot(ctx, destZone, types.StringID(j.DestServerID)); err != nil {
+			return fail(err)
+		}
+		j.Booted = true
+		if err := checkpoint(); err != nil {
+			return fail(err)
+		}
+	}
+
+	// 未接続のNICや未適用のパケットフィルタがある場合、移行先だけでは元の構成を再現できていないため、
+	// 移行元を残して利用者に確認を委ねる
+	if j.Options.DeleteSource && !j.SourceDeleted && len(j.Warnings) > 0 {
+		j.Warnings = append(j.Warnings, "移行先の構成に警告があるため、移行元サーバーは削除していません。移行先を確認してから削除してください")
+	} else if j.Options.DeleteSource && !j.SourceDeleted {
+		report("移行元サーバーを削除しています")
+		diskIDs := make([]types.ID, 0, len(j.Disks))
+		for _, d := range j.Disks {
+			diskIDs = append(diskIDs, types.StringID(d.SourceDiskID))
+		}
+		err := serverOp.DeleteWithDisks(ctx, srcZone, srcID, &iaas.ServerDeleteWithDisksRequest{IDs: diskIDs})
+		if err != nil && !iaas.IsNotFoundError(err) {
+			return fail(err)
+		}
+		j.SourceDeleted = true
+		if err := checkpoint(); err != nil {
+			return fail(err)
+		}
+	}
+
+	j.Status = ServerMigrationCompleted
+	if err := checkpoint(); err != nil {
+		return j, err
+	}
+	report("移行が完了しました")
+	return j, nil
+}
+
+// deleteArchiveIfExists は一時アーカイブを削除する。再開時に削除済みのアーカイブは無視する。
+func deleteArchiveIfExists(ctx context.Context, archiveOp iaas.ArchiveAPI, zone string, id string) error {
+	if id == "" {
+		return nil
+	}
+	if err := archiveOp.Delete(ctx, zone, types.StringID(id)); err != nil && !iaas.IsNotFoundError(err) {
+		return err
+	}
+	return nil
+}
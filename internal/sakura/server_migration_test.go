@@ -0,0 +1,187 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func TestServerService_Migrate(t *testing.T) {
+	service := newTestServerService(t)
+	store := NewServerMigrationStore(t.TempDir())
+	ctx := context.Background()
+
+	src, err := createTestServer(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+
+	var messages []string
+	j, err := service.Migrate(ctx, "is1a", src.ID.String(), ServerMigrationOptions{DestZone: "is1b", DeleteSource: true}, store, func(p ServerMigrationProgress) {
+		messages = append(messages, p.Message)
+	})
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if j.Status != ServerMigrationCompleted || j.DestServerID == "" || !j.SourceDeleted {
+		t.Errorf("journal = %+v", j)
+	}
+	if len(messages) == 0 {
+		t.Error("progress should be reported")
+	}
+
+	dest, err := iaas.NewServerOp(nil).Read(ctx, "is1b", types.StringID(j.DestServerID))
+	if err != nil {
+		t.Fatalf("read migrated server: %v", err)
+	}
+	if dest.Name != src.Name || dest.CPU != src.CPU || dest.MemoryMB != src.MemoryMB {
+		t.Errorf("migrated server = %+v", dest)
+	}
+	if _, err := iaas.NewServerOp(nil).Read(ctx, "is1a", src.ID); !iaas.IsNotFoundError(err) {
+		t.Errorf("source server should be deleted: %v", err)
+	}
+
+	saved, err := store.Load(j.ID)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if saved.Status != ServerMigrationCompleted || saved.DestServerID != j.DestServerID {
+		t.Errorf("saved journal = %+v", saved)
+	}
+}
+
+func TestServerService_ResumeMigration_SkipsRecordedSteps(t *testing.T) {
+	service := newTestServerService(t)
+	store := NewServerMigrationStore(t.TempDir())
+	ctx := context.Background()
+
+	src, err := createTestServer(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+	created, err := createTestServer(ctx, "is1b")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+	// 移行先サーバーの作成後に失敗した状態
+	j := &ServerMigrationJournal{
+		ID:             "resume-test",
+		SourceZone:     "is1a",
+		SourceServerID: src.ID.String(),
+		Options:        ServerMigrationOptions{DestZone: "is1b", NewName: "test-server"},
+		Status:         ServerMigrationFailed,
+		Error:          "timeout",
+		SourceStopped:  true,
+		DestServerID:   created.ID.String(),
+	}
+	if err := store.Save(j); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	resumed, err := service.ResumeMigration(ctx, j.ID, store, nil)
+	if err != nil {
+		t.Fatalf("ResumeMigration: %v", err)
+	}
+	if resumed.Status != ServerMigrationCompleted || resumed.Error != "" || resumed.DestServerID != created.ID.String() {
+		t.Errorf("resumed journal = %+v", resumed)
+	}
+	servers, err := service.List(ctx, "is1b")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(servers) != 1 {
+		t.Errorf("servers in is1b = %d, want 1 (resume must not create another server)", len(servers))
+	}
+}
+
+func TestServerService_ResumeMigration_KeepsSourceWhenWarned(t *testing.T) {
+	service := newTestServerService(t)
+	store := NewServerMigrationStore(t.TempDir())
+	ctx := context.Background()
+
+	src, err := createTestServer(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+	created, err := createTestServer(ctx, "is1b")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+	j := &ServerMigrationJournal{
+		ID:             "warned-test",
+		SourceZone:     "is1a",
+		SourceServerID: src.ID.String(),
+		Options:        ServerMigrationOptions{DestZone: "is1b", NewName: "test-server", DeleteSource: true},
+		Status:         ServerMigrationFailed,
+		Warnings:       []string{"NIC #1 は未接続です"},
+		SourceStopped:  true,
+		DestServerID:   created.ID.String(),
+	}
+	if err := store.Save(j); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	resumed, err := service.ResumeMigration(ctx, j.ID, store, nil)
+	if err != nil {
+		t.Fatalf("ResumeMigration: %v", err)
+	}
+	if resumed.Status != ServerMigrationCompleted || resumed.SourceDeleted || len(resumed.Warnings) != 2 {
+		t.Errorf("resumed journal = %+v", resumed)
+	}
+	if _, err := iaas.NewServerOp(nil).Read(ctx, "is1a", src.ID); err != nil {
+		t.Errorf("source server should be kept: %v", err)
+	}
+}
+
+func TestServerService_Migrate_RequiresAnotherZone(t *testing.T) {
+	service := newTestServerService(t)
+	store := NewServerMigrationStore(t.TempDir())
+	for _, zone := range []string{"", "is1a", "xx1a"} {
+		if _, err := service.Migrate(context.Background(), "is1a", "1", ServerMigrationOptions{DestZone: zone}, store, nil); err == nil {
+			t.Errorf("DestZone %q should be rejected", zone)
+		}
+	}
+}
+
+func TestServerMigrationStore_ListNewestFirst(t *testing.T) {
+	store := NewServerMigrationStore(t.TempDir())
+	for _, j := range []*ServerMigrationJournal{
+		{ID: "a", CreatedAt: "2026-01-01T00:00:00Z"},
+		{ID: "b", CreatedAt: "2026-03-01T00:00:00Z"},
+		{ID: "c", CreatedAt: "2026-02-01T00:00:00Z"},
+	} {
+		if err := store.Save(j); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 3 || list[0].ID != "b" || list[1].ID != "c" || list[2].ID != "a" {
+		t.Errorf("List = %+v", list)
+	}
+	if err := store.Delete("b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Load("b"); err == nil {
+		t.Error("deleted journal should not load")
+	}
+}
+
+func TestServerMigrationStore_RejectsPathInID(t *testing.T) {
+	store := NewServerMigrationStore(t.TempDir())
+	for _, id := range []string{"", "..", "../outside", `..\outside`, "sub/dir"} {
+		if err := store.Save(&ServerMigrationJournal{ID: id}); err == nil {
+			t.Errorf("Save(%q) should be rejected", id)
+		}
+		if _, err := store.Load(id); err == nil {
+			t.Errorf("Load(%q) should be rejected", id)
+		}
+		if err := store.Delete(id); err == nil {
+			t.Errorf("Delete(%q) should be rejected", id)
+		}
+	}
+}
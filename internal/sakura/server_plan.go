@@ -0,0 +1,201 @@
+package sakura
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// ServerPlanInfo はサーバープランのカタログの1件。料金は円で、ゾーンの料金が取得できない場合は0。
+type ServerPlanInfo struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	CPU          int    `json:"cpu"`
+	MemoryGB     int    `json:"memoryGB"`
+	GPU          int    `json:"gpu"`
+	GPUModel     string `json:"gpuModel"`
+	CPUModel     string `json:"cpuModel"`
+	Commitment   string `json:"commitment"` // "standard" または "dedicatedcpu"
+	Generation   int    `json:"generation"`
+	Availability string `json:"availability"`
+	HourlyPrice  int    `json:"hourlyPrice"`
+	DailyPrice   int    `json:"dailyPrice"`
+	MonthlyPrice int    `json:"monthlyPrice"`
+}
+
+// ServerPlanFilter はプランの絞り込み条件。ゼロ値の項目は条件にしない。
+type ServerPlanFilter struct {
+	Commitment    string `json:"commitment"`
+	Generation    int    `json:"generation"`
+	MinCPU        int    `json:"minCpu"`
+	MaxCPU        int    `json:"maxCpu"`
+	MinMemoryGB   int    `json:"minMemoryGB"`
+	MaxMemoryGB   int    `json:"maxMemoryGB"`
+	GPUOnly       bool   `json:"gpuOnly"`
+	AvailableOnly bool   `json:"availableOnly"`
+}
+
+// ServerPlanChangeCheck はプラン変更前の確認結果。Planが nil の場合は指定したCPU・メモリのプランが存在しない。
+type ServerPlanChangeCheck struct {
+	Current      *ServerPlanInfo `json:"current"`
+	Plan         *ServerPlanInfo `json:"plan"`
+	RequiresStop bool            `json:"requiresStop"`
+	Errors       []string        `json:"errors"`
+	Warnings     []string        `json:"warnings"`
+}
+
+// ErrServerPlanCatalogUnavailable はプランのカタログを取得できず、プラン変更の確認ができなかったことを表す。
+var ErrServerPlanCatalogUnavailable = errors.New("サーバープランのカタログを取得できません")
+
+type ServerPlanService struct {
+	client *Client
+}
+
+func NewServerPlanService(client *Client) *ServerPlanService {
+	return &ServerPlanService{client: client}
+}
+
+// List はゾーンのサーバープランをCPU・メモリの昇順で返す。
+func (s *ServerPlanService) List(ctx context.Context, zone string, filter ServerPlanFilter) ([]ServerPlanInfo, error) {
+	plans, err := s.catalog(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return filterServerPlans(plans, filter), nil
+}
+
+func (s *ServerPlanService) catalog(ctx context.Context, zone string) ([]ServerPlanInfo, error) {
+	op := iaas.NewServerPlanOp(s.client.Caller())
+	result, err := op.Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]ServerPlanInfo, 0, len(result.ServerPlans))
+	for _, p := range result.ServerPlans {
+		plans = append(plans, serverPlanFromSDK(zone, p))
+	}
+	return plans, nil
+}
+
+// CheckPlanChange はサーバーのプランをCPU・メモリで変更できるか確認する。
+// 専有CPUかどうか・世代・GPU数は現在のプランを引き継ぐ。
+func (s *ServerPlanService) CheckPlanChange(ctx context.Context, zone string, serverID string, cpu int, memoryGB int) (*ServerPlanChangeCheck, error) {
+	srv, err := iaas.NewServerOp(s.client.Caller()).Read(ctx, zone, types.StringID(serverID))
+	if err != nil {
+		return nil, err
+	}
+	plans, err := s.catalog(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrServerPlanCatalogUnavailable, err)
+	}
+	if len(plans) == 0 {
+		return nil, ErrServerPlanCatalogUnavailable
+	}
+
+	current := findServerPlan(plans, srv.CPU, srv.GetMemoryGB(), srv.ServerPlanGPU, string(srv.ServerPlanCommitment), int(srv.ServerPlanGeneration))
+	return checkServerPlanChange(plans, current, srv.CPU, srv.GetMemoryGB(), srv.ServerPlanGPU, string(srv.ServerPlanCommitment), int(srv.ServerPlanGeneration), srv.InstanceStatus.IsUp(), cpu, memoryGB), nil
+}
+
+func checkServerPlanChange(plans []ServerPlanInfo, current *ServerPlanInfo, currentCPU int, currentMemoryGB int, gpu int, commitment string, generation int, running bool, cpu int, memoryGB int) *ServerPlanChangeCheck {
+	check := &ServerPlanChangeCheck{Current: current, RequiresStop: running, Errors: []string{}, Warnings: []string{}}
+
+	plan := findServerPlan(plans, cpu, memoryGB, gpu, commitment, generation)
+	if plan == nil {
+		check.Errors = append(check.Errors, fmt.Sprintf("%dコア/%dGBのプランはありません", cpu, memoryGB))
+		return check
+	}
+	check.Plan = plan
+	if plan.Availability != string(types.Availabilities.Available) {
+		check.Errors = append(check.Errors, fmt.Sprintf("%s は現在利用できません(%s)", plan.Name, plan.Availability))
+	}
+	if cpu == currentCPU && memoryGB == currentMemoryGB {
+		check.Errors = append(check.Errors, "現在と同じプランです")
+	}
+
+	if running {
+		check.Warnings = append(check.Warnings, "プランを変更するにはサーバーを停止する必要があります")
+	}
+	check.Warnings = append(check.Warnings, "プランを変更するとサーバーのIDが変わります")
+	if current != nil && current.MonthlyPrice > 0 && plan.MonthlyPrice > 0 {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("月額料金が %d円 から %d円 になります", current.MonthlyPrice, plan.MonthlyPrice))
+	}
+	return check
+}
+
+// findServerPlan はCPU・メモリ・GPU数・専有CPUかどうか・世代が一致するプランを返す。
+// 世代が0の場合は最新の世代のプランを返す。
+func findServerPlan(plans []ServerPlanInfo, cpu int, memoryGB int, gpu int, commitment string, generation int) *ServerPlanInfo {
+	if commitment == "" {
+		commitment = string(types.Commitments.Standard)
+	}
+	var found *ServerPlanInfo
+	for i := range plans {
+		p := &plans[i]
+		if p.CPU != cpu || p.MemoryGB != memoryGB || p.GPU != gpu || p.Commitment != commitment {
+			continue
+		}
+		if generation != 0 && p.Generation != generation {
+			continue
+		}
+		if found == nil || p.Generation > found.Generation {
+			found = p
+		}
+	}
+	return found
+}
+
+func filterServerPlans(plans []ServerPlanInfo, filter ServerPlanFilter) []ServerPlanInfo {
+	filtered := make([]ServerPlanInfo, 0, len(plans))
+	for _, p := range plans {
+		switch {
+		case filter.Commitment != "" && p.Commitment != filter.Commitment:
+		case filter.Generation != 0 && p.Generation != filter.Generation:
+		case filter.MinCPU > 0 && p.CPU < filter.MinCPU:
+		case filter.MaxCPU > 0 && p.CPU > filter.MaxCPU:
+		case filter.MinMemoryGB > 0 && p.MemoryGB < filter.MinMemoryGB:
+		case filter.MaxMemoryGB > 0 && p.MemoryGB > filter.MaxMemoryGB:
+		case filter.GPUOnly && p.GPU == 0:
+		case filter.AvailableOnly && p.Availability != string(types.Availabilities.Available):
+		default:
+			filtered = append(filtered, p)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].CPU != filtered[j].CPU {
+			return filtered[i].CPU < filtered[j].CPU
+		}
+		if filtered[i].MemoryGB != filtered[j].MemoryGB {
+			return filtered[i].MemoryGB < filtered[j].MemoryGB
+		}
+		return filtered[i].Generation < filtered[j].Generation
+	})
+	return filtered
+}
+
+func serverPlanFromSDK(zone string, p *iaas.ServerPlan) ServerPlanInfo {
+	info := ServerPlanInfo{
+		ID:           p.ID.String(),
+		Name:         p.Name,
+		CPU:          p.CPU,
+		MemoryGB:     p.GetMemoryGB(),
+		GPU:          p.GPU,
+		GPUModel:     p.GPUModel,
+		CPUModel:     p.CPUModel,
+		Commitment:   string(p.Commitment),
+		Generation:   int(p.Generation),
+		Availability: string(p.Availability),
+	}
+	for _, price := range p.Price {
+		if price.Zone == zone {
+			info.HourlyPrice = price.Hourly
+			info.DailyPrice = price.Daily
+			info.MonthlyPrice = price.Monthly
+		}
+	}
+	return info
+}
@@ -0,0 +1,96 @@
+package sakura
+
+import (
+	"testing"
+)
+
+func testServerPlans() []ServerPlanInfo {
+	return []ServerPlanInfo{
+		{ID: "1", Name: "4core-8GB", CPU: 4, MemoryGB: 8, Commitment: "standard", Generation: 200, Availability: "available", MonthlyPrice: 8000},
+		{ID: "2", Name: "2core-4GB", CPU: 2, MemoryGB: 4, Commitment: "standard", Generation: 100, Availability: "available", MonthlyPrice: 4400},
+		{ID: "3", Name: "2core-4GB", CPU: 2, MemoryGB: 4, Commitment: "standard", Generation: 200, Availability: "available", MonthlyPrice: 4000},
+		{ID: "4", Name: "2core-4GB dedicated", CPU: 2, MemoryGB: 4, Commitment: "dedicatedcpu", Generation: 200, Availability: "available", MonthlyPrice: 9000},
+		{ID: "5", Name: "8core-64GB GPU", CPU: 8, MemoryGB: 64, GPU: 1, Commitment: "standard", Generation: 200, Availability: "available"},
+		{ID: "6", Name: "16core-32GB", CPU: 16, MemoryGB: 32, Commitment: "standard", Generation: 200, Availability: "discontinued"},
+	}
+}
+
+func TestFilterServerPlans(t *testing.T) {
+	ids := func(plans []ServerPlanInfo) []string {
+		var ids []string
+		for _, p := range plans {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		filter ServerPlanFilter
+		want   []string
+	}{
+		{"sorted by cpu and memory", ServerPlanFilter{}, []string{"2", "3", "4", "1", "5", "6"}},
+		{"dedicated", ServerPlanFilter{Commitment: "dedicatedcpu"}, []string{"4"}},
+		{"cpu range and generation", ServerPlanFilter{MinCPU: 4, MaxCPU: 8, Generation: 200}, []string{"1", "5"}},
+		{"memory range", ServerPlanFilter{MinMemoryGB: 8, MaxMemoryGB: 32}, []string{"1", "6"}},
+		{"gpu", ServerPlanFilter{GPUOnly: true}, []string{"5"}},
+		{"available", ServerPlanFilter{AvailableOnly: true, MinCPU: 16}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(filterServerPlans(testServerPlans(), tt.filter))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindServerPlan(t *testing.T) {
+	plans := testServerPlans()
+	if p := findServerPlan(plans, 2, 4, 0, "", 0); p == nil || p.ID != "3" {
+		t.Errorf("latest generation standard plan = %+v, want ID 3", p)
+	}
+	if p := findServerPlan(plans, 2, 4, 0, "standard", 100); p == nil || p.ID != "2" {
+		t.Errorf("generation 100 plan = %+v, want ID 2", p)
+	}
+	if p := findServerPlan(plans, 2, 4, 0, "dedicatedcpu", 0); p == nil || p.ID != "4" {
+		t.Errorf("dedicated plan = %+v, want ID 4", p)
+	}
+	if p := findServerPlan(plans, 3, 4, 0, "", 0); p != nil {
+		t.Errorf("3core plan should not exist, got %+v", p)
+	}
+}
+
+func TestCheckServerPlanChange(t *testing.T) {
+	plans := testServerPlans()
+	current := findServerPlan(plans, 2, 4, 0, "standard", 200)
+
+	check := checkServerPlanChange(plans, current, 2, 4, 0, "standard", 200, true, 4, 8)
+	if len(check.Errors) != 0 || check.Plan == nil || check.Plan.ID != "1" {
+		t.Fatalf("check = %+v", check)
+	}
+	if !check.RequiresStop || len(check.Warnings) != 3 {
+		t.Errorf("running server should warn about stop, ID and price: %+v", check.Warnings)
+	}
+
+	check = checkServerPlanChange(plans, current, 2, 4, 0, "standard", 200, false, 3, 8)
+	if check.Plan != nil || len(check.Errors) != 1 {
+		t.Errorf("unknown combination: %+v", check)
+	}
+
+	check = checkServerPlanChange(plans, current, 2, 4, 0, "standard", 200, false, 16, 32)
+	if len(check.Errors) != 1 || check.RequiresStop {
+		t.Errorf("discontinued plan: %+v", check)
+	}
+
+	check = checkServerPlanChange(plans, current, 2, 4, 0, "standard", 200, false, 2, 4)
+	if len(check.Errors) != 1 {
+		t.Errorf("same plan: %+v", check)
+	}
+}
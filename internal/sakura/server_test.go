@@ -69,6 +69,25 @@ func TestServerService_ChangePlan(t *testing.T) {
 	}
 }
 
+func TestServerService_ChangeToPlan(t *testing.T) {
+	service := newTestServerService(t)
+	ctx := context.Background()
+
+	created, err := createTestServer(ctx, "is1a")
+	if err != nil {
+		t.Fatalf("createTestServer: %v", err)
+	}
+
+	plan := &ServerPlanInfo{CPU: 2, MemoryGB: 4, Commitment: "standard", Generation: 200}
+	updated, err := service.ChangeToPlan(ctx, "is1a", created.ID.String(), plan)
+	if err != nil {
+		t.Fatalf("ChangeToPlan: %v", err)
+	}
+	if updated.CPU != 2 || updated.Memory != 4 {
+		t.Errorf("updated = %+v, want 2 CPU / 4 GB", updated)
+	}
+}
+
 func TestServerService_InsertAndEjectCDROM(t *testing.T) {
 	service := newTestServerService(t)
 	ctx := context.Background()
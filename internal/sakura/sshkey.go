@@ -0,0 +1,191 @@
+package sakura
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// ローカルで生成する鍵の種類。
+const (
+	SSHKeyAlgorithmEd25519 = "ed25519"
+	SSHKeyAlgorithmRSA     = "rsa"
+)
+
+const sshKeyRSABits = 4096
+
+type SSHKeyInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	PublicKey   string `json:"publicKey"`
+	Fingerprint string `json:"fingerprint"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// SSHKeyCreateInput はSSH公開鍵の登録内容。Generateが空の場合はPublicKeyに貼り付けた公開鍵を登録し、
+// "ed25519"/"rsa" の場合はローカルで鍵ペアを生成して公開鍵を登録する。
+type SSHKeyCreateInput struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	PublicKey      string `json:"publicKey"`
+	Generate       string `json:"generate"`
+	SaveToKeyring  bool   `json:"saveToKeyring"`  // 生成した秘密鍵をキーリングに保存するか(保存はApp側で行う)
+	PrivateKeyNote string `json:"privateKeyNote"` // 生成した秘密鍵のコメント(省略時は鍵の名前)
+}
+
+// SSHKeyCreateResult は登録したSSH公開鍵。鍵ペアを生成した場合のみPrivateKeyにOpenSSH形式の秘密鍵が入る。
+// 秘密鍵はさくらのクラウドには送信されないため、ここで保存しなければ二度と取得できない。
+// キーリングへの保存に失敗した場合はKeyringErrorに理由が入る(公開鍵の登録自体は成功している)。
+type SSHKeyCreateResult struct {
+	Key          SSHKeyInfo `json:"key"`
+	PrivateKey   string     `json:"privateKey"`
+	KeyringError string     `json:"keyringError,omitempty"`
+}
+
+type SSHKeyService struct {
+	client *Client
+}
+
+func NewSSHKeyService(client *Client) *SSHKeyService {
+	return &SSHKeyService{client: client}
+}
+
+func (s *SSHKeyService) List(ctx context.Context) ([]SSHKeyInfo, error) {
+	op := iaas.NewSSHKeyOp(s.client.Caller())
+	result, err := op.Find(ctx, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]SSHKeyInfo, 0, len(result.SSHKeys))
+	for _, k := range result.SSHKeys {
+		list = append(list, *sshKeyFromSDK(k))
+	}
+	return list, nil
+}
+
+// Create はSSH公開鍵を登録する。Generateが指定された場合はローカルで鍵ペアを生成し、秘密鍵を結果に含めて返す。
+func (s *SSHKeyService) Create(ctx context.Context, input SSHKeyCreateInput) (*SSHKeyCreateResult, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("名前を指定してください")
+	}
+
+	publicKey := strings.TrimSpace(input.PublicKey)
+	var privateKey string
+	if input.Generate != "" {
+		comment := input.PrivateKeyNote
+		if comment == "" {
+			comment = input.Name
+		}
+		var err error
+		publicKey, privateKey, err = GenerateSSHKeyPair(input.Generate, comment)
+		if err != nil {
+			return nil, err
+		}
+		// 登録後に保存できないと分かっても秘密鍵を失うだけなので、登録前に大きさを確かめる
+		if input.SaveToKeyring && len(privateKey) > sshPrivateKeyKeyringLimit {
+			return nil, fmt.Errorf("%s の秘密鍵(%dバイト)はキーリングに保存できる大きさ(%dバイト)を超えています。ed25519を選ぶか、キーリングへの保存を外してファイルに保存してください",
+				input.Generate, len(privateKey), sshPrivateKeyKeyringLimit)
+		}
+	} else if err := validateSSHPublicKey(publicKey); err != nil {
+		return nil, err
+	}
+
+	op := iaas.NewSSHKeyOp(s.client.Caller())
+	created, err := op.Create(ctx, &iaas.SSHKeyCreateRequest{
+		Name:        input.Name,
+		Description: input.Description,
+		PublicKey:   publicKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHKeyCreateResult{Key: *sshKeyFromSDK(created), PrivateKey: privateKey}, nil
+}
+
+// Update はSSH公開鍵の名前と説明を変更する。公開鍵自体は変更できない。
+func (s *SSHKeyService) Update(ctx context.Context, id string, name string, description string) (*SSHKeyInfo, error) {
+	op := iaas.NewSSHKeyOp(s.client.Caller())
+	updated, err := op.Update(ctx, types.StringID(id), &iaas.SSHKeyUpdateRequest{
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sshKeyFromSDK(updated), nil
+}
+
+func (s *SSHKeyService) Delete(ctx context.Context, id string) error {
+	op := iaas.NewSSHKeyOp(s.client.Caller())
+	return op.Delete(ctx, types.StringID(id))
+}
+
+// GenerateSSHKeyPair はローカルで鍵ペアを生成し、authorized_keys形式の公開鍵とOpenSSH形式の秘密鍵を返す。
+func GenerateSSHKeyPair(algorithm string, comment string) (string, string, error) {
+	var privateKey crypto.PrivateKey
+	var publicKey crypto.PublicKey
+	switch algorithm {
+	case SSHKeyAlgorithmEd25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		privateKey, publicKey = priv, pub
+	case SSHKeyAlgorithmRSA:
+		priv, err := rsa.GenerateKey(rand.Reader, sshKeyRSABits)
+		if err != nil {
+			return "", "", err
+		}
+		privateKey, publicKey = priv, &priv.PublicKey
+	default:
+		return "", "", fmt.Errorf("未対応の鍵の種類です: %s", algorithm)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, comment)
+	if err != nil {
+		return "", "", err
+	}
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	if comment != "" {
+		authorizedKey += " " + comment
+	}
+	return authorizedKey, string(pem.EncodeToMemory(block)), nil
+}
+
+func validateSSHPublicKey(publicKey string) error {
+	if publicKey == "" {
+		return fmt.Errorf("公開鍵を入力してください")
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey)); err != nil {
+		return fmt.Errorf("公開鍵の形式が不正です: %w", err)
+	}
+	return nil
+}
+
+func sshKeyFromSDK(k *iaas.SSHKey) *SSHKeyInfo {
+	return &SSHKeyInfo{
+		ID:          k.ID.String(),
+		Name:        k.Name,
+		Description: k.Description,
+		PublicKey:   k.PublicKey,
+		Fingerprint: k.Fingerprint,
+		CreatedAt:   k.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,115 @@
+package sakura
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSSHKeyService(t *testing.T) *SSHKeyService {
+	t.Helper()
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	return NewSSHKeyService(&Client{})
+}
+
+func TestSSHKeyService_CRUD(t *testing.T) {
+	service := newTestSSHKeyService(t)
+	ctx := context.Background()
+
+	pub, _, err := GenerateSSHKeyPair(SSHKeyAlgorithmEd25519, "pasted")
+	if err != nil {
+		t.Fatalf("GenerateSSHKeyPair: %v", err)
+	}
+	pasted, err := service.Create(ctx, SSHKeyCreateInput{Name: "pasted-key", PublicKey: pub + "\n"})
+	if err != nil {
+		t.Fatalf("Create(paste): %v", err)
+	}
+	if pasted.PrivateKey != "" {
+		t.Error("pasted key should not return a private key")
+	}
+
+	generated, err := service.Create(ctx, SSHKeyCreateInput{Name: "generated-key", Generate: SSHKeyAlgorithmEd25519})
+	if err != nil {
+		t.Fatalf("Create(generate): %v", err)
+	}
+	if !strings.Contains(generated.PrivateKey, "OPENSSH PRIVATE KEY") {
+		t.Errorf("PrivateKey = %q, want OpenSSH private key", generated.PrivateKey)
+	}
+
+	updated, err := service.Update(ctx, generated.Key.ID, "renamed-key", "desc")
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "renamed-key" || updated.Description != "desc" {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	if err := service.Delete(ctx, pasted.Key.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	list, err := service.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for _, k := range list {
+		if k.ID == pasted.Key.ID {
+			t.Errorf("deleted key %s still listed", k.ID)
+		}
+	}
+}
+
+func TestSSHKeyService_CreateRejectsInvalidKey(t *testing.T) {
+	service := newTestSSHKeyService(t)
+	if _, err := service.Create(context.Background(), SSHKeyCreateInput{Name: "bad", PublicKey: "not a key"}); err == nil {
+		t.Error("Create with an invalid public key should fail")
+	}
+}
+
+func TestGenerateSSHKeyPair(t *testing.T) {
+	for _, algorithm := range []string{SSHKeyAlgorithmEd25519, SSHKeyAlgorithmRSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			pub, priv, err := GenerateSSHKeyPair(algorithm, "me@example")
+			if err != nil {
+				t.Fatalf("GenerateSSHKeyPair: %v", err)
+			}
+			parsedPub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(pub))
+			if err != nil {
+				t.Fatalf("ParseAuthorizedKey: %v", err)
+			}
+			if comment != "me@example" {
+				t.Errorf("comment = %q, want me@example", comment)
+			}
+			signer, err := ssh.ParsePrivateKey([]byte(priv))
+			if err != nil {
+				t.Fatalf("ParsePrivateKey: %v", err)
+			}
+			if string(signer.PublicKey().Marshal()) != string(parsedPub.Marshal()) {
+				t.Error("private key does not match public key")
+			}
+		})
+	}
+
+	if _, _, err := GenerateSSHKeyPair("dsa", ""); err == nil {
+		t.Error("unsupported algorithm should fail")
+	}
+}
+
+func TestSSHKeyService_CreateRejectsOversizedKeyringKey(t *testing.T) {
+	service := newTestSSHKeyService(t)
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, SSHKeyCreateInput{Name: "rsa-key", Generate: SSHKeyAlgorithmRSA, SaveToKeyring: true}); err == nil {
+		t.Fatal("Create(rsa, SaveToKeyring) should fail before registering the key")
+	}
+	list, err := service.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("key was registered despite the keyring size error: %+v", list)
+	}
+}
@@ -16,6 +16,7 @@ type SwitchInfo struct {
 	DefaultRoute   string            `json:"defaultRoute"`
 	Scope          string            `json:"scope"`
 	Subnets        []SwitchSubnetInfo `json:"subnets,omitempty"`
+	BridgeID       string            `json:"bridgeId,omitempty"` // 接続しているブリッジ。未接続の場合は空
 }
 
 type SwitchSubnetInfo struct {
@@ -52,6 +53,7 @@ func (s *SwitchService) List(ctx context.Context, zone string) ([]SwitchInfo, er
 			NetworkMaskLen: sw.NetworkMaskLen,
 			DefaultRoute:   sw.DefaultRoute,
 			Scope:          string(sw.Scope),
+			BridgeID:       bridgeIDString(sw.BridgeID),
 		})
 	}
 	return list, nil
@@ -85,6 +87,7 @@ func (s *SwitchService) Get(ctx context.Context, zone string, id string) (*Switc
 		DefaultRoute:   sw.DefaultRoute,
 		Scope:          string(sw.Scope),
 		Subnets:        subnets,
+		BridgeID:       bridgeIDString(sw.BridgeID),
 	}, nil
 }
 
@@ -138,3 +141,10 @@ func (s *SwitchService) Update(ctx context.Context, zone string, id string, name
 		Scope:          string(sw.Scope),
 	}, nil
 }
+
+func bridgeIDString(id types.ID) string {
+	if id.IsEmpty() {
+		return ""
+	}
+	return id.String()
+}
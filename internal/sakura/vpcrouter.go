@@ -0,0 +1,527 @@
+package sakura
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// VPCルーターのプラン。
+const (
+	VPCRouterPlanStandard     = "standard"
+	VPCRouterPlanPremium      = "premium"
+	VPCRouterPlanHighSpec     = "highspec"
+	VPCRouterPlanHighSpec4000 = "highspec4000"
+)
+
+var vpcRouterPlanIDs = map[string]types.ID{
+	VPCRouterPlanStandard:     types.VPCRouterPlans.Standard,
+	VPCRouterPlanPremium:      types.VPCRouterPlans.Premium,
+	VPCRouterPlanHighSpec:     types.VPCRouterPlans.HighSpec,
+	VPCRouterPlanHighSpec4000: types.VPCRouterPlans.HighSpec4000,
+}
+
+type VPCRouterInfo struct {
+	ID              string                   `json:"id"`
+	Name            string                   `json:"name"`
+	Description     string                   `json:"description"`
+	Tags            []string                 `json:"tags"`
+	Zone            string                   `json:"zone"`
+	Plan            string                   `json:"plan"`
+	Version         int                      `json:"version"`
+	Status          string                   `json:"status"`
+	Availability    string                   `json:"availability"`
+	PublicIPAddress string                   `json:"publicIpAddress"`
+	Interfaces      []VPCRouterInterfaceInfo `json:"interfaces"`
+	Settings        VPCRouterSettings        `json:"settings"`
+	CreatedAt       string                   `json:"createdAt"`
+}
+
+// VPCRouterInterfaceInfo はNICの接続先と、設定されたIPアドレス。Index 0はグローバル側。
+type VPCRouterInterfaceInfo struct {
+	Index            int      `json:"index"`
+	SwitchID         string   `json:"switchId"`
+	SwitchName       string   `json:"switchName"`
+	SwitchScope      string   `json:"switchScope"`
+	IPAddress        string   `json:"ipAddress"` // スタンダードプランのグローバル側のアドレス
+	VirtualIPAddress string   `json:"virtualIpAddress"`
+	IPAddresses      []string `json:"ipAddresses"`
+	NetworkMaskLen   int      `json:"networkMaskLen"`
+	IPAliases        []string `json:"ipAliases"`
+}
+
+// VPCRouterCreateInput はVPCルーターの作成内容。
+// スタンダードプランは共有セグメントに接続し、プレミアム以上はSwitchIDのルーター+スイッチに
+// VirtualIPAddressと冗長化用の2つのIPAddressesを割り当てる。
+type VPCRouterCreateInput struct {
+	Name               string   `json:"name"`
+	Description        string   `json:"description"`
+	Tags               []string `json:"tags"`
+	Plan               string   `json:"plan"`
+	SwitchID           string   `json:"switchId"`
+	VirtualIPAddress   string   `json:"virtualIpAddress"`
+	IPAddresses        []string `json:"ipAddresses"`
+	NetworkMaskLen     int      `json:"networkMaskLen"`
+	IPAliases          []string `json:"ipAliases"`
+	VRID               int      `json:"vrid"`
+	InternetConnection bool     `json:"internetConnection"`
+	Boot               bool     `json:"boot"`
+}
+
+// VPCRouterInterfaceInput はプライベート側NIC(eth1〜eth7)の接続先とIPアドレス。
+// スタンダードプランではIPAddressesに1つ、プレミアム以上ではVirtualIPAddressと2つのIPAddressesを指定する。
+type VPCRouterInterfaceInput struct {
+	Index            int      `json:"index"`
+	SwitchID         string   `json:"switchId"`
+	VirtualIPAddress string   `json:"virtualIpAddress"`
+	IPAddresses      []string `json:"ipAddresses"`
+	NetworkMaskLen   int      `json:"networkMaskLen"`
+	IPAliases        []string `json:"ipAliases"`
+}
+
+// VPCRouterStatusInfo はVPCルーターの稼働状況(ファイアウォールのログ・DHCPのリース・VPNの接続状況等)。
+type VPCRouterStatusInfo struct {
+	FirewallReceiveLogs []string                      `json:"firewallReceiveLogs"`
+	FirewallSendLogs    []string                      `json:"firewallSendLogs"`
+	VPNLogs             []string                      `json:"vpnLogs"`
+	SessionCount        int                           `json:"sessionCount"`
+	DHCPLeases          []VPCRouterDHCPStaticMapping  `json:"dhcpLeases"`
+	L2TPSessions        []VPCRouterRemoteAccessStatus `json:"l2tpSessions"`
+	SiteToSiteVPNPeers  []VPCRouterVPNPeerStatus      `json:"siteToSiteVpnPeers"`
+	WireGuardPublicKey  string                        `json:"wireGuardPublicKey"`
+}
+
+type VPCRouterRemoteAccessStatus struct {
+	User      string `json:"user"`
+	IPAddress string `json:"ipAddress"`
+	TimeSec   int    `json:"timeSec"`
+}
+
+type VPCRouterVPNPeerStatus struct {
+	Peer   string `json:"peer"`
+	Status string `json:"status"`
+}
+
+type VPCRouterService struct {
+	client *Client
+}
+
+func NewVPCRouterService(client *Client) *VPCRouterService {
+	return &VPCRouterService{client: client}
+}
+
+func (s *VPCRouterService) List(ctx context.Context, zone string) ([]VPCRouterInfo, error) {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	result, err := op.Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]VPCRouterInfo, 0, len(result.VPCRouters))
+	for _, r := range result.VPCRouters {
+		list = append(list, *vpcRouterFromSDK(zone, r))
+	}
+	return list, nil
+}
+
+func (s *VPCRouterService) Get(ctx context.Context, zone string, vpcRouterID string) (*VPCRouterInfo, error) {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	r, err := op.Read(ctx, zone, types.StringID(vpcRouterID))
+	if err != nil {
+		return nil, err
+	}
+	return vpcRouterFromSDK(zone, r), nil
+}
+
+func (s *VPCRouterService) Create(ctx context.Context, zone string, input VPCRouterCreateInput) (*VPCRouterInfo, error) {
+	req, err := input.request()
+	if err != nil {
+		return nil, err
+	}
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	r, err := op.Create(ctx, zone, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 作成直後はコピー中のため、利用可能になってから起動する
+	if input.Boot {
+		if _, err := iaas.WaiterForReady(func() (interface{}, error) {
+			return op.Read(ctx, zone, r.ID)
+		}).WaitForState(ctx); err != nil {
+			return nil, err
+		}
+		if err := op.Boot(ctx, zone, r.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.Get(ctx, zone, r.ID.String())
+}
+
+func (in *VPCRouterCreateInput) request() (*iaas.VPCRouterCreateRequest, error) {
+	if in.Name == "" {
+		return nil, fmt.Errorf("名前を指定してください")
+	}
+	planID, ok := vpcRouterPlanIDs[in.Plan]
+	if !ok {
+		return nil, fmt.Errorf("不明なプランです: %s", in.Plan)
+	}
+
+	req := &iaas.VPCRouterCreateRequest{
+		Name:        in.Name,
+		Description: in.Description,
+		Tags:        in.Tags,
+		PlanID:      planID,
+		Version:     2,
+		Settings: &iaas.VPCRouterSetting{
+			InternetConnectionEnabled: types.StringFlag(in.InternetConnection),
+		},
+	}
+	if in.Plan == VPCRouterPlanStandard {
+		req.Switch = &iaas.ApplianceConnectedSwitch{Scope: types.Scopes.Shared}
+		return req, nil
+	}
+
+	if in.SwitchID == "" {
+		return nil, fmt.Errorf("プレミアム以上のプランではルーター+スイッチを指定してください")
+	}
+	if in.VirtualIPAddress == "" || len(in.IPAddresses) != 2 {
+		return nil, fmt.Errorf("プレミアム以上のプランでは仮想IPアドレスと2つの実IPアドレスを指定してください")
+	}
+	vrid := in.VRID
+	if vrid == 0 {
+		vrid = 1
+	}
+	req.Switch = &iaas.ApplianceConnectedSwitch{ID: types.StringID(in.SwitchID)}
+	req.IPAddresses = in.IPAddresses
+	req.Settings.VRID = vrid
+	req.Settings.Interfaces = []*iaas.VPCRouterInterfaceSetting{{
+		Index:            0,
+		VirtualIPAddress: in.VirtualIPAddress,
+		IPAddress:        in.IPAddresses,
+		NetworkMaskLen:   in.NetworkMaskLen,
+		IPAliases:        in.IPAliases,
+	}}
+	return req, nil
+}
+
+func (s *VPCRouterService) Update(ctx context.Context, zone string, vpcRouterID string, name string, description string, tags []string) (*VPCRouterInfo, error) {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	id := types.StringID(vpcRouterID)
+	current, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := op.Update(ctx, zone, id, &iaas.VPCRouterUpdateRequest{
+		Name:         name,
+		Description:  description,
+		Tags:         tags,
+		IconID:       current.IconID,
+		Settings:     current.Settings,
+		SettingsHash: current.SettingsHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vpcRouterFromSDK(zone, r), nil
+}
+
+// UpdateSettings はNAT・ポートフォワーディング・ファイアウォール・DHCP・VPN・スタティックルート等の設定を置き換え、
+// VPCルーターに反映する。NICの設定やVRIDなど、VPCRouterSettingsに含まれない設定は現在の値を引き継ぐ。
+func (s *VPCRouterService) UpdateSettings(ctx context.Context, zone string, vpcRouterID string, settings VPCRouterSettings) (*VPCRouterInfo, error) {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	id := types.StringID(vpcRouterID)
+	current, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.validate(current.PlanID == types.VPCRouterPlans.Standard); err != nil {
+		return nil, err
+	}
+
+	merged := &iaas.VPCRouterSetting{}
+	if current.Settings != nil {
+		copied := *current.Settings
+		merged = &copied
+	}
+	settings.apply(merged)
+	return s.updateSettingsAndApply(ctx, zone, current, merged)
+}
+
+// ConfigureInterface はプライベート側NICをスイッチに接続してIPアドレスを設定し、VPCルーターに反映する。
+// NICの接続・切断はVPCルーターの停止中に行う必要がある。設定の保存に失敗した場合はNICを元のスイッチに接続し直す。
+func (s *VPCRouterService) ConfigureInterface(ctx context.Context, zone string, vpcRouterID string, input VPCRouterInterfaceInput) (*VPCRouterInfo, error) {
+	if input.Index < 1 || input.Index > 7 {
+		return nil, fmt.Errorf("NIC番号は1〜7の範囲で指定してください")
+	}
+	if input.SwitchID == "" || len(input.IPAddresses) == 0 || input.NetworkMaskLen == 0 {
+		return nil, fmt.Errorf("接続するスイッチ・IPアドレス・ネットマスクを指定してください")
+	}
+
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	id := types.StringID(vpcRouterID)
+	current, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.PlanID == types.VPCRouterPlans.Standard && len(input.IPAddresses) != 1 {
+		return nil, fmt.Errorf("スタンダードプランではIPアドレスを1つ指定してください")
+	}
+	if current.PlanID != types.VPCRouterPlans.Standard && (input.VirtualIPAddress == "" || len(input.IPAddresses) != 2) {
+		return nil, fmt.Errorf("プレミアム以上のプランでは仮想IPアドレスと2つの実IPアドレスを指定してください")
+	}
+
+	// 設定の保存に失敗した場合は、NICを元のスイッチに接続し直す
+	rollback := &rollbackStack{}
+	connected := vpcRouterConnectedSwitch(current, input.Index)
+	if connected != input.SwitchID {
+		if connected != "" {
+			if err := op.DisconnectFromSwitch(ctx, zone, id, input.Index); err != nil {
+				return nil, err
+			}
+			rollback.push(func(ctx context.Context) error {
+				return op.ConnectToSwitch(ctx, zone, id, input.Index, types.StringID(connected))
+			})
+		}
+		if err := op.ConnectToSwitch(ctx, zone, id, input.Index, types.StringID(input.SwitchID)); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+		rollback.push(func(ctx context.Context) error {
+			return op.DisconnectFromSwitch(ctx, zone, id, input.Index)
+		})
+		// 接続後はSettingsHashが変わるため読み直す
+		if current, err = op.Read(ctx, zone, id); err != nil {
+			return nil, rollback.run(ctx, err)
+		}
+	}
+
+	merged := &iaas.VPCRouterSetting{}
+	if current.Settings != nil {
+		copied := *current.Settings
+		merged = &copied
+	}
+	merged.Interfaces = replaceVPCRouterInterfaceSetting(merged.Interfaces, &iaas.VPCRouterInterfaceSetting{
+		Index:            input.Index,
+		VirtualIPAddress: input.VirtualIPAddress,
+		IPAddress:        input.IPAddresses,
+		NetworkMaskLen:   input.NetworkMaskLen,
+		IPAliases:        input.IPAliases,
+	})
+	updated, err := s.updateSettingsAndApply(ctx, zone, current, merged)
+	// 設定の保存後に反映だけが失敗した場合は、スイッチとIPアドレスの設定が揃っているため接続は戻さない
+	if err != nil && !errors.Is(err, errVPCRouterSettingsNotApplied) {
+		return nil, rollback.run(ctx, err)
+	}
+	return updated, err
+}
+
+// DisconnectInterface はプライベート側NICのIPアドレス設定を削除し、スイッチから切断する。
+func (s *VPCRouterService) DisconnectInterface(ctx context.Context, zone string, vpcRouterID string, index int) (*VPCRouterInfo, error) {
+	if index < 1 || index > 7 {
+		return nil, fmt.Errorf("NIC番号は1〜7の範囲で指定してください")
+	}
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	id := types.StringID(vpcRouterID)
+	current, err := op.Read(ctx, zone, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Settings != nil {
+		copied := *current.Settings
+		copied.Interfaces = replaceVPCRouterInterfaceSetting(copied.Interfaces, &iaas.VPCRouterInterfaceSetting{Index: index})
+		if _, err := s.updateSettingsAndApply(ctx, zone, current, &copied); err != nil {
+			return nil, err
+		}
+	}
+	if switchID := vpcRouterConnectedSwitch(current, index); switchID != "" {
+		if err := op.DisconnectFromSwitch(ctx, zone, id, index); err != nil {
+			return nil, fmt.Errorf("NIC #%d のIPアドレス設定は削除しましたが、スイッチからの切断に失敗しました(NICはスイッチ %s に接続されたままです): %w", index, switchID, err)
+		}
+	}
+	return s.Get(ctx, zone, vpcRouterID)
+}
+
+// errVPCRouterSettingsNotApplied は設定の保存には成功し、稼働中のVPCルーターへの反映(Config)だけが失敗したことを表す。
+var errVPCRouterSettingsNotApplied = errors.New("設定を保存しましたが、VPCルーターへの反映に失敗しました")
+
+func (s *VPCRouterService) updateSettingsAndApply(ctx context.Context, zone string, current *iaas.VPCRouter, settings *iaas.VPCRouterSetting) (*VPCRouterInfo, error) {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	r, err := op.UpdateSettings(ctx, zone, current.ID, &iaas.VPCRouterUpdateSettingsRequest{
+		Settings:     settings,
+		SettingsHash: current.SettingsHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 設定の変更は反映(Config)するまで稼働中のVPCルーターに適用されない
+	if err := op.Config(ctx, zone, current.ID); err != nil {
+		return nil, fmt.Errorf("%w: %w", errVPCRouterSettingsNotApplied, err)
+	}
+	return vpcRouterFromSDK(zone, r), nil
+}
+
+// replaceVPCRouterInterfaceSetting はIndexが同じNICの設定を置き換える。IPアドレスが空の設定は削除として扱う。
+func replaceVPCRouterInterfaceSetting(settings []*iaas.VPCRouterInterfaceSetting, setting *iaas.VPCRouterInterfaceSetting) []*iaas.VPCRouterInterfaceSetting {
+	result := make([]*iaas.VPCRouterInterfaceSetting, 0, len(settings)+1)
+	for _, s := range settings {
+		if s.Index != setting.Index {
+			result = append(result, s)
+		}
+	}
+	if len(setting.IPAddress) > 0 {
+		result = append(result, setting)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Index < result[j].Index })
+	return result
+}
+
+func vpcRouterConnectedSwitch(r *iaas.VPCRouter, index int) string {
+	for _, iface := range r.Interfaces {
+		if iface.Index == index {
+			return iface.SwitchID.String()
+		}
+	}
+	return ""
+}
+
+func (s *VPCRouterService) Delete(ctx context.Context, zone string, vpcRouterID string) error {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	return op.Delete(ctx, zone, types.StringID(vpcRouterID))
+}
+
+func (s *VPCRouterService) PowerOn(ctx context.Context, zone string, vpcRouterID string) error {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	return op.Boot(ctx, zone, types.StringID(vpcRouterID))
+}
+
+func (s *VPCRouterService) PowerOff(ctx context.Context, zone string, vpcRouterID string) error {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	return op.Shutdown(ctx, zone, types.StringID(vpcRouterID), &iaas.ShutdownOption{Force: false})
+}
+
+func (s *VPCRouterService) ForceStop(ctx context.Context, zone string, vpcRouterID string) error {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	return op.Shutdown(ctx, zone, types.StringID(vpcRouterID), &iaas.ShutdownOption{Force: true})
+}
+
+func (s *VPCRouterService) Reset(ctx context.Context, zone string, vpcRouterID string) error {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	return op.Reset(ctx, zone, types.StringID(vpcRouterID))
+}
+
+func (s *VPCRouterService) GetStatus(ctx context.Context, zone string, vpcRouterID string) (*VPCRouterStatusInfo, error) {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	st, err := op.Status(ctx, zone, types.StringID(vpcRouterID))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &VPCRouterStatusInfo{
+		FirewallReceiveLogs: st.FirewallReceiveLogs,
+		FirewallSendLogs:    st.FirewallSendLogs,
+		VPNLogs:             st.VPNLogs,
+		SessionCount:        st.SessionCount,
+		DHCPLeases:          []VPCRouterDHCPStaticMapping{},
+		L2TPSessions:        []VPCRouterRemoteAccessStatus{},
+		SiteToSiteVPNPeers:  []VPCRouterVPNPeerStatus{},
+	}
+	for _, l := range st.DHCPServerLeases {
+		info.DHCPLeases = append(info.DHCPLeases, VPCRouterDHCPStaticMapping{MACAddress: l.MACAddress, IPAddress: l.IPAddress})
+	}
+	for _, sess := range st.L2TPIPsecServerSessions {
+		info.L2TPSessions = append(info.L2TPSessions, VPCRouterRemoteAccessStatus{User: sess.User, IPAddress: sess.IPAddress, TimeSec: sess.TimeSec})
+	}
+	for _, p := range st.SiteToSiteIPsecVPNPeers {
+		info.SiteToSiteVPNPeers = append(info.SiteToSiteVPNPeers, VPCRouterVPNPeerStatus{Peer: p.Peer, Status: p.Status})
+	}
+	if st.WireGuard != nil {
+		info.WireGuardPublicKey = st.WireGuard.PublicKey
+	}
+	return info, nil
+}
+
+// GetLog はVPCルーターのシステムログを返す。
+func (s *VPCRouterService) GetLog(ctx context.Context, zone string, vpcRouterID string) (string, error) {
+	op := iaas.NewVPCRouterOp(s.client.Caller())
+	l, err := op.Logs(ctx, zone, types.StringID(vpcRouterID))
+	if err != nil {
+		return "", err
+	}
+	return l.Log, nil
+}
+
+func vpcRouterPlanName(id types.ID) string {
+	for name, planID := range vpcRouterPlanIDs {
+		if planID == id {
+			return name
+		}
+	}
+	return id.String()
+}
+
+func vpcRouterFromSDK(zone string, r *iaas.VPCRouter) *VPCRouterInfo {
+	info := &VPCRouterInfo{
+		ID:           r.ID.String(),
+		Name:         r.Name,
+		Description:  r.Description,
+		Tags:         r.Tags,
+		Zone:         zone,
+		Plan:         vpcRouterPlanName(r.PlanID),
+		Version:      r.Version,
+		Status:       string(r.InstanceStatus),
+		Availability: string(r.Availability),
+		Interfaces:   []VPCRouterInterfaceInfo{},
+		Settings:     vpcRouterSettingsFromSDK(r.Settings),
+		CreatedAt:    r.CreatedAt.Format(time.RFC3339),
+	}
+
+	ifaceSettings := make(map[int]*iaas.VPCRouterInterfaceSetting)
+	if r.Settings != nil {
+		for _, s := range r.Settings.Interfaces {
+			ifaceSettings[s.Index] = s
+		}
+	}
+	for _, iface := range r.Interfaces {
+		i := VPCRouterInterfaceInfo{
+			Index:       iface.Index,
+			SwitchID:    iface.SwitchID.String(),
+			SwitchName:  iface.SwitchName,
+			SwitchScope: string(iface.SwitchScope),
+			IPAddress:   iface.IPAddress,
+		}
+		if s, ok := ifaceSettings[iface.Index]; ok {
+			i.VirtualIPAddress = s.VirtualIPAddress
+			i.IPAddresses = s.IPAddress
+			i.NetworkMaskLen = s.NetworkMaskLen
+			i.IPAliases = s.IPAliases
+		}
+		info.Interfaces = append(info.Interfaces, i)
+	}
+	sort.SliceStable(info.Interfaces, func(i, j int) bool { return info.Interfaces[i].Index < info.Interfaces[j].Index })
+
+	info.PublicIPAddress = vpcRouterPublicIPAddress(r)
+	return info
+}
+
+// vpcRouterPublicIPAddress はグローバル側のアドレスを返す。プレミアム以上のプランでは仮想IPアドレス。
+func vpcRouterPublicIPAddress(r *iaas.VPCRouter) string {
+	if r.Settings != nil {
+		for _, s := range r.Settings.Interfaces {
+			if s.Index == 0 && s.VirtualIPAddress != "" {
+				return s.VirtualIPAddress
+			}
+		}
+	}
+	for _, iface := range r.Interfaces {
+		if iface.Index == 0 {
+			return iface.IPAddress
+		}
+	}
+	return ""
+}
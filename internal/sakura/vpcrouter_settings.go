@@ -0,0 +1,357 @@
+package sakura
+
+import (
+	"fmt"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// VPCRouterSettings はVPCルーターの機能ごとの設定。詳細の表示と設定の更新の両方で使う。
+// NICのIPアドレス設定はスイッチの接続と一緒に変更するため、VPCRouterService.ConfigureInterfaceで扱う。
+type VPCRouterSettings struct {
+	InternetConnection bool                         `json:"internetConnection"`
+	StaticNAT          []VPCRouterStaticNAT         `json:"staticNat"`
+	PortForwarding     []VPCRouterPortForwarding    `json:"portForwarding"`
+	Firewall           []VPCRouterFirewall          `json:"firewall"`
+	DHCPServers        []VPCRouterDHCPServer        `json:"dhcpServers"`
+	DHCPStaticMappings []VPCRouterDHCPStaticMapping `json:"dhcpStaticMappings"`
+	SiteToSiteVPN      []VPCRouterSiteToSiteVPN     `json:"siteToSiteVpn"`
+	L2TPIPsecServer    *VPCRouterL2TPIPsecServer    `json:"l2tpIpsecServer"` // nilは無効
+	WireGuard          *VPCRouterWireGuard          `json:"wireGuard"`       // nilは無効
+	RemoteAccessUsers  []VPCRouterRemoteAccessUser  `json:"remoteAccessUsers"`
+	StaticRoutes       []VPCRouterStaticRoute       `json:"staticRoutes"`
+	SyslogHost         string                       `json:"syslogHost"`
+}
+
+// VPCRouterStaticNAT はグローバルIPアドレスとプライベートIPアドレスの1対1のNAT。プレミアム以上のプランで使える。
+type VPCRouterStaticNAT struct {
+	GlobalAddress  string `json:"globalAddress"`
+	PrivateAddress string `json:"privateAddress"`
+	Description    string `json:"description"`
+}
+
+type VPCRouterPortForwarding struct {
+	Protocol       string `json:"protocol"` // "tcp"/"udp"
+	GlobalPort     int    `json:"globalPort"`
+	PrivateAddress string `json:"privateAddress"`
+	PrivatePort    int    `json:"privatePort"`
+	Description    string `json:"description"`
+}
+
+// VPCRouterFirewall はNICごとのファイアウォール。Indexは0(グローバル側)〜7。
+type VPCRouterFirewall struct {
+	Index   int                     `json:"index"`
+	Send    []VPCRouterFirewallRule `json:"send"`
+	Receive []VPCRouterFirewallRule `json:"receive"`
+}
+
+type VPCRouterFirewallRule struct {
+	Protocol           string `json:"protocol"` // "tcp"/"udp"/"icmp"/"ip"
+	SourceNetwork      string `json:"sourceNetwork"`
+	SourcePort         string `json:"sourcePort"`
+	DestinationNetwork string `json:"destinationNetwork"`
+	DestinationPort    string `json:"destinationPort"`
+	Action             string `json:"action"` // "allow"/"deny"
+	Logging            bool   `json:"logging"`
+	Description        string `json:"description"`
+}
+
+type VPCRouterDHCPServer struct {
+	Interface  string   `json:"interface"` // "eth1"〜"eth7"
+	RangeStart string   `json:"rangeStart"`
+	RangeStop  string   `json:"rangeStop"`
+	DNSServers []string `json:"dnsServers"`
+}
+
+type VPCRouterDHCPStaticMapping struct {
+	MACAddress string `json:"macAddress"`
+	IPAddress  string `json:"ipAddress"`
+}
+
+type VPCRouterSiteToSiteVPN struct {
+	Peer            string   `json:"peer"`
+	RemoteID        string   `json:"remoteId"`
+	PreSharedSecret string   `json:"preSharedSecret"`
+	Routes          []string `json:"routes"`
+	LocalPrefix     []string `json:"localPrefix"`
+}
+
+type VPCRouterL2TPIPsecServer struct {
+	RangeStart      string `json:"rangeStart"`
+	RangeStop       string `json:"rangeStop"`
+	PreSharedSecret string `json:"preSharedSecret"`
+}
+
+type VPCRouterWireGuard struct {
+	IPAddress string                   `json:"ipAddress"` // サーバー側のアドレス(CIDR表記)
+	Peers     []VPCRouterWireGuardPeer `json:"peers"`
+}
+
+type VPCRouterWireGuardPeer struct {
+	Name      string `json:"name"`
+	IPAddress string `json:"ipAddress"`
+	PublicKey string `json:"publicKey"`
+}
+
+// VPCRouterRemoteAccessUser はL2TP/IPsecで接続するユーザー。
+type VPCRouterRemoteAccessUser struct {
+	UserName string `json:"userName"`
+	Password string `json:"password"`
+}
+
+type VPCRouterStaticRoute struct {
+	Prefix  string `json:"prefix"`
+	NextHop string `json:"nextHop"`
+}
+
+// validate はAPIに送る前に分かる設定の誤りを確認する。standardはスタンダードプランかどうか。
+func (s *VPCRouterSettings) validate(standard bool) error {
+	if standard && len(s.StaticNAT) > 0 {
+		return fmt.Errorf("スタティックNATはプレミアム以上のプランで利用できます")
+	}
+	for i, pf := range s.PortForwarding {
+		if pf.Protocol != "tcp" && pf.Protocol != "udp" {
+			return fmt.Errorf("ポートフォワーディング #%d: プロトコルは tcp/udp を指定してください", i+1)
+		}
+		if !validPort(pf.GlobalPort) || !validPort(pf.PrivatePort) {
+			return fmt.Errorf("ポートフォワーディング #%d: ポートは1〜65535の範囲で指定してください", i+1)
+		}
+		if pf.PrivateAddress == "" {
+			return fmt.Errorf("ポートフォワーディング #%d: 転送先のIPアドレスを指定してください", i+1)
+		}
+	}
+	for _, fw := range s.Firewall {
+		if fw.Index < 0 || fw.Index > 7 {
+			return fmt.Errorf("ファイアウォールのNIC番号は0〜7の範囲で指定してください: %d", fw.Index)
+		}
+		for _, rule := range append(append([]VPCRouterFirewallRule(nil), fw.Send...), fw.Receive...) {
+			if rule.Action != "allow" && rule.Action != "deny" {
+				return fmt.Errorf("ファイアウォール(eth%d): 動作は allow/deny を指定してください", fw.Index)
+			}
+		}
+	}
+	if s.L2TPIPsecServer != nil && s.L2TPIPsecServer.PreSharedSecret == "" {
+		return fmt.Errorf("L2TP/IPsecの事前共有キーを指定してください")
+	}
+	if s.WireGuard != nil {
+		for _, peer := range s.WireGuard.Peers {
+			if peer.PublicKey == "" {
+				return fmt.Errorf("WireGuardのピア %s の公開鍵を指定してください", peer.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+// apply は設定をSDKの設定に反映する。このアプリで扱わない設定(VRID・NIC・PPTP・DNSフォワーディング等)はそのまま残す。
+func (s *VPCRouterSettings) apply(dst *iaas.VPCRouterSetting) {
+	dst.InternetConnectionEnabled = types.StringFlag(s.InternetConnection)
+	dst.SyslogHost = s.SyslogHost
+
+	dst.StaticNAT = make([]*iaas.VPCRouterStaticNAT, 0, len(s.StaticNAT))
+	for _, n := range s.StaticNAT {
+		dst.StaticNAT = append(dst.StaticNAT, &iaas.VPCRouterStaticNAT{
+			GlobalAddress:  n.GlobalAddress,
+			PrivateAddress: n.PrivateAddress,
+			Description:    n.Description,
+		})
+	}
+
+	dst.PortForwarding = make([]*iaas.VPCRouterPortForwarding, 0, len(s.PortForwarding))
+	for _, pf := range s.PortForwarding {
+		dst.PortForwarding = append(dst.PortForwarding, &iaas.VPCRouterPortForwarding{
+			Protocol:       types.EVPCRouterPortForwardingProtocol(pf.Protocol),
+			GlobalPort:     types.StringNumber(pf.GlobalPort),
+			PrivateAddress: pf.PrivateAddress,
+			PrivatePort:    types.StringNumber(pf.PrivatePort),
+			Description:    pf.Description,
+		})
+	}
+
+	dst.Firewall = make([]*iaas.VPCRouterFirewall, 0, len(s.Firewall))
+	for _, fw := range s.Firewall {
+		dst.Firewall = append(dst.Firewall, &iaas.VPCRouterFirewall{
+			Index:   fw.Index,
+			Send:    vpcRouterFirewallRulesToSDK(fw.Send),
+			Receive: vpcRouterFirewallRulesToSDK(fw.Receive),
+		})
+	}
+
+	dst.DHCPServer = make([]*iaas.VPCRouterDHCPServer, 0, len(s.DHCPServers))
+	for _, d := range s.DHCPServers {
+		dst.DHCPServer = append(dst.DHCPServer, &iaas.VPCRouterDHCPServer{
+			Interface:  d.Interface,
+			RangeStart: d.RangeStart,
+			RangeStop:  d.RangeStop,
+			DNSServers: d.DNSServers,
+		})
+	}
+
+	dst.DHCPStaticMapping = make([]*iaas.VPCRouterDHCPStaticMapping, 0, len(s.DHCPStaticMappings))
+	for _, m := range s.DHCPStaticMappings {
+		dst.DHCPStaticMapping = append(dst.DHCPStaticMapping, &iaas.VPCRouterDHCPStaticMapping{
+			MACAddress: m.MACAddress,
+			IPAddress:  m.IPAddress,
+		})
+	}
+
+	// IKE/ESPのパラメータは既存の値を引き継ぎ、接続先の一覧だけを置き換える
+	if dst.SiteToSiteIPsecVPN == nil {
+		dst.SiteToSiteIPsecVPN = &iaas.VPCRouterSiteToSiteIPsecVPN{}
+	}
+	dst.SiteToSiteIPsecVPN.Config = make([]*iaas.VPCRouterSiteToSiteIPsecVPNConfig, 0, len(s.SiteToSiteVPN))
+	for _, v := range s.SiteToSiteVPN {
+		dst.SiteToSiteIPsecVPN.Config = append(dst.SiteToSiteIPsecVPN.Config, &iaas.VPCRouterSiteToSiteIPsecVPNConfig{
+			Peer:            v.Peer,
+			RemoteID:        v.RemoteID,
+			PreSharedSecret: v.PreSharedSecret,
+			Routes:          v.Routes,
+			LocalPrefix:     v.LocalPrefix,
+		})
+	}
+
+	dst.L2TPIPsecServerEnabled = types.StringFlag(s.L2TPIPsecServer != nil)
+	dst.L2TPIPsecServer = nil
+	if s.L2TPIPsecServer != nil {
+		dst.L2TPIPsecServer = &iaas.VPCRouterL2TPIPsecServer{
+			RangeStart:      s.L2TPIPsecServer.RangeStart,
+			RangeStop:       s.L2TPIPsecServer.RangeStop,
+			PreSharedSecret: s.L2TPIPsecServer.PreSharedSecret,
+		}
+	}
+
+	dst.WireGuardEnabled = types.StringFlag(s.WireGuard != nil)
+	dst.WireGuard = nil
+	if s.WireGuard != nil {
+		peers := make([]*iaas.VPCRouterWireGuardPeer, 0, len(s.WireGuard.Peers))
+		for _, p := range s.WireGuard.Peers {
+			peers = append(peers, &iaas.VPCRouterWireGuardPeer{Name: p.Name, IPAddress: p.IPAddress, PublicKey: p.PublicKey})
+		}
+		dst.WireGuard = &iaas.VPCRouterWireGuard{IPAddress: s.WireGuard.IPAddress, Peers: peers}
+	}
+
+	dst.RemoteAccessUsers = make([]*iaas.VPCRouterRemoteAccessUser, 0, len(s.RemoteAccessUsers))
+	for _, u := range s.RemoteAccessUsers {
+		dst.RemoteAccessUsers = append(dst.RemoteAccessUsers, &iaas.VPCRouterRemoteAccessUser{UserName: u.UserName, Password: u.Password})
+	}
+
+	dst.StaticRoute = make([]*iaas.VPCRouterStaticRoute, 0, len(s.StaticRoutes))
+	for _, r := range s.StaticRoutes {
+		dst.StaticRoute = append(dst.StaticRoute, &iaas.VPCRouterStaticRoute{Prefix: r.Prefix, NextHop: r.NextHop})
+	}
+}
+
+func vpcRouterFirewallRulesToSDK(rules []VPCRouterFirewallRule) []*iaas.VPCRouterFirewallRule {
+	result := make([]*iaas.VPCRouterFirewallRule, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, &iaas.VPCRouterFirewallRule{
+			Protocol:           types.Protocol(r.Protocol),
+			SourceNetwork:      types.VPCFirewallNetwork(r.SourceNetwork),
+			SourcePort:         types.VPCFirewallPort(r.SourcePort),
+			DestinationNetwork: types.VPCFirewallNetwork(r.DestinationNetwork),
+			DestinationPort:    types.VPCFirewallPort(r.DestinationPort),
+			Action:             types.Action(r.Action),
+			Logging:            types.StringFlag(r.Logging),
+			Description:        r.Description,
+		})
+	}
+	return result
+}
+
+func vpcRouterFirewallRulesFromSDK(rules []*iaas.VPCRouterFirewallRule) []VPCRouterFirewallRule {
+	result := make([]VPCRouterFirewallRule, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, VPCRouterFirewallRule{
+			Protocol:           string(r.Protocol),
+			SourceNetwork:      string(r.SourceNetwork),
+			SourcePort:         string(r.SourcePort),
+			DestinationNetwork: string(r.DestinationNetwork),
+			DestinationPort:    string(r.DestinationPort),
+			Action:             string(r.Action),
+			Logging:            r.Logging.Bool(),
+			Description:        r.Description,
+		})
+	}
+	return result
+}
+
+func vpcRouterSettingsFromSDK(src *iaas.VPCRouterSetting) VPCRouterSettings {
+	s := VPCRouterSettings{
+		StaticNAT:          []VPCRouterStaticNAT{},
+		PortForwarding:     []VPCRouterPortForwarding{},
+		Firewall:           []VPCRouterFirewall{},
+		DHCPServers:        []VPCRouterDHCPServer{},
+		DHCPStaticMappings: []VPCRouterDHCPStaticMapping{},
+		SiteToSiteVPN:      []VPCRouterSiteToSiteVPN{},
+		RemoteAccessUsers:  []VPCRouterRemoteAccessUser{},
+		StaticRoutes:       []VPCRouterStaticRoute{},
+	}
+	if src == nil {
+		return s
+	}
+	s.InternetConnection = src.InternetConnectionEnabled.Bool()
+	s.SyslogHost = src.SyslogHost
+
+	for _, n := range src.StaticNAT {
+		s.StaticNAT = append(s.StaticNAT, VPCRouterStaticNAT{GlobalAddress: n.GlobalAddress, PrivateAddress: n.PrivateAddress, Description: n.Description})
+	}
+	for _, pf := range src.PortForwarding {
+		s.PortForwarding = append(s.PortForwarding, VPCRouterPortForwarding{
+			Protocol:       string(pf.Protocol),
+			GlobalPort:     pf.GlobalPort.Int(),
+			PrivateAddress: pf.PrivateAddress,
+			PrivatePort:    pf.PrivatePort.Int(),
+			Description:    pf.Description,
+		})
+	}
+	for _, fw := range src.Firewall {
+		s.Firewall = append(s.Firewall, VPCRouterFirewall{
+			Index:   fw.Index,
+			Send:    vpcRouterFirewallRulesFromSDK(fw.Send),
+			Receive: vpcRouterFirewallRulesFromSDK(fw.Receive),
+		})
+	}
+	for _, d := range src.DHCPServer {
+		s.DHCPServers = append(s.DHCPServers, VPCRouterDHCPServer{Interface: d.Interface, RangeStart: d.RangeStart, RangeStop: d.RangeStop, DNSServers: d.DNSServers})
+	}
+	for _, m := range src.DHCPStaticMapping {
+		s.DHCPStaticMappings = append(s.DHCPStaticMappings, VPCRouterDHCPStaticMapping{MACAddress: m.MACAddress, IPAddress: m.IPAddress})
+	}
+	if src.SiteToSiteIPsecVPN != nil {
+		for _, v := range src.SiteToSiteIPsecVPN.Config {
+			s.SiteToSiteVPN = append(s.SiteToSiteVPN, VPCRouterSiteToSiteVPN{
+				Peer:            v.Peer,
+				RemoteID:        v.RemoteID,
+				PreSharedSecret: v.PreSharedSecret,
+				Routes:          v.Routes,
+				LocalPrefix:     v.LocalPrefix,
+			})
+		}
+	}
+	if src.L2TPIPsecServerEnabled.Bool() && src.L2TPIPsecServer != nil {
+		s.L2TPIPsecServer = &VPCRouterL2TPIPsecServer{
+			RangeStart:      src.L2TPIPsecServer.RangeStart,
+			RangeStop:       src.L2TPIPsecServer.RangeStop,
+			PreSharedSecret: src.L2TPIPsecServer.PreSharedSecret,
+		}
+	}
+	if src.WireGuardEnabled.Bool() && src.WireGuard != nil {
+		wg := &VPCRouterWireGuard{IPAddress: src.WireGuard.IPAddress, Peers: []VPCRouterWireGuardPeer{}}
+		for _, p := range src.WireGuard.Peers {
+			wg.Peers = append(wg.Peers, VPCRouterWireGuardPeer{Name: p.Name, IPAddress: p.IPAddress, PublicKey: p.PublicKey})
+		}
+		s.WireGuard = wg
+	}
+	for _, u := range src.RemoteAccessUsers {
+		s.RemoteAccessUsers = append(s.RemoteAccessUsers, VPCRouterRemoteAccessUser{UserName: u.UserName, Password: u.Password})
+	}
+	for _, r := range src.StaticRoute {
+		s.StaticRoutes = append(s.StaticRoutes, VPCRouterStaticRoute{Prefix: r.Prefix, NextHop: r.NextHop})
+	}
+	return s
+}
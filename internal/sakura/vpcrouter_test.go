@@ -0,0 +1,107 @@
+package sakura
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/fake"
+)
+
+func TestVPCRouterService_CreateAndUpdateSettings(t *testing.T) {
+	fake.SwitchFactoryFuncToFake()
+	fake.InitDataStore()
+	service := NewVPCRouterService(&Client{})
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, "is1a", VPCRouterCreateInput{Name: "router", Plan: VPCRouterPlanPremium}); err == nil {
+		t.Error("Create with premium plan and no switch should fail")
+	}
+	router, err := service.Create(ctx, "is1a", VPCRouterCreateInput{Name: "router", Plan: VPCRouterPlanStandard, InternetConnection: true})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if router.Plan != VPCRouterPlanStandard {
+		t.Errorf("Plan = %q, want %q", router.Plan, VPCRouterPlanStandard)
+	}
+
+	settings := VPCRouterSettings{
+		InternetConnection: true,
+		StaticNAT:          []VPCRouterStaticNAT{{GlobalAddress: "192.0.2.10", PrivateAddress: "192.168.0.10"}},
+	}
+	if _, err := service.UpdateSettings(ctx, "is1a", router.ID, settings); err == nil {
+		t.Error("UpdateSettings with static NAT on standard plan should fail")
+	}
+
+	settings.StaticNAT = nil
+	settings.PortForwarding = []VPCRouterPortForwarding{{Protocol: "tcp", GlobalPort: 10022, PrivateAddress: "192.168.0.10", PrivatePort: 22}}
+	settings.StaticRoutes = []VPCRouterStaticRoute{{Prefix: "10.0.0.0/8", NextHop: "192.168.0.1"}}
+	updated, err := service.UpdateSettings(ctx, "is1a", router.ID, settings)
+	if err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+	if len(updated.Settings.PortForwarding) != 1 || updated.Settings.PortForwarding[0].PrivatePort != 22 {
+		t.Errorf("PortForwarding = %+v", updated.Settings.PortForwarding)
+	}
+	if len(updated.Settings.StaticRoutes) != 1 || updated.Settings.StaticRoutes[0].NextHop != "192.168.0.1" {
+		t.Errorf("StaticRoutes = %+v", updated.Settings.StaticRoutes)
+	}
+}
+
+func TestVPCRouterSettings_ApplyKeepsUnmanagedSettings(t *testing.T) {
+	dst := &iaas.VPCRouterSetting{
+		VRID: 10,
+		Interfaces: []*iaas.VPCRouterInterfaceSetting{
+			{Index: 1, IPAddress: []string{"192.168.0.1"}, NetworkMaskLen: 24},
+		},
+	}
+	settings := VPCRouterSettings{
+		Firewall: []VPCRouterFirewall{{
+			Index:   1,
+			Receive: []VPCRouterFirewallRule{{Protocol: "tcp", DestinationPort: "22", Action: "allow"}},
+		}},
+		L2TPIPsecServer:   &VPCRouterL2TPIPsecServer{RangeStart: "192.168.0.200", RangeStop: "192.168.0.210", PreSharedSecret: "secret"},
+		RemoteAccessUsers: []VPCRouterRemoteAccessUser{{UserName: "user", Password: "password"}},
+	}
+	if err := settings.validate(true); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	settings.apply(dst)
+
+	if dst.VRID != 10 || len(dst.Interfaces) != 1 {
+		t.Errorf("unmanaged settings were changed: %+v", dst)
+	}
+	got := vpcRouterSettingsFromSDK(dst)
+	if len(got.Firewall) != 1 || len(got.Firewall[0].Receive) != 1 || got.Firewall[0].Receive[0].DestinationPort != "22" {
+		t.Errorf("Firewall = %+v", got.Firewall)
+	}
+	if got.L2TPIPsecServer == nil || got.L2TPIPsecServer.PreSharedSecret != "secret" {
+		t.Errorf("L2TPIPsecServer = %+v", got.L2TPIPsecServer)
+	}
+	if len(got.RemoteAccessUsers) != 1 || got.RemoteAccessUsers[0].UserName != "user" {
+		t.Errorf("RemoteAccessUsers = %+v", got.RemoteAccessUsers)
+	}
+}
+
+func TestVPCRouterSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings VPCRouterSettings
+		wantErr  bool
+	}{
+		{"empty", VPCRouterSettings{}, false},
+		{"bad protocol", VPCRouterSettings{PortForwarding: []VPCRouterPortForwarding{{Protocol: "icmp", GlobalPort: 80, PrivateAddress: "192.168.0.10", PrivatePort: 80}}}, true},
+		{"bad port", VPCRouterSettings{PortForwarding: []VPCRouterPortForwarding{{Protocol: "tcp", GlobalPort: 0, PrivateAddress: "192.168.0.10", PrivatePort: 80}}}, true},
+		{"bad firewall index", VPCRouterSettings{Firewall: []VPCRouterFirewall{{Index: 8}}}, true},
+		{"bad firewall action", VPCRouterSettings{Firewall: []VPCRouterFirewall{{Index: 0, Send: []VPCRouterFirewallRule{{Protocol: "ip", Action: "drop"}}}}}, true},
+		{"l2tp without secret", VPCRouterSettings{L2TPIPsecServer: &VPCRouterL2TPIPsecServer{RangeStart: "192.168.0.200", RangeStop: "192.168.0.210"}}, true},
+		{"wireguard peer without key", VPCRouterSettings{WireGuard: &VPCRouterWireGuard{IPAddress: "192.168.1.1/24", Peers: []VPCRouterWireGuardPeer{{Name: "peer"}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.validate(false); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
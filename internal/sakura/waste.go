@@ -0,0 +1,369 @@
+package sakura
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+// 無駄なリソースの検出種別。Cleanupで実行されるアクションもこの種別で決まる。
+const (
+	WasteKindUnattachedDisk     = "unattachedDisk"
+	WasteKindUnusedArchive      = "unusedArchive"
+	WasteKindEmptySwitch        = "emptySwitch"
+	WasteKindUnusedPacketFilter = "unusedPacketFilter"
+	WasteKindStoppedServer      = "stoppedServer"
+	WasteKindIdleProxyLB        = "idleProxyLB"
+	WasteKindUnreferencedKMSKey = "unreferencedKmsKey"
+)
+
+const (
+	defaultArchiveUnusedDays  = 90
+	wasteEstimatedCostUnknown = -1
+	kmsKeyStatusSuspended     = "suspended"
+)
+
+// WasteFinding は課金が発生しているが使われていないと思われるリソース1件分の検出結果。
+// EstimatedMonthlyCost は料金表から引けなかった場合 -1 となる。
+type WasteFinding struct {
+	Kind                 string `json:"kind"`
+	Zone                 string `json:"zone"`
+	ResourceID           string `json:"resourceId"`
+	ResourceName         string `json:"resourceName"`
+	Reason               string `json:"reason"`
+	EstimatedMonthlyCost int    `json:"estimatedMonthlyCost"`
+	ActionLabel          string `json:"actionLabel"`
+}
+
+type WasteReport struct {
+	Findings                  []WasteFinding `json:"findings"`
+	TotalEstimatedMonthlyCost int            `json:"totalEstimatedMonthlyCost"`
+}
+
+// wastePriceCatalog はサービスクラスのパス(例: "cloud/disk/ssd/20g")から月額料金を引く。
+type wastePriceCatalog map[string]int
+
+func (c wastePriceCatalog) monthly(path string) int {
+	if price, ok := c[path]; ok {
+		return price
+	}
+	return wasteEstimatedCostUnknown
+}
+
+type WasteService struct {
+	client *Client
+}
+
+func NewWasteService(client *Client) *WasteService {
+	return &WasteService{client: client}
+}
+
+// Analyze は指定ゾーンのIaaSリソースとELBを調べ、使われていないリソースを検出する。
+// archiveUnusedDays日以上前に作成され、どのディスクのコピー元にもなっていないアーカイブを未使用とみなす。
+func (s *WasteService) Analyze(ctx context.Context, zones []string, archiveUnusedDays int) (*WasteReport, error) {
+	if len(zones) == 0 {
+		zones = Zones
+	}
+	if archiveUnusedDays <= 0 {
+		archiveUnusedDays = defaultArchiveUnusedDays
+	}
+	now := time.Now()
+
+	findings := make([]WasteFinding, 0)
+	for _, zone := range zones {
+		zoneFindings, err := s.analyzeZone(ctx, zone, now, archiveUnusedDays)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, zoneFindings...)
+	}
+
+	proxyLBs, err := iaas.NewProxyLBOp(s.client.Caller()).Find(ctx, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, detectIdleProxyLBs(proxyLBs.ProxyLBs)...)
+
+	return NewWasteReport(findings), nil
+}
+
+func (s *WasteService) analyzeZone(ctx context.Context, zone string, now time.Time, archiveUnusedDays int) ([]WasteFinding, error) {
+	caller := s.client.Caller()
+
+	prices, err := s.priceCatalog(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	disks, err := iaas.NewDiskOp(caller).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	archives, err := iaas.NewArchiveOp(caller).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	switches, err := iaas.NewSwitchOp(caller).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	pfs, err := iaas.NewPacketFilterOp(caller).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	servers, err := iaas.NewServerOp(caller).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]WasteFinding, 0)
+	findings = append(findings, detectUnattachedDisks(zone, disks.Disks, prices)...)
+	findings = append(findings, detectUnusedArchives(zone, archives.Archives, disks.Disks, now, archiveUnusedDays, prices)...)
+	findings = append(findings, detectEmptySwitches(zone, switches.Switches, prices)...)
+	findings = append(findings, detectUnusedPacketFilters(zone, pfs.PacketFilters, servers.Servers)...)
+	findings = append(findings, detectStoppedServers(zone, servers.Servers, prices)...)
+	return findings, nil
+}
+
+func (s *WasteService) priceCatalog(ctx context.Context, zone string) (wastePriceCatalog, error) {
+	result, err := iaas.NewServiceClassOp(s.client.Caller()).Find(ctx, zone, &iaas.FindCondition{})
+	if err != nil {
+		return nil, err
+	}
+	catalog := make(wastePriceCatalog, len(result.ServiceClasses))
+	for _, sc := range result.ServiceClasses {
+		if sc.Price == nil {
+			continue
+		}
+		catalog[sc.ServiceClassPath] = sc.Price.Monthly
+	}
+	return catalog, nil
+}
+
+// Cleanup は検出結果に対応する削除アクションを実行する。KMSキーはIaaS APIの対象外のため呼び出し側で処理する。
+func (s *WasteService) Cleanup(ctx context.Context, finding WasteFinding) error {
+	caller := s.client.Caller()
+	id := types.StringID(finding.ResourceID)
+
+	switch finding.Kind {
+	case WasteKindUnattachedDisk:
+		return iaas.NewDiskOp(caller).Delete(ctx, finding.Zone, id)
+	case WasteKindUnusedArchive:
+		return iaas.NewArchiveOp(caller).Delete(ctx, finding.Zone, id)
+	case WasteKindEmptySwitch:
+		return iaas.NewSwitchOp(caller).Delete(ctx, finding.Zone, id)
+	case WasteKindUnusedPacketFilter:
+		return iaas.NewPacketFilterOp(caller).Delete(ctx, finding.Zone, id)
+	case WasteKindStoppedServer:
+		serverOp := iaas.NewServerOp(caller)
+		srv, err := serverOp.Read(ctx, finding.Zone, id)
+		if err != nil {
+			return err
+		}
+		if srv.InstanceStatus != types.ServerInstanceStatuses.Down {
+			return fmt.Errorf("サーバー %s は停止していないため削除できません", finding.ResourceName)
+		}
+		diskIDs := make([]types.ID, 0, len(srv.Disks))
+		for _, d := range srv.Disks {
+			diskIDs = append(diskIDs, d.ID)
+		}
+		return serverOp.DeleteWithDisks(ctx, finding.Zone, id, &iaas.ServerDeleteWithDisksRequest{IDs: diskIDs})
+	case WasteKindIdleProxyLB:
+		return iaas.NewProxyLBOp(caller).Delete(ctx, id)
+	}
+	return fmt.Errorf("クリーンアップに対応していない検出種別です: %s", finding.Kind)
+}
+
+// NewWasteReport は検出結果を集計する。料金不明(-1)の検出結果は合計に含めない。
+func NewWasteReport(findings []WasteFinding) *WasteReport {
+	total := 0
+	for _, f := range findings {
+		if f.EstimatedMonthlyCost > 0 {
+			total += f.EstimatedMonthlyCost
+		}
+	}
+	return &WasteReport{Findings: findings, TotalEstimatedMonthlyCost: total}
+}
+
+func diskServiceClassPath(d *iaas.Disk) string {
+	plan := "ssd"
+	if d.DiskPlanID == types.DiskPlans.HDD {
+		plan = "hdd"
+	}
+	return fmt.Sprintf("cloud/disk/%s/%dg", plan, d.SizeMB/1024)
+}
+
+func detectUnattachedDisks(zone string, disks []*iaas.Disk, prices wastePriceCatalog) []WasteFinding {
+	findings := make([]WasteFinding, 0)
+	for _, d := range disks {
+		if !d.ServerID.IsEmpty() {
+			continue
+		}
+		findings = append(findings, WasteFinding{
+			Kind:                 WasteKindUnattachedDisk,
+			Zone:                 zone,
+			ResourceID:           d.ID.String(),
+			ResourceName:         d.Name,
+			Reason:               "どのサーバーにも接続されていないディスク",
+			EstimatedMonthlyCost: prices.monthly(diskServiceClassPath(d)),
+			ActionLabel:          "ディスクを削除",
+		})
+	}
+	return findings
+}
+
+func detectUnusedArchives(zone string, archives []*iaas.Archive, disks []*iaas.Disk, now time.Time, unusedDays int, prices wastePriceCatalog) []WasteFinding {
+	usedAsSource := make(map[types.ID]bool)
+	for _, d := range disks {
+		if !d.SourceArchiveID.IsEmpty() {
+			usedAsSource[d.SourceArchiveID] = true
+		}
+	}
+	threshold := now.AddDate(0, 0, -unusedDays)
+
+	findings := make([]WasteFinding, 0)
+	for _, a := range archives {
+		if a.Scope != types.Scopes.User || usedAsSource[a.ID] || a.CreatedAt.After(threshold) {
+			continue
+		}
+		findings = append(findings, WasteFinding{
+			Kind:                 WasteKindUnusedArchive,
+			Zone:                 zone,
+			ResourceID:           a.ID.String(),
+			ResourceName:         a.Name,
+			Reason:               fmt.Sprintf("%d日以上前に作成され、現在どのディスクのコピー元にもなっていないアーカイブ", unusedDays),
+			EstimatedMonthlyCost: prices.monthly(fmt.Sprintf("cloud/archive/%dg", a.SizeMB/1024)),
+			ActionLabel:          "アーカイブを削除",
+		})
+	}
+	return findings
+}
+
+func detectEmptySwitches(zone string, switches []*iaas.Switch, prices wastePriceCatalog) []WasteFinding {
+	findings := make([]WasteFinding, 0)
+	for _, sw := range switches {
+		if sw.ServerCount > 0 || sw.ApplianceCount > 0 || !sw.BridgeID.IsEmpty() {
+			continue
+		}
+		findings = append(findings, WasteFinding{
+			Kind:                 WasteKindEmptySwitch,
+			Zone:                 zone,
+			ResourceID:           sw.ID.String(),
+			ResourceName:         sw.Name,
+			Reason:               "サーバー・アプライアンスが1台も接続されていないスイッチ",
+			EstimatedMonthlyCost: prices.monthly("cloud/switch/default"),
+			ActionLabel:          "スイッチを削除",
+		})
+	}
+	return findings
+}
+
+// detectUnusedPacketFilters はどのサーバーのNICにも適用されていないパケットフィルタを検出する。
+// パケットフィルタ自体は無料のため料金は0とする。
+func detectUnusedPacketFilters(zone string, pfs []*iaas.PacketFilter, servers []*iaas.Server) []WasteFinding {
+	applied := make(map[types.ID]bool)
+	for _, srv := range servers {
+		for _, iface := range srv.Interfaces {
+			if !iface.PacketFilterID.IsEmpty() {
+				applied[iface.PacketFilterID] = true
+			}
+		}
+	}
+
+	findings := make([]WasteFinding, 0)
+	for _, pf := range pfs {
+		if applied[pf.ID] {
+			continue
+		}
+		findings = append(findings, WasteFinding{
+			Kind:         WasteKindUnusedPacketFilter,
+			Zone:         zone,
+			ResourceID:   pf.ID.String(),
+			ResourceName: pf.Name,
+			Reason:       "どのNICにも適用されていないパケットフィルタ",
+			ActionLabel:  "パケットフィルタを削除",
+		})
+	}
+	return findings
+}
+
+// detectStoppedServers は停止中にもかかわらずディスクを保持し続けているサーバーを検出する。
+// 停止中のサーバー本体は課金されないため、料金は接続ディスクの合計で見積もる。
+func detectStoppedServers(zone string, servers []*iaas.Server, prices wastePriceCatalog) []WasteFinding {
+	findings := make([]WasteFinding, 0)
+	for _, srv := range servers {
+		if srv.InstanceStatus != types.ServerInstanceStatuses.Down || len(srv.Disks) == 0 {
+			continue
+		}
+		cost := 0
+		for _, d := range srv.Disks {
+			price := prices.monthly(diskServiceClassPath(&iaas.Disk{DiskPlanID: d.DiskPlanID, SizeMB: d.SizeMB}))
+			if price == wasteEstimatedCostUnknown {
+				cost = wasteEstimatedCostUnknown
+				break
+			}
+			cost += price
+		}
+		findings = append(findings, WasteFinding{
+			Kind:                 WasteKindStoppedServer,
+			Zone:                 zone,
+			ResourceID:           srv.ID.String(),
+			ResourceName:         srv.Name,
+			Reason:               fmt.Sprintf("停止中だがディスクを%d台保持しているサーバー", len(srv.Disks)),
+			EstimatedMonthlyCost: cost,
+			ActionLabel:          "サーバーとディスクを削除",
+		})
+	}
+	return findings
+}
+
+// detectIdleProxyLBs は有効な実サーバーが1台もないELBを検出する。
+// ELBのプラン料金はゾーン別料金表に含まれないため不明(-1)とする。
+func detectIdleProxyLBs(proxyLBs []*iaas.ProxyLB) []WasteFinding {
+	findings := make([]WasteFinding, 0)
+	for _, p := range proxyLBs {
+		enabled := 0
+		for _, srv := range p.Servers {
+			if srv.Enabled {
+				enabled++
+			}
+		}
+		if enabled > 0 {
+			continue
+		}
+		findings = append(findings, WasteFinding{
+			Kind:                 WasteKindIdleProxyLB,
+			ResourceID:           p.ID.String(),
+			ResourceName:         p.Name,
+			Reason:               "有効な実サーバーが1台もないエンハンスドロードバランサ",
+			EstimatedMonthlyCost: wasteEstimatedCostUnknown,
+			ActionLabel:          "ELBを削除",
+		})
+	}
+	return findings
+}
+
+// DetectUnreferencedKMSKeys は依存グラフ上でバケット・Vaultのどちらからも参照されていないKMSキーを検出する。
+// バケットはアクセスキーなしに列挙できず、グラフには呼び出し側が指定したものしか含まれないため、
+// 検出したキーは削除せず一時停止(suspended)を提案する。一時停止なら見落とした参照元があっても元に戻せる。
+// すでに一時停止しているキーは提案することがないため対象外とする。
+func DetectUnreferencedKMSKeys(graph *DependencyGraph) []WasteFinding {
+	findings := make([]WasteFinding, 0)
+	for _, n := range graph.Nodes {
+		if n.Type != DependencyNodeKMSKey || n.Status == kmsKeyStatusSuspended || len(graph.Dependents(n.ID)) > 0 {
+			continue
+		}
+		findings = append(findings, WasteFinding{
+			Kind:                 WasteKindUnreferencedKMSKey,
+			ResourceID:           n.ResourceID,
+			ResourceName:         n.Name,
+			Reason:               "指定したバケット・シークレットマネージャーのどちらからも参照されていないKMSキー",
+			EstimatedMonthlyCost: wasteEstimatedCostUnknown,
+			ActionLabel:          "KMSキーを一時停止",
+		})
+	}
+	return findings
+}
@@ -0,0 +1,141 @@
+package sakura
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sacloud/sacloud-sdk-go/api/iaas"
+	"github.com/sacloud/sacloud-sdk-go/api/iaas/types"
+)
+
+func wasteFindingIDs(findings []WasteFinding) map[string]bool {
+	ids := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		ids[f.ResourceID] = true
+	}
+	return ids
+}
+
+func TestDetectUnattachedDisks(t *testing.T) {
+	disks := []*iaas.Disk{
+		{ID: types.ID(1), Name: "orphan", SizeMB: 20 * 1024, DiskPlanID: types.DiskPlans.SSD},
+		{ID: types.ID(2), Name: "attached", SizeMB: 20 * 1024, ServerID: types.ID(100)},
+	}
+	prices := wastePriceCatalog{"cloud/disk/ssd/20g": 1980}
+
+	findings := detectUnattachedDisks("is1a", disks, prices)
+	if len(findings) != 1 {
+		t.Fatalf("len(findings) = %d, want 1: %+v", len(findings), findings)
+	}
+	if findings[0].ResourceID != "1" {
+		t.Errorf("ResourceID = %q, want %q", findings[0].ResourceID, "1")
+	}
+	if findings[0].EstimatedMonthlyCost != 1980 {
+		t.Errorf("EstimatedMonthlyCost = %d, want 1980", findings[0].EstimatedMonthlyCost)
+	}
+}
+
+func TestDetectUnusedArchives(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -120)
+	archives := []*iaas.Archive{
+		{ID: types.ID(10), Name: "old-unused", Scope: types.Scopes.User, SizeMB: 20 * 1024, CreatedAt: old},
+		{ID: types.ID(11), Name: "old-used", Scope: types.Scopes.User, CreatedAt: old},
+		{ID: types.ID(12), Name: "recent", Scope: types.Scopes.User, CreatedAt: now.AddDate(0, 0, -10)},
+		{ID: types.ID(13), Name: "public", Scope: types.Scopes.Shared, CreatedAt: old},
+	}
+	disks := []*iaas.Disk{{ID: types.ID(1), SourceArchiveID: types.ID(11)}}
+
+	findings := detectUnusedArchives("is1a", archives, disks, now, 90, wastePriceCatalog{})
+	ids := wasteFindingIDs(findings)
+	if len(findings) != 1 || !ids["10"] {
+		t.Errorf("findings = %+v, want only archive 10", findings)
+	}
+	if findings[0].EstimatedMonthlyCost != wasteEstimatedCostUnknown {
+		t.Errorf("EstimatedMonthlyCost = %d, want unknown(-1)", findings[0].EstimatedMonthlyCost)
+	}
+}
+
+func TestDetectEmptySwitchesAndUnusedPacketFilters(t *testing.T) {
+	switches := []*iaas.Switch{
+		{ID: types.ID(20), Name: "empty"},
+		{ID: types.ID(21), Name: "used", ServerCount: 2},
+		{ID: types.ID(22), Name: "appliance", ApplianceCount: 1},
+	}
+	if ids := wasteFindingIDs(detectEmptySwitches("is1a", switches, wastePriceCatalog{})); len(ids) != 1 || !ids["20"] {
+		t.Errorf("empty switches = %v, want only 20", ids)
+	}
+
+	pfs := []*iaas.PacketFilter{
+		{ID: types.ID(30), Name: "applied"},
+		{ID: types.ID(31), Name: "unused"},
+	}
+	servers := []*iaas.Server{
+		{ID: types.ID(100), Interfaces: []*iaas.InterfaceView{{ID: types.ID(1), PacketFilterID: types.ID(30)}}},
+	}
+	if ids := wasteFindingIDs(detectUnusedPacketFilters("is1a", pfs, servers)); len(ids) != 1 || !ids["31"] {
+		t.Errorf("unused packet filters = %v, want only 31", ids)
+	}
+}
+
+func TestDetectStoppedServers(t *testing.T) {
+	servers := []*iaas.Server{
+		{
+			ID:             types.ID(100),
+			Name:           "stopped",
+			InstanceStatus: types.ServerInstanceStatuses.Down,
+			Disks: []*iaas.ServerConnectedDisk{
+				{ID: types.ID(1), SizeMB: 20 * 1024, DiskPlanID: types.DiskPlans.SSD},
+				{ID: types.ID(2), SizeMB: 40 * 1024, DiskPlanID: types.DiskPlans.SSD},
+			},
+		},
+		{ID: types.ID(101), Name: "running", InstanceStatus: types.ServerInstanceStatuses.Up, Disks: []*iaas.ServerConnectedDisk{{ID: types.ID(3)}}},
+		{ID: types.ID(102), Name: "diskless", InstanceStatus: types.ServerInstanceStatuses.Down},
+	}
+	prices := wastePriceCatalog{"cloud/disk/ssd/20g": 1980, "cloud/disk/ssd/40g": 3960}
+
+	findings := detectStoppedServers("is1a", servers, prices)
+	if len(findings) != 1 || findings[0].ResourceID != "100" {
+		t.Fatalf("findings = %+v, want only server 100", findings)
+	}
+	if findings[0].EstimatedMonthlyCost != 1980+3960 {
+		t.Errorf("EstimatedMonthlyCost = %d, want %d", findings[0].EstimatedMonthlyCost, 1980+3960)
+	}
+}
+
+func TestDetectIdleProxyLBs(t *testing.T) {
+	proxyLBs := []*iaas.ProxyLB{
+		{ID: types.ID(40), Name: "idle", Servers: []*iaas.ProxyLBServer{{IPAddress: "192.0.2.1", Enabled: false}}},
+		{ID: types.ID(41), Name: "active", Servers: []*iaas.ProxyLBServer{{IPAddress: "192.0.2.2", Enabled: true}}},
+		{ID: types.ID(42), Name: "no-servers"},
+	}
+	ids := wasteFindingIDs(detectIdleProxyLBs(proxyLBs))
+	if len(ids) != 2 || !ids["40"] || !ids["42"] {
+		t.Errorf("idle ELBs = %v, want 40 and 42", ids)
+	}
+}
+
+func TestDetectUnreferencedKMSKeys(t *testing.T) {
+	g := NewDependencyGraph()
+	g.AddKMSKey("key-used", "used", "active")
+	g.AddKMSKey("key-unused", "unused", "active")
+	g.AddKMSKey("key-suspended", "suspended", "suspended")
+	g.AddVaultEncryption("vault-1", "vault", "key-used")
+
+	ids := wasteFindingIDs(DetectUnreferencedKMSKeys(g))
+	if len(ids) != 1 || !ids["key-unused"] {
+		t.Errorf("unreferenced keys = %v, want only key-unused", ids)
+	}
+}
+
+func TestNewWasteReport(t *testing.T) {
+	report := NewWasteReport([]WasteFinding{
+		{EstimatedMonthlyCost: 1000},
+		{EstimatedMonthlyCost: wasteEstimatedCostUnknown},
+		{EstimatedMonthlyCost: 0},
+		{EstimatedMonthlyCost: 500},
+	})
+	if report.TotalEstimatedMonthlyCost != 1500 {
+		t.Errorf("TotalEstimatedMonthlyCost = %d, want 1500", report.TotalEstimatedMonthlyCost)
+	}
+}
@@ -0,0 +1,380 @@
+// Package vncbridge はサーバーのVNCコンソール(RFB over TCP)を、noVNCなどのブラウザ用VNCクライアントが
+// 接続できるローカルのWebSocketに中継する。
+//
+// RFBのハンドシェイクやVNC認証はWebSocketの向こう側のクライアントが行い、ここではバイト列をそのまま中継する。
+// 中継のついでに、セッションの開始・終了時刻や転送量などのメタデータを記録する。
+package vncbridge
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// openTimeout はOpenで発行したURLにクライアントが接続するまでの猶予。
+	openTimeout = time.Minute
+	dialTimeout = 10 * time.Second
+	bufferSize  = 32 * 1024
+	// rfbVersionLen はRFBのProtocolVersionメッセージ("RFB 003.008\n")の長さ。
+	rfbVersionLen = 12
+)
+
+// Target は中継先のVNCサーバーと、記録用のサーバー情報。
+type Target struct {
+	Host     string
+	Port     string
+	Zone     string
+	ServerID string
+}
+
+// SessionRecord は1回のコンソール接続のメタデータ。パスワードや画面の内容は記録しない。
+type SessionRecord struct {
+	ID            string `json:"id"`
+	Zone          string `json:"zone"`
+	ServerID      string `json:"serverId"`
+	Target        string `json:"target"`
+	ServerVersion string `json:"serverVersion"`
+	StartedAt     string `json:"startedAt"`
+	EndedAt       string `json:"endedAt"`
+	BytesToServer int64  `json:"bytesToServer"`
+	BytesToClient int64  `json:"bytesToClient"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Bridge はローカルのWebSocketサーバー。最初のOpen呼び出し時に127.0.0.1の空きポートで待ち受けを開始する。
+type Bridge struct {
+	recordPath string
+	dial       func(network, address string) (net.Conn, error)
+
+	mu       sync.Mutex
+	listener net.Listener
+	server   *http.Server
+	pending  map[string]pendingSession
+	history  []SessionRecord
+	conns    map[string]func()
+}
+
+type pendingSession struct {
+	target  Target
+	expires time.Time
+}
+
+// New はBridgeを作成する。recordPathが空でなければ、セッションの記録をJSON Lines形式で追記する。
+func New(recordPath string) *Bridge {
+	return &Bridge{
+		recordPath: recordPath,
+		dial: func(network, address string) (net.Conn, error) {
+			return net.DialTimeout(network, address, dialTimeout)
+		},
+		pending: make(map[string]pendingSession),
+		conns:   make(map[string]func()),
+	}
+}
+
+// Open は中継先を登録し、クライアントが接続するWebSocketのURLとセッションIDを返す。
+// URLは一度だけ、openTimeout以内に使用できる。
+func (b *Bridge) Open(target Target) (url string, sessionID string, err error) {
+	if target.Host == "" || target.Port == "" {
+		return "", "", fmt.Errorf("VNCの接続先が不明です")
+	}
+	addr, err := b.start()
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for t, p := range b.pending {
+		if now.After(p.expires) {
+			delete(b.pending, t)
+		}
+	}
+	b.pending[token] = pendingSession{target: target, expires: now.Add(openTimeout)}
+	return fmt.Sprintf("ws://%s/vnc/%s", addr, token), token, nil
+}
+
+// Disconnect は接続中のセッションを切断する。
+func (b *Bridge) Disconnect(sessionID string) {
+	b.mu.Lock()
+	closeFn, ok := b.conns[sessionID]
+	delete(b.pending, sessionID)
+	b.mu.Unlock()
+	if ok {
+		closeFn()
+	}
+}
+
+// Sessions はこのプロセスで終了したセッションの記録を古い順に返す。
+func (b *Bridge) Sessions() []SessionRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]SessionRecord(nil), b.history...)
+}
+
+// Close は待ち受けを終了し、接続中のセッションを全て切断する。
+func (b *Bridge) Close() error {
+	b.mu.Lock()
+	server := b.server
+	closers := make([]func(), 0, len(b.conns))
+	for _, c := range b.conns {
+		closers = append(closers, c)
+	}
+	b.server = nil
+	b.listener = nil
+	b.mu.Unlock()
+
+	for _, c := range closers {
+		c()
+	}
+	if server == nil {
+		return nil
+	}
+	return server.Close()
+}
+
+// start は待ち受けを開始し、待ち受けているアドレスを返す。
+// 戻った後にCloseされてもアドレスを参照できるよう、ロックを保持している間に取得する。
+func (b *Bridge) start() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.listener != nil {
+		return b.listener.Addr().String(), nil
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("VNCブリッジの待ち受けに失敗しました: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vnc/", b.handle)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	b.listener = ln
+	b.server = server
+	go func() { _ = server.Serve(ln) }()
+	return ln.Addr().String(), nil
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  bufferSize,
+	WriteBufferSize: bufferSize,
+	Subprotocols:    []string{"binary"},
+	// WebViewのOriginはwails://等になるため、Originではなく使い捨てのトークンで接続元を制限する
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+func (b *Bridge) handle(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/vnc/")
+
+	b.mu.Lock()
+	p, ok := b.pending[token]
+	delete(b.pending, token)
+	b.mu.Unlock()
+	if !ok || time.Now().After(p.expires) {
+		http.Error(w, "unknown or expired session", http.StatusNotFound)
+		return
+	}
+
+	address := net.JoinHostPort(p.target.Host, p.target.Port)
+	tcp, err := b.dial("tcp", address)
+	if err != nil {
+		http.Error(w, "failed to connect to VNC server", http.StatusBadGateway)
+		b.record(SessionRecord{
+			ID:        token,
+			Zone:      p.target.Zone,
+			ServerID:  p.target.ServerID,
+			Target:    address,
+			StartedAt: time.Now().Format(time.RFC3339),
+			EndedAt:   time.Now().Format(time.RFC3339),
+			Error:     err.Error(),
+		})
+		return
+	}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		_ = tcp.Close()
+		return
+	}
+
+	s := &session{
+		record: SessionRecord{
+			ID:        token,
+			Zone:      p.target.Zone,
+			ServerID:  p.target.ServerID,
+			Target:    address,
+			StartedAt: time.Now().Format(time.RFC3339),
+		},
+		ws:  ws,
+		tcp: tcp,
+	}
+	b.mu.Lock()
+	b.conns[token] = s.close
+	b.mu.Unlock()
+
+	s.run()
+
+	b.mu.Lock()
+	delete(b.conns, token)
+	b.mu.Unlock()
+	b.record(s.finish())
+}
+
+func (b *Bridge) record(rec SessionRecord) {
+	b.mu.Lock()
+	b.history = append(b.history, rec)
+	b.mu.Unlock()
+	if b.recordPath == "" {
+		return
+	}
+	// 記録の失敗でコンソール操作を妨げないよう、エラーは無視する
+	_ = appendRecord(b.recordPath, rec)
+}
+
+func appendRecord(path string, rec SessionRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(rec)
+}
+
+// session はWebSocketとVNCサーバーのTCP接続の組。どちらかが切断されたら両方を閉じる。
+type session struct {
+	record        SessionRecord
+	ws            *websocket.Conn
+	tcp           net.Conn
+	bytesToServer atomic.Int64
+	bytesToClient atomic.Int64
+	closeOnce     sync.Once
+	errMu         sync.Mutex
+	err           error
+}
+
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		_ = s.ws.Close()
+		_ = s.tcp.Close()
+	})
+}
+
+func (s *session) setErr(err error) {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+	if s.err == nil && !isClosedError(err) {
+		s.err = err
+	}
+}
+
+func (s *session) run() {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer s.close()
+		s.setErr(s.clientToServer())
+	}()
+	go func() {
+		defer wg.Done()
+		defer s.close()
+		s.setErr(s.serverToClient())
+	}()
+	wg.Wait()
+}
+
+func (s *session) clientToServer() error {
+	for {
+		messageType, data, err := s.ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if messageType != websocket.BinaryMessage && messageType != websocket.TextMessage {
+			continue
+		}
+		n, err := s.tcp.Write(data)
+		s.bytesToServer.Add(int64(n))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *session) serverToClient() error {
+	buf := make([]byte, bufferSize)
+	var version []byte
+	for {
+		n, err := s.tcp.Read(buf)
+		if n > 0 {
+			if len(version) < rfbVersionLen {
+				version = append(version, buf[:min(n, rfbVersionLen-len(version))]...)
+				if len(version) == rfbVersionLen {
+					s.record.ServerVersion = strings.TrimSpace(string(version))
+				}
+			}
+			if werr := s.ws.WriteMessage(websocket.BinaryMessage, buf[:n]); werr != nil {
+				return werr
+			}
+			s.bytesToClient.Add(int64(n))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *session) finish() SessionRecord {
+	rec := s.record
+	rec.EndedAt = time.Now().Format(time.RFC3339)
+	rec.BytesToServer = s.bytesToServer.Load()
+	rec.BytesToClient = s.bytesToClient.Load()
+	s.errMu.Lock()
+	if s.err != nil {
+		rec.Error = s.err.Error()
+	}
+	s.errMu.Unlock()
+	return rec
+}
+
+// isClosedError は正常な切断(どちらかが閉じた)によるエラーかを判定する。
+func isClosedError(err error) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+		return true
+	}
+	return false
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
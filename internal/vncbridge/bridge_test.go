@@ -0,0 +1,212 @@
+package vncbridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startFakeRFBServer はProtocolVersionを送った後、受信したデータをそのまま返すだけのRFBサーバーもどき。
+func startFakeRFBServer(t *testing.T) (host, port string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				if _, err := c.Write([]byte("RFB 003.008\n")); err != nil {
+					return
+				}
+				r := bufio.NewReader(c)
+				buf := make([]byte, 1024)
+				for {
+					n, err := r.Read(buf)
+					if err != nil {
+						return
+					}
+					if _, err := c.Write(buf[:n]); err != nil {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+	return host, port
+}
+
+func readBinary(t *testing.T, ws *websocket.Conn, want int) []byte {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var got []byte
+	for len(got) < want {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		if messageType != websocket.BinaryMessage {
+			t.Fatalf("messageType = %d, want binary", messageType)
+		}
+		got = append(got, data...)
+	}
+	return got
+}
+
+func waitSessions(t *testing.T, b *Bridge, n int) []SessionRecord {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if sessions := b.Sessions(); len(sessions) >= n {
+			return sessions
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("session was not recorded")
+	return nil
+}
+
+func TestBridge_RelaysAndRecords(t *testing.T) {
+	host, port := startFakeRFBServer(t)
+	recordPath := filepath.Join(t.TempDir(), "vnc-sessions.jsonl")
+	b := New(recordPath)
+	t.Cleanup(func() { _ = b.Close() })
+
+	url, sessionID, err := b.Open(Target{Host: host, Port: port, Zone: "is1a", ServerID: "100"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	ws, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Protocol"); got != "" && got != "binary" {
+		t.Errorf("subprotocol = %q", got)
+	}
+
+	if got := string(readBinary(t, ws, 12)); got != "RFB 003.008\n" {
+		t.Errorf("version = %q, want RFB 003.008", got)
+	}
+	if err := ws.WriteMessage(websocket.BinaryMessage, []byte("RFB 003.008\n")); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	if got := string(readBinary(t, ws, 12)); got != "RFB 003.008\n" {
+		t.Errorf("echo = %q", got)
+	}
+	_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	_ = ws.Close()
+
+	rec := waitSessions(t, b, 1)[0]
+	if rec.ID != sessionID || rec.ServerID != "100" || rec.Zone != "is1a" {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.ServerVersion != "RFB 003.008" {
+		t.Errorf("ServerVersion = %q", rec.ServerVersion)
+	}
+	if rec.BytesToServer != 12 || rec.BytesToClient != 24 {
+		t.Errorf("bytes = %d/%d, want 12/24", rec.BytesToServer, rec.BytesToClient)
+	}
+	if rec.Error != "" {
+		t.Errorf("Error = %q, want empty on normal close", rec.Error)
+	}
+
+	data, err := os.ReadFile(recordPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var saved SessionRecord
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Unmarshal: %v (%s)", err, data)
+	}
+	if saved.ID != sessionID {
+		t.Errorf("saved ID = %q, want %q", saved.ID, sessionID)
+	}
+}
+
+func TestBridge_TokenIsSingleUse(t *testing.T) {
+	host, port := startFakeRFBServer(t)
+	b := New("")
+	t.Cleanup(func() { _ = b.Close() })
+
+	url, _, err := b.Open(Target{Host: host, Port: port})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("first Dial: %v", err)
+	}
+	defer ws.Close()
+
+	if _, _, err := websocket.DefaultDialer.Dial(url, nil); err == nil {
+		t.Error("second Dial with the same token should fail")
+	}
+	if _, _, err := websocket.DefaultDialer.Dial(url+"x", nil); err == nil {
+		t.Error("Dial with an unknown token should fail")
+	}
+}
+
+func TestBridge_Disconnect(t *testing.T) {
+	host, port := startFakeRFBServer(t)
+	b := New("")
+	t.Cleanup(func() { _ = b.Close() })
+
+	url, sessionID, err := b.Open(Target{Host: host, Port: port})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer ws.Close()
+	readBinary(t, ws, 12)
+
+	b.Disconnect(sessionID)
+	waitSessions(t, b, 1)
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := ws.ReadMessage(); err == nil {
+		t.Error("ReadMessage after Disconnect should fail")
+	}
+}
+
+func TestBridge_OpenRequiresTarget(t *testing.T) {
+	b := New("")
+	if _, _, err := b.Open(Target{}); err == nil {
+		t.Error("Open with empty target should fail")
+	}
+}
+
+func TestBridge_OpenConcurrentWithClose(t *testing.T) {
+	b := New("")
+	defer b.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = b.Close()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if _, _, err := b.Open(Target{Host: "127.0.0.1", Port: "5900"}); err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+	}
+	<-done
+}